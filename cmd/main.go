@@ -2,32 +2,122 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
+	"math"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/amylixing/news-fetcher/internal/ai"
+	"github.com/amylixing/news-fetcher/internal/archive"
+	"github.com/amylixing/news-fetcher/internal/botcmd"
 	"github.com/amylixing/news-fetcher/internal/cache"
 	"github.com/amylixing/news-fetcher/internal/config"
+	"github.com/amylixing/news-fetcher/internal/deadletter"
+	"github.com/amylixing/news-fetcher/internal/dedupstats"
+	"github.com/amylixing/news-fetcher/internal/digest"
+	"github.com/amylixing/news-fetcher/internal/dupewindow"
+	"github.com/amylixing/news-fetcher/internal/escalation"
+	"github.com/amylixing/news-fetcher/internal/eventhook"
+	"github.com/amylixing/news-fetcher/internal/eventlog"
 	"github.com/amylixing/news-fetcher/internal/fetcher"
+	"github.com/amylixing/news-fetcher/internal/journal"
 	"github.com/amylixing/news-fetcher/internal/models"
+	"github.com/amylixing/news-fetcher/internal/mute"
+	"github.com/amylixing/news-fetcher/internal/ocr"
+	"github.com/amylixing/news-fetcher/internal/pendinganalysis"
+	"github.com/amylixing/news-fetcher/internal/pidlock"
+	"github.com/amylixing/news-fetcher/internal/priorityqueue"
+	"github.com/amylixing/news-fetcher/internal/readstate"
+	"github.com/amylixing/news-fetcher/internal/sampling"
 	"github.com/amylixing/news-fetcher/internal/sender"
+	"github.com/amylixing/news-fetcher/internal/sentiment"
+	"github.com/amylixing/news-fetcher/internal/subscription"
+	"github.com/amylixing/news-fetcher/internal/userwatch"
+	"github.com/amylixing/news-fetcher/internal/watchlist"
+	"github.com/amylixing/news-fetcher/internal/webhook"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/kardianos/service"
 )
 
+// maxRecentSent 是 /latest 命令可查询的最近发送条目上限
+const maxRecentSent = 20
+
+// readAckCallbackPrefix 是已读确认内联按钮的 callback_data 前缀，
+// 后面拼接 readstate.Store 分配的短序号
+const readAckCallbackPrefix = "readstate:ack:"
+
+// appVersion 是当前二进制的版本号，发布构建时通过
+// `go build -ldflags "-X main.appVersion=x.y.z"` 注入，本地开发构建保持 "dev"；
+// `news-fetcher update` 用它判断是否已是 GitHub 最新 release
+var appVersion = "dev"
+
 type App struct {
-	cfg      *config.Config
-	fetcher  *fetcher.Fetcher
-	analyzer *ai.Analyzer
-	sender   *sender.Sender
-	cache    *cache.Cache
+	name             string // profile 名称，单租户模式下为空
+	cfg              *config.Config
+	fetcher          *fetcher.Fetcher
+	analyzer         *ai.Analyzer
+	sender           *sender.Sender
+	cache            *cache.Cache
+	webhook          *webhook.Webhook
+	sentiment        *sentiment.Index
+	watchlist        *watchlist.Matcher
+	subStore         *subscription.Store    // 为空表示未开启 /subscribe 自助订阅
+	userWatch        *userwatch.Store       // 为空表示未开启 /watch 个人关键词订阅
+	muter            *mute.Muter            // 为空表示未开启 /mute 数据源临时静音
+	sampler          *sampling.Sampler      // 大频道低重要性条目抽样投递，未开启时 Allow 总是返回 true
+	ocr              *ocr.Extractor         // 为空表示未开启纯图片公告的 OCR 补全
+	archive          *archive.Store         // 为空表示未开启内容更新时的编辑推送或投递回执记录
+	deliveryReceipts bool                   // 是否将每条新闻在各聊天的投递回执写入 archive，供 /receipts 命令查询
+	pendingAI        *pendinganalysis.Store // 为空表示不跨抓取周期重试失败的 AI 分析
+	dupeWindow       *dupewindow.Store      // 为空表示未开启重复稿件抑制窗口
+	deadLetter       *deadletter.Store      // 为空表示未开启死信队列，投递失败的条目仅等待下一轮抓取周期自然重试
+	readState        *readstate.Store       // 为空表示未开启私信已读状态跟踪，个人订阅私信不带已读确认按钮
+	digestRouter     *digest.Router         // 为空表示未开启按类目拆分的摘要推送
+	digestSchedules  []*digest.Schedule     // 与 digestRouter 内部持有的是同一批实例，供各自的定时任务取用
+	escalator        *escalation.Escalator  // 为空表示未开启严重条目升级通知链
+	dedupStats       *dedupstats.Recorder   // 按天统计各去重环节淘汰的条目数，供 /dedupstats 调优报告使用
+	journal          *journal.Store         // 为空表示未开启崩溃安全的处理进度记录
+	deliveryQueue    *priorityqueue.Queue   // 为空表示未开启 AI 分析与发送阶段之间的优先级队列，逐条按到达顺序直接发送
+	dryRun           bool                   // 为 true 时只打印处理结果，不实际发送到 Telegram/Webhook
+
+	briefingMu    sync.Mutex
+	briefingItems []*models.News // 早间简报累积的条目，每次推送后清空
+
+	recentMu   sync.Mutex
+	recentSent []*models.News // 最近成功发送的条目，供 /latest 命令查询，最多保留 maxRecentSent 条
+
+	cycleSkipped    int64 // 因单轮处理耗时超过抓取间隔而被跳过/丢弃的轮次数，atomic 读写
+	cycleOverlapped int64 // 因单轮处理耗时超过抓取间隔而紧跟/并发开始的轮次数，atomic 读写
+
+	cycleSummaryMu     sync.Mutex
+	avgFetchedPerCycle float64 // 每轮抓取量的指数移动平均，用于 cycle_summary 判断本轮是否异常偏高
+
+	sourceBaselineMu sync.Mutex
+	sourceBaseline   map[string]float64 // 每个数据源单轮抓取量的指数移动平均，用于 anomaly_detection 判断该源本轮是否异常偏高
 }
 
-func NewApp(cfg *config.Config) (*App, error) {
+// NewApp 创建一个应用实例，name 为 profile 名称，单租户模式下传空字符串；
+// name 用于隔离各 profile 的去重缓存文件，保证多租户之间的去重状态互不影响
+// NewApp 构建一个应用实例。sharedCache 非空时复用调用方传入的去重缓存实例
+// （用于多个 profile 共享同一份去重命名空间），否则按 name 拆分出独立的缓存文件
+func NewApp(cfg *config.Config, name string, sharedCache *cache.Cache) (*App, error) {
+	// 配置调试期原始负载输出，默认关闭，避免正文和令牌泄露到日志
+	if cfg.App != nil {
+		eventlog.SetDebugDump(cfg.App.DebugDump, cfg.App.DumpMaxSize)
+		eventlog.SetLogLevel(cfg.App.LogLevel)
+	}
+	eventhook.Configure(cfg.EventHook)
+
 	// 创建数据目录
 	dataDir := filepath.Join(os.Getenv("HOME"), ".news-fetcher")
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
@@ -35,26 +125,93 @@ func NewApp(cfg *config.Config) (*App, error) {
 	}
 	log.Printf("数据目录已创建: %s", dataDir)
 
-	// 初始化缓存
-	cacheFile := filepath.Join(dataDir, "news_cache.json")
-	log.Printf("初始化缓存，文件路径: %s", cacheFile)
-	newsCache, err := cache.NewCache(cacheFile)
-	if err != nil {
-		return nil, fmt.Errorf("初始化缓存失败: %v", err)
+	// 初始化缓存：传入了 sharedCache 时直接复用（多个 profile 共享同一份去重命名空间），
+	// 否则多租户模式下按 profile 名称拆分缓存文件，保证去重状态互相隔离
+	newsCache := sharedCache
+	if newsCache == nil {
+		cacheFileName := "news_cache.json"
+		if name != "" {
+			cacheFileName = fmt.Sprintf("news_cache_%s.json", name)
+		}
+		cacheFile := filepath.Join(dataDir, cacheFileName)
+		log.Printf("初始化缓存，文件路径: %s", cacheFile)
+		var err error
+		newsCache, err = cache.NewCache(cacheFile)
+		if err != nil {
+			return nil, fmt.Errorf("初始化缓存失败: %v", err)
+		}
+		log.Printf("缓存初始化成功")
+	} else {
+		log.Printf("使用共享去重缓存，命名空间: %s", name)
 	}
-	log.Printf("缓存初始化成功")
 
 	// 初始化抓取器
 	log.Printf("初始化抓取器...")
-	fetcher, err := fetcher.NewFetcher(cfg.Sources)
+	fetcher, err := fetcher.NewFetcher(cfg.Sources, cfg.HTTPClient)
 	if err != nil {
 		return nil, fmt.Errorf("初始化抓取器失败: %v", err)
 	}
 	log.Printf("抓取器初始化成功")
 
+	// 开启逐数据源的抓取游标持久化：即使去重缓存被清空，也不会把已处理过的旧条目
+	// 重新当作新闻，多租户模式下按 profile 名称拆分文件
+	cursorFileName := "cursors.json"
+	if name != "" {
+		cursorFileName = fmt.Sprintf("cursors_%s.json", name)
+	}
+	if err := fetcher.EnableCursor(filepath.Join(dataDir, cursorFileName)); err != nil {
+		return nil, fmt.Errorf("初始化抓取游标失败: %v", err)
+	}
+
+	// 开启逐数据源的状态持久化（ETag、分页游标等），供实现了 StatefulSource 的
+	// 数据源保存自己的抓取状态，多租户模式下按 profile 名称拆分文件
+	sourceStateFileName := "source_state.json"
+	if name != "" {
+		sourceStateFileName = fmt.Sprintf("source_state_%s.json", name)
+	}
+	if err := fetcher.EnableSourceState(filepath.Join(dataDir, sourceStateFileName)); err != nil {
+		return nil, fmt.Errorf("初始化数据源状态存储失败: %v", err)
+	}
+
+	// 开启逐数据源的持久化 Cookie Jar，供配置了 cookie_jar: true 的数据源保存登录
+	// 会话，多租户模式下按 profile 名称拆分文件
+	cookieJarFileName := "cookies.json"
+	if name != "" {
+		cookieJarFileName = fmt.Sprintf("cookies_%s.json", name)
+	}
+	if err := fetcher.EnableCookieJar(filepath.Join(dataDir, cookieJarFileName)); err != nil {
+		return nil, fmt.Errorf("初始化 Cookie 存储失败: %v", err)
+	}
+
+	// 开启了 websub 的 RSS 源尝试通过 WebSub 接收推送更新，未声明 hub 或订阅失败时
+	// 该数据源自动回退为轮询，不影响应用启动
+	if err := fetcher.EnableWebSub(cfg.Sources.WebSub); err != nil {
+		return nil, fmt.Errorf("初始化 WebSub 推送订阅失败: %v", err)
+	}
+
+	// 启用了自助订阅时，加载上次持久化的订阅列表并合并进运行期数据源，
+	// 多租户模式下按 profile 名称拆分文件，与缓存文件的隔离方式保持一致
+	var subStore *subscription.Store
+	if cfg.Subscription != nil && cfg.Subscription.Enabled {
+		subFileName := "subscriptions.json"
+		if name != "" {
+			subFileName = fmt.Sprintf("subscriptions_%s.json", name)
+		}
+		subStore = subscription.NewStore(filepath.Join(dataDir, subFileName))
+		urls, err := subStore.Load()
+		if err != nil {
+			log.Printf("加载已持久化的订阅列表失败: %v", err)
+		}
+		for _, u := range urls {
+			if err := fetcher.AddRSSSource(context.Background(), &config.SourceConfig{URL: u}); err != nil {
+				log.Printf("恢复订阅源失败，跳过: %s: %v", u, err)
+			}
+		}
+	}
+
 	// 初始化AI分析器
 	log.Printf("初始化AI分析器...")
-	analyzer, err := ai.NewAnalyzer(cfg.AI)
+	analyzer, err := ai.NewAnalyzer(cfg.AI, cfg.HTTPClient)
 	if err != nil {
 		log.Printf("AI分析器初始化失败: %v", err)
 		cfg.AI.Enabled = false
@@ -64,18 +221,201 @@ func NewApp(cfg *config.Config) (*App, error) {
 
 	// 初始化发送器
 	log.Printf("初始化发送器...")
-	sender, err := sender.NewSender(cfg.Telegram, newsCache)
+	sender, err := sender.NewSender(cfg.Telegram, newsCache, cfg.HTTPClient)
 	if err != nil {
 		return nil, fmt.Errorf("初始化发送器失败: %v", err)
 	}
 	log.Printf("发送器初始化成功")
 
+	// 启用聊天ID迁移记录持久化：群组升级为超级群组后自动更新并记住新聊天ID，
+	// 多租户模式下按 profile 名称拆分文件，与缓存文件的隔离方式保持一致
+	chatMigrationFileName := "chat_migrations.json"
+	if name != "" {
+		chatMigrationFileName = fmt.Sprintf("chat_migrations_%s.json", name)
+	}
+	if err := sender.EnableChatMigration(filepath.Join(dataDir, chatMigrationFileName)); err != nil {
+		return nil, err
+	}
+
+	// 按天统计各去重环节淘汰的条目数，供 /dedupstats 调优报告使用
+	dedupStats := dedupstats.New(0)
+	sender.SetDedupStats(dedupStats)
+
+	// 初始化 Webhook 发送器
+	log.Printf("初始化 Webhook 发送器...")
+	webhookSender, err := webhook.NewWebhook(cfg.Webhook)
+	if err != nil {
+		return nil, fmt.Errorf("初始化 Webhook 发送器失败: %v", err)
+	}
+	log.Printf("Webhook 发送器初始化成功")
+
+	var watchlistMatcher *watchlist.Matcher
+	if cfg.Watchlist != nil && cfg.Watchlist.Enabled {
+		watchlistMatcher = watchlist.NewMatcher(cfg.Watchlist.Entities)
+	}
+
+	// 启用了个人关键词订阅时，加载已持久化的用户订阅，文件按 profile 名称拆分
+	var userWatchStore *userwatch.Store
+	if cfg.UserWatch != nil && cfg.UserWatch.Enabled {
+		userWatchFileName := "user_watch.json"
+		if name != "" {
+			userWatchFileName = fmt.Sprintf("user_watch_%s.json", name)
+		}
+		userWatchStore, err = userwatch.NewStore(filepath.Join(dataDir, userWatchFileName), cfg.UserWatch.MaxPerHour)
+		if err != nil {
+			return nil, fmt.Errorf("初始化用户关键词订阅失败: %v", err)
+		}
+	}
+
+	var muter *mute.Muter
+	if cfg.Mute != nil && cfg.Mute.Enabled {
+		muter = mute.NewMuter()
+	}
+
+	var ocrExtractor *ocr.Extractor
+	if cfg.OCR != nil && cfg.OCR.Enabled {
+		ocrExtractor = ocr.NewExtractor(cfg.OCR.Command, time.Duration(cfg.OCR.Timeout)*time.Second)
+	}
+
+	// 启用了内容更新编辑推送或投递回执记录时，加载已持久化的发送记录，
+	// 文件按 profile 名称拆分，与缓存文件的隔离方式保持一致
+	var archiveStore *archive.Store
+	deliveryReceiptsEnabled := cfg.DeliveryReceipts != nil && cfg.DeliveryReceipts.Enabled
+	if (cfg.EditOnUpdate != nil && cfg.EditOnUpdate.Enabled) || deliveryReceiptsEnabled {
+		archiveFileName := "archive.json"
+		if name != "" {
+			archiveFileName = fmt.Sprintf("archive_%s.json", name)
+		}
+		archiveStore, err = archive.NewStore(filepath.Join(dataDir, archiveFileName))
+		if err != nil {
+			return nil, fmt.Errorf("初始化发送记录失败: %v", err)
+		}
+	}
+
+	// 启用了 AI 分析且配置了跨周期重试时长时，加载已持久化的待重试分析记录，
+	// 文件按 profile 名称拆分，与缓存文件的隔离方式保持一致
+	var pendingAI *pendinganalysis.Store
+	if cfg.AI != nil && cfg.AI.Enabled && cfg.AI.RetryMaxAge > 0 {
+		pendingAIFileName := "pending_analysis.json"
+		if name != "" {
+			pendingAIFileName = fmt.Sprintf("pending_analysis_%s.json", name)
+		}
+		pendingAI, err = pendinganalysis.NewStore(filepath.Join(dataDir, pendingAIFileName))
+		if err != nil {
+			return nil, fmt.Errorf("初始化待重试分析存储失败: %v", err)
+		}
+	}
+
+	// 启用了重复稿件抑制窗口时，加载已持久化的窗口记录，
+	// 文件按 profile 名称拆分，与缓存文件的隔离方式保持一致
+	var dupeWindow *dupewindow.Store
+	if cfg.DuplicateWindow != nil && cfg.DuplicateWindow.Enabled {
+		dupeWindowFileName := "duplicate_window.json"
+		if name != "" {
+			dupeWindowFileName = fmt.Sprintf("duplicate_window_%s.json", name)
+		}
+		dupeWindow, err = dupewindow.NewStore(filepath.Join(dataDir, dupeWindowFileName))
+		if err != nil {
+			return nil, fmt.Errorf("初始化重复抑制窗口存储失败: %v", err)
+		}
+	}
+
+	// 启用了死信队列时，加载已持久化的死信记录，
+	// 文件按 profile 名称拆分，与缓存文件的隔离方式保持一致
+	var deadLetterStore *deadletter.Store
+	if cfg.DeadLetter != nil && cfg.DeadLetter.Enabled {
+		deadLetterFileName := "dead_letter.json"
+		if name != "" {
+			deadLetterFileName = fmt.Sprintf("dead_letter_%s.json", name)
+		}
+		deadLetterStore, err = deadletter.NewStore(filepath.Join(dataDir, deadLetterFileName))
+		if err != nil {
+			return nil, fmt.Errorf("初始化死信队列存储失败: %v", err)
+		}
+	}
+
+	// 启用了崩溃安全的处理进度记录时，加载已持久化的流水线进度，
+	// 文件按 profile 名称拆分，与缓存文件的隔离方式保持一致
+	var journalStore *journal.Store
+	if cfg.Journal != nil && cfg.Journal.Enabled {
+		journalFileName := "journal.json"
+		if name != "" {
+			journalFileName = fmt.Sprintf("journal_%s.json", name)
+		}
+		journalStore, err = journal.NewStore(filepath.Join(dataDir, journalFileName))
+		if err != nil {
+			return nil, fmt.Errorf("初始化处理进度存储失败: %v", err)
+		}
+	}
+
+	// 启用了 AI 分析与发送阶段之间的优先级队列时创建队列实例；纯内存状态，
+	// 进程重启后队列清空，未发出的条目下一轮抓取仍会被当作新条目重新入队，
+	// 不需要像上面几个 Store 一样持久化
+	var deliveryQueue *priorityqueue.Queue
+	if cfg.App != nil && cfg.App.DeliveryQueue != nil && cfg.App.DeliveryQueue.Enabled {
+		deliveryQueue = priorityqueue.New(cfg.App.DeliveryQueue.MaxPerCycle, cfg.App.DeliveryQueue.AgingBoost)
+	}
+
+	// 启用了私信已读状态时，加载已持久化的未读记录，
+	// 文件按 profile 名称拆分，与缓存文件的隔离方式保持一致
+	var readStateStore *readstate.Store
+	if cfg.ReadState != nil && cfg.ReadState.Enabled {
+		readStateFileName := "read_state.json"
+		if name != "" {
+			readStateFileName = fmt.Sprintf("read_state_%s.json", name)
+		}
+		readStateStore, err = readstate.NewStore(filepath.Join(dataDir, readStateFileName))
+		if err != nil {
+			return nil, fmt.Errorf("初始化已读状态存储失败: %v", err)
+		}
+	}
+
+	// 启用了按类目拆分的摘要推送时，为每个类目计划各自创建一个独立缓冲区；
+	// digestSchedules 保留同一批实例的引用，供 Run() 里各计划自己的定时任务直接取用
+	var digestRouter *digest.Router
+	var digestSchedules []*digest.Schedule
+	if cfg.Digest != nil && cfg.Digest.Enabled {
+		for _, scheduleCfg := range cfg.Digest.Schedules {
+			digestSchedules = append(digestSchedules, digest.NewSchedule(scheduleCfg))
+		}
+		digestRouter = digest.NewRouter(digestSchedules)
+	}
+
+	// 开启了严重条目升级通知链时，主推送渠道投递失败且重要性达到阈值的条目
+	// 会依次尝试链上配置的通道，telegram 类型的通道直接复用 sender 的纯文本发送能力
+	var escalator *escalation.Escalator
+	if cfg.Escalation != nil && cfg.Escalation.Enabled {
+		escalator = escalation.New(cfg.Escalation, sender)
+	}
+
 	app := &App{
-		cfg:      cfg,
-		fetcher:  fetcher,
-		analyzer: analyzer,
-		sender:   sender,
-		cache:    newsCache,
+		name:             name,
+		cfg:              cfg,
+		fetcher:          fetcher,
+		analyzer:         analyzer,
+		sender:           sender,
+		cache:            newsCache,
+		webhook:          webhookSender,
+		sentiment:        sentiment.NewIndex(),
+		watchlist:        watchlistMatcher,
+		subStore:         subStore,
+		userWatch:        userWatchStore,
+		muter:            muter,
+		sampler:          sampling.New(cfg.Telegram.Sampling),
+		ocr:              ocrExtractor,
+		archive:          archiveStore,
+		deliveryReceipts: deliveryReceiptsEnabled,
+		pendingAI:        pendingAI,
+		dupeWindow:       dupeWindow,
+		deadLetter:       deadLetterStore,
+		readState:        readStateStore,
+		digestRouter:     digestRouter,
+		digestSchedules:  digestSchedules,
+		escalator:        escalator,
+		dedupStats:       dedupStats,
+		journal:          journalStore,
+		deliveryQueue:    deliveryQueue,
+		sourceBaseline:   make(map[string]float64),
 	}
 
 	log.Printf("应用初始化完成")
@@ -89,128 +429,1953 @@ func (app *App) Run(ctx context.Context) error {
 
 	// 创建等待组
 	var wg sync.WaitGroup
-	
-	// 启动定时任务
-	ticker := time.NewTicker(time.Duration(app.cfg.App.FetchInterval) * time.Second)
+
+	// 启动定时任务，定时器周期取全局间隔和高优先级数据源自身间隔中的最短值，
+	// 这样 priority: high 的数据源（如交易所公告）才能按自己的 poll_interval 被及时轮询到
+	tickInterval := app.fetcher.FastestInterval(app.cfg.App.FetchInterval)
+	ticker := time.NewTicker(time.Duration(tickInterval) * time.Second)
 	defer ticker.Stop()
 
+	policy := "queue-one"
+	maxConcurrent := 2
+	if app.cfg.App.Concurrency != nil {
+		if app.cfg.App.Concurrency.Policy != "" {
+			policy = app.cfg.App.Concurrency.Policy
+		}
+		if app.cfg.App.Concurrency.MaxConcurrent > 0 {
+			maxConcurrent = app.cfg.App.Concurrency.MaxConcurrent
+		}
+	}
+
+	// run-concurrent-with-limit 策略下用于限制同时运行的轮次数的信号量
+	var cycleSem chan struct{}
+	if policy == "run-concurrent-with-limit" {
+		cycleSem = make(chan struct{}, maxConcurrent)
+	}
+
 	// 启动主循环
-		wg.Add(1)
+	wg.Add(1)
 	go func() {
-			defer wg.Done()
+		defer wg.Done()
 		for {
 			select {
 			case <-ctx.Done():
 				log.Println("收到停止信号，正在退出...")
 				return
 			case <-ticker.C:
-				if err := app.processNews(ctx); err != nil {
-					log.Printf("处理新闻失败: %v", err)
+				switch policy {
+				case "skip":
+					if err := app.processNews(ctx); err != nil {
+						log.Printf("处理新闻失败: %v", err)
+					}
+					// 处理期间可能已经又攒了一个 tick，丢弃它以保证严格按间隔
+					// 运行，而不是像默认策略那样紧跟着立刻开始下一轮
+					select {
+					case <-ticker.C:
+						atomic.AddInt64(&app.cycleSkipped, 1)
+						eventlog.Event("cycle", "schedule", "", "skipped", 0)
+						log.Println("上一轮处理耗时超过抓取间隔，丢弃期间累积的调度")
+					default:
+					}
+				case "run-concurrent-with-limit":
+					select {
+					case cycleSem <- struct{}{}:
+						atomic.AddInt64(&app.cycleOverlapped, 1)
+						wg.Add(1)
+						go func() {
+							defer wg.Done()
+							defer func() { <-cycleSem }()
+							if err := app.processNews(ctx); err != nil {
+								log.Printf("处理新闻失败: %v", err)
+							}
+						}()
+					default:
+						atomic.AddInt64(&app.cycleSkipped, 1)
+						eventlog.Event("cycle", "schedule", "", "skipped", 0)
+						log.Println("并发运行的轮次数已达上限，跳过本次调度")
+					}
+				default: // "queue-one"：维持 time.Ticker 原有行为，耗时超过间隔时下一轮紧跟开始
+					start := time.Now()
+					if err := app.processNews(ctx); err != nil {
+						log.Printf("处理新闻失败: %v", err)
+					}
+					if elapsed := time.Since(start); elapsed > time.Duration(tickInterval)*time.Second {
+						atomic.AddInt64(&app.cycleOverlapped, 1)
+						eventlog.Event("cycle", "schedule", "", "overlapping", elapsed)
+						log.Printf("本轮处理耗时 %v，超过抓取间隔 %ds，下一轮已紧跟开始", elapsed, tickInterval)
+					}
 				}
 			}
-			}
+		}
 	}()
 
+	// 启动情绪指数定时汇总推送
+	if app.cfg.Sentiment != nil && app.cfg.Sentiment.Enabled {
+		interval := app.cfg.Sentiment.Interval
+		if interval <= 0 {
+			interval = 3600
+		}
+		sentimentTicker := time.NewTicker(time.Duration(interval) * time.Second)
+		defer sentimentTicker.Stop()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-sentimentTicker.C:
+					app.postSentimentIndex(ctx)
+				}
+			}
+		}()
+	}
+
+	// 启动早间简报定时任务：每分钟检查一次本地时间是否到达配置的时刻
+	if app.cfg.Briefing != nil && app.cfg.Briefing.Enabled {
+		loc := time.Local
+		if app.cfg.Briefing.Timezone != "" {
+			l, err := time.LoadLocation(app.cfg.Briefing.Timezone)
+			if err != nil {
+				log.Printf("加载简报时区失败，使用系统本地时区: %v", err)
+			} else {
+				loc = l
+			}
+		}
+
+		briefingTicker := time.NewTicker(time.Minute)
+		defer briefingTicker.Stop()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lastFired := ""
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case now := <-briefingTicker.C:
+					local := now.In(loc)
+					today := local.Format("2006-01-02")
+					if local.Hour() == app.cfg.Briefing.Hour && local.Minute() == app.cfg.Briefing.Minute && lastFired != today {
+						lastFired = today
+						app.postMorningBriefing(ctx)
+					}
+				}
+			}
+		}()
+	}
+
+	// 启动按类目拆分的摘要推送定时任务：每个配置了非零汇总周期的计划各自开一个
+	// 独立的 ticker，互不影响彼此的推送节奏；IntervalSeconds 为 0 的计划命中后
+	// 立即推送，不在这里定时，由 processNews 内联触发
+	for _, schedule := range app.digestSchedules {
+		if schedule.Config.IntervalSeconds <= 0 {
+			continue
+		}
+
+		ticker := time.NewTicker(time.Duration(schedule.Config.IntervalSeconds) * time.Second)
+		defer ticker.Stop()
+
+		wg.Add(1)
+		go func(schedule *digest.Schedule) {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					app.postDigest(ctx, schedule)
+				}
+			}
+		}(schedule)
+	}
+
+	// 启动交互式命令监听：/latest 等只读命令任何人可用，管理类命令受 admin_ids 白名单限制
+	if app.cfg.Telegram.Commands != nil && app.cfg.Telegram.Commands.Enabled && app.sender.Bot() != nil {
+		dispatcher := botcmd.NewDispatcher(app.sender.Bot(), app.cfg.Telegram.Commands.AdminIDs)
+		dispatcher.Register("latest", botcmd.LevelPublic, app.handleLatestCommand)
+		dispatcher.Register("status", botcmd.LevelPublic, app.handleStatusCommand)
+		dispatcher.Register("receipts", botcmd.LevelPublic, app.handleReceiptsCommand)
+		dispatcher.Register("deadletter", botcmd.LevelAdmin, app.handleDeadLetterCommand)
+		dispatcher.Register("budget", botcmd.LevelPublic, app.handleBudgetCommand)
+		dispatcher.Register("dedupstats", botcmd.LevelPublic, app.handleDedupStatsCommand)
+		dispatcher.Register("recap", botcmd.LevelPublic, app.handleRecapCommand)
+		dispatcher.Register("testformat", botcmd.LevelAdmin, app.handleTestFormatCommand)
+
+		if app.subStore != nil {
+			level := botcmd.LevelPublic
+			if app.cfg.Subscription.AdminOnly {
+				level = botcmd.LevelAdmin
+			}
+			dispatcher.Register("subscribe", level, app.handleSubscribeCommand)
+			dispatcher.Register("unsubscribe", level, app.handleUnsubscribeCommand)
+		}
+
+		if app.userWatch != nil {
+			dispatcher.Register("watch", botcmd.LevelPublic, app.handleWatchCommand)
+			dispatcher.Register("unwatch", botcmd.LevelPublic, app.handleUnwatchCommand)
+		}
+
+		if app.muter != nil {
+			dispatcher.Register("mute", botcmd.LevelAdmin, app.handleMuteCommand)
+			dispatcher.Register("unmute", botcmd.LevelAdmin, app.handleUnmuteCommand)
+			dispatcher.Register("muted", botcmd.LevelPublic, app.handleMutedCommand)
+		}
+
+		if app.readState != nil {
+			dispatcher.Register("unread", botcmd.LevelPublic, app.handleUnreadCommand)
+			dispatcher.RegisterCallback(readAckCallbackPrefix, app.handleReadAckCallback)
+		}
+
+		dispatcher.RegisterInlineQuery(app.handleInlineQuery)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dispatcher.Listen(ctx)
+		}()
+	}
+
+	// 开启后在启动完成时推送一条问候消息（版本号、已启用数据源数量），运维人员
+	// 从频道本身就能及时发现意外重启或崩溃循环，不必盯着服务器日志
+	if app.cfg.LifecycleNotify != nil && app.cfg.LifecycleNotify.Enabled {
+		text := fmt.Sprintf("news-fetcher 已启动\n版本: %s\n已启用数据源: %d 个", appVersion, app.fetcher.SourceCount())
+		if err := app.sender.SendText(ctx, app.lifecycleNotifyChatIDs(), text); err != nil {
+			log.Printf("推送启动通知失败: %v", err)
+		}
+	}
+
 	// 等待信号
 	<-sigChan
 	log.Println("收到终止信号，正在关闭服务...")
 	wg.Wait()
+
+	if app.cfg.LifecycleNotify != nil && app.cfg.LifecycleNotify.Enabled {
+		// 用独立的短超时 context，避免进程已经在退出流程中时因为父 ctx 已取消而发不出这条告别消息
+		notifyCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := app.sender.SendText(notifyCtx, app.lifecycleNotifyChatIDs(), "news-fetcher 已正常关闭"); err != nil {
+			log.Printf("推送关闭通知失败: %v", err)
+		}
+		cancel()
+	}
+
 	return nil
 }
 
-func (a *App) processNews(ctx context.Context) error {
-	// 获取新闻列表
-	newsList, err := a.fetcher.Fetch(ctx)
-			if err != nil {
-		return fmt.Errorf("获取新闻失败: %v", err)
+// lifecycleNotifyChatIDs 返回启动/关闭通知的目标聊天：配置了 lifecycle_notify.chat_id
+// 时只发到这一个聊天，否则返回空切片，由 Sender.SendText 回退到 telegram.bot.chat_ids
+func (app *App) lifecycleNotifyChatIDs() []string {
+	if app.cfg.LifecycleNotify.ChatID != "" {
+		return []string{app.cfg.LifecycleNotify.ChatID}
 	}
+	return nil
+}
 
-	log.Printf("原始获取到 %d 条新闻", len(newsList))
+// handleLatestCommand 处理只读的 /latest 命令，返回最近成功发送的条目标题列表
+func (app *App) handleLatestCommand(ctx context.Context, msg *tgbotapi.Message, args string) (string, error) {
+	app.recentMu.Lock()
+	items := append([]*models.News(nil), app.recentSent...)
+	app.recentMu.Unlock()
 
-	// 如果没有新闻，直接返回
-	if len(newsList) == 0 {
-		log.Printf("没有获取到新的新闻")
-		return nil
+	if len(items) == 0 {
+		return "暂无最近发送的新闻", nil
 	}
-	
-	// 过滤并处理新新闻
-	var newNews []*models.News
-	for _, news := range newsList {
-		// 检查缓存
-		if _, exists := a.cache.Get(news.Source, news.ID); !exists {
-			log.Printf("发现新新闻: %s (ID: %s)", news.OriginalTitle, news.ID)
-			newNews = append(newNews, news)
+
+	var text strings.Builder
+	text.WriteString("最近发送的新闻：\n")
+	for i := len(items) - 1; i >= 0; i-- {
+		text.WriteString(fmt.Sprintf("- %s\n", items[i].OriginalTitle))
+	}
+	return text.String(), nil
+}
+
+// handleInlineQuery 处理内联查询（用户在任意聊天输入 "@botname 关键词" 触发），
+// 把命中的最近发送条目作为可分享的结果返回。仓库里没有全文检索索引，archive
+// 也只保存消息ID和内容指纹、不保存正文，因此没有可供检索的持久化归档，这里只能
+// 在进程内存里保留的最近 maxRecentSent 条条目范围内做大小写不敏感的标题子串匹配，
+// 进程重启或超出该窗口的历史条目搜不到
+func (app *App) handleInlineQuery(ctx context.Context, query *tgbotapi.InlineQuery) ([]interface{}, error) {
+	keywords := strings.ToLower(strings.TrimSpace(query.Query))
+
+	app.recentMu.Lock()
+	items := append([]*models.News(nil), app.recentSent...)
+	app.recentMu.Unlock()
+
+	const maxResults = 20
+	var results []interface{}
+	for i := len(items) - 1; i >= 0 && len(results) < maxResults; i-- {
+		news := items[i]
+		if keywords != "" && !strings.Contains(strings.ToLower(news.OriginalTitle), keywords) {
+			continue
 		}
+		messageText := fmt.Sprintf("📰 %s\n\n%s", news.OriginalTitle, news.Link)
+		article := tgbotapi.NewInlineQueryResultArticle(fmt.Sprintf("%s:%s", news.Source, news.ID), news.OriginalTitle, messageText)
+		article.Description = news.Link
+		results = append(results, article)
 	}
-	
-	// 如果没有新新闻，直接返回
-	if len(newNews) == 0 {
-		log.Printf("没有新的新闻需要处理")
-		return nil
+	return results, nil
+}
+
+// handleStatusCommand 处理 /status 命令，列出已开启熔断的数据源及其当前状态，
+// 以及每个已尝试过抓取的数据源的健康状况（成功率、平均延迟、最近成功时间、
+// 是否已因长期失效被自动禁用），未给任何数据源开启熔断/尚无健康数据时提示无可用信息
+func (app *App) handleStatusCommand(ctx context.Context, msg *tgbotapi.Message, args string) (string, error) {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("调度：跳过 %d 轮，紧跟/并发 %d 轮\n",
+		atomic.LoadInt64(&app.cycleSkipped), atomic.LoadInt64(&app.cycleOverlapped)))
+
+	if app.deliveryQueue != nil {
+		text.WriteString(fmt.Sprintf("优先级队列：%d 条待发送\n", app.deliveryQueue.Pending()))
 	}
 
-	log.Printf("准备处理 %d 条新新闻", len(newNews))
-	
-	// 如果启用了AI分析
-	if a.cfg.AI != nil && a.cfg.AI.Enabled {
-		log.Printf("开始AI分析...")
-		if err := a.analyzer.AnalyzeNews(ctx, newNews); err != nil {
-			log.Printf("AI分析失败: %v", err)
-			return err
+	status := app.fetcher.BreakerStatus()
+	if len(status) == 0 {
+		text.WriteString("未开启数据源熔断\n")
+	} else {
+		urls := make([]string, 0, len(status))
+		for url := range status {
+			urls = append(urls, url)
+		}
+		sort.Strings(urls)
+
+		text.WriteString("数据源熔断状态：\n")
+		for _, url := range urls {
+			snapshot := status[url]
+			text.WriteString(fmt.Sprintf("- %s: %s (连续失败 %d 次)\n", url, snapshot.State, snapshot.Failures))
 		}
-		log.Printf("AI分析完成")
 	}
-	
-	// 处理每条新闻
-	for _, news := range newNews {
-		log.Printf("正在处理新闻: %s", news.OriginalTitle)
-	
-		// 发送新闻
-		if err := a.sender.SendNews(ctx, news); err != nil {
-			log.Printf("发送新闻失败: %v", err)
-			continue
+
+	health := app.fetcher.HealthStatus()
+	if len(health) == 0 {
+		text.WriteString("暂无数据源健康数据")
+		return text.String(), nil
+	}
+
+	urls := make([]string, 0, len(health))
+	for url := range health {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+
+	text.WriteString("数据源健康状况：\n")
+	for _, url := range urls {
+		snapshot := health[url]
+		disabled := ""
+		if snapshot.Disabled {
+			disabled = "，已自动禁用"
+		}
+		lastSuccess := "从未成功"
+		if !snapshot.LastSuccess.IsZero() {
+			lastSuccess = snapshot.LastSuccess.Format("2006-01-02 15:04:05")
 		}
+		text.WriteString(fmt.Sprintf("- %s: 成功率 %.0f%% (%d/%d)，平均延迟 %s，最近成功 %s%s\n",
+			url, snapshot.SuccessRate()*100, snapshot.Successes, snapshot.Attempts, snapshot.AvgLatency, lastSuccess, disabled))
+	}
+	return text.String(), nil
+}
 
-		// 只有在成功发送后才更新缓存
-		if err := a.cache.Set(news.Source, news.ID, true, time.Duration(a.cfg.Cache.TTL)*time.Second); err != nil {
-			log.Printf("更新缓存失败: %v", err)
-		} else {
-			log.Printf("成功缓存新闻: %s (ID: %s), TTL: %d秒", news.OriginalTitle, news.ID, a.cfg.Cache.TTL)
+// handleBudgetCommand 处理 /budget 命令，汇总 AI 分析、翻译以及各数据源当天已消耗
+// 的请求次数，帮助在触发优雅降级（跳过分析/翻译、直接发送原文）之前提前发现异常
+func (app *App) handleBudgetCommand(ctx context.Context, msg *tgbotapi.Message, args string) (string, error) {
+	var text strings.Builder
+
+	if used, limit := app.analyzer.BudgetUsage(); limit > 0 {
+		text.WriteString(fmt.Sprintf("AI 分析：今日 %d/%d 次\n", used, limit))
+	} else {
+		text.WriteString("AI 分析：未设置每日额度\n")
+	}
+
+	fetchStatus := app.fetcher.FetchBudgetStatus()
+	if len(fetchStatus) == 0 {
+		text.WriteString("未给任何数据源设置每日抓取额度")
+		return text.String(), nil
+	}
+
+	urls := make([]string, 0, len(fetchStatus))
+	for url := range fetchStatus {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+
+	text.WriteString("数据源抓取额度：\n")
+	for _, url := range urls {
+		snapshot := fetchStatus[url]
+		text.WriteString(fmt.Sprintf("- %s: 今日已用 %d 次\n", url, snapshot.Used))
+	}
+	return text.String(), nil
+}
+
+// handleDedupStatsCommand 处理 /dedupstats 命令，按天列出各去重环节（ID 缓存、
+// 模糊标题指纹、发送前内容哈希）淘汰掉的条目数，帮助判断某个环节的阈值是设得
+// 太松还是太紧，不必只凭感觉调参数；当前不含 embeddings 语义去重，因为本仓库
+// 尚未实现该环节
+func (app *App) handleDedupStatsCommand(ctx context.Context, msg *tgbotapi.Message, args string) (string, error) {
+	if app.dedupStats == nil {
+		return "去重统计未启用", nil
+	}
+
+	report := app.dedupStats.Report()
+	if len(report) == 0 {
+		return "暂无去重统计数据", nil
+	}
+
+	var text strings.Builder
+	text.WriteString("去重调优报告：\n")
+	for _, day := range report {
+		layers := make([]string, 0, len(day.Counts))
+		for layer := range day.Counts {
+			layers = append(layers, layer)
 		}
+		sort.Strings(layers)
 
-		log.Printf("成功处理新闻: %s", news.OriginalTitle)
+		parts := make([]string, 0, len(layers))
+		for _, layer := range layers {
+			parts = append(parts, fmt.Sprintf("%s: %d", layer, day.Counts[layer]))
+		}
+		text.WriteString(fmt.Sprintf("- %s: %s\n", day.Day, strings.Join(parts, ", ")))
 	}
+	return text.String(), nil
+}
 
-	return nil
+// handleRecapCommand 处理 /recap <duration> 命令（如 "/recap 8h"），把过去指定
+// 时长内推送到本聊天的新闻标题交给 AI 归纳成一份简报，用于用户离开一段时间后
+// 快速了解错过了什么；AI 未开启或当日额度用尽时退化为直接列出标题。
+// 归纳范围限于进程内存里保留的最近 maxRecentSent 条条目（供 /latest 复用的同一份
+// 缓冲区），不是持久化归档——archive 只保存消息ID和内容指纹、不保存正文，见
+// handleInlineQuery 的说明；进程重启或超出该窗口的条目召回不到
+func (app *App) handleRecapCommand(ctx context.Context, msg *tgbotapi.Message, args string) (string, error) {
+	window, err := time.ParseDuration(strings.TrimSpace(args))
+	if err != nil || window <= 0 {
+		return "用法: /recap <时长>，如 /recap 8h、/recap 30m", nil
+	}
+	cutoff := time.Now().Add(-window)
+	chatID := strconv.FormatInt(msg.Chat.ID, 10)
+
+	app.recentMu.Lock()
+	items := append([]*models.News(nil), app.recentSent...)
+	app.recentMu.Unlock()
+
+	var missed []*models.News
+	for _, news := range items {
+		if news.CreateTime.Before(cutoff) {
+			continue
+		}
+		if !app.newsDeliveredToChat(news, chatID) {
+			continue
+		}
+		missed = append(missed, news)
+	}
+	if len(missed) == 0 {
+		return fmt.Sprintf("过去 %s 内没有推送给本聊天的新闻", window), nil
+	}
+
+	if recap, ok := app.analyzer.Recap(ctx, missed); ok {
+		return fmt.Sprintf("过去 %s 的简报（共 %d 条）：\n%s", window, len(missed), recap), nil
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("过去 %s 推送给本聊天的新闻（共 %d 条）：\n", window, len(missed)))
+	for i := len(missed) - 1; i >= 0; i-- {
+		text.WriteString(fmt.Sprintf("- %s\n", missed[i].OriginalTitle))
+	}
+	return text.String(), nil
 }
 
-func main() {
-	// 设置日志格式
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
-	log.Println("启动新闻抓取服务...")
+// handleTestFormatCommand 用示例新闻按本聊天的模板、路由、语言、解析模式等配置
+// 直接发送一条预览消息，供管理员即时验证排版改动，无需等待下一条真实新闻
+func (app *App) handleTestFormatCommand(ctx context.Context, msg *tgbotapi.Message, args string) (string, error) {
+	chatID := strconv.FormatInt(msg.Chat.ID, 10)
+	if err := app.sender.SendPreview(ctx, chatID); err != nil {
+		return fmt.Sprintf("发送预览失败: %v", err), nil
+	}
+	return "", nil
+}
 
-	// 创建数据目录
-	dataDir := filepath.Join(os.Getenv("HOME"), ".news-fetcher")
-	if err := os.MkdirAll(dataDir, 0755); err != nil {
-		log.Fatalf("创建数据目录失败: %v", err)
+// newsDeliveredToChat 判断按抽样等路由规则，某条新闻当时是否会投递到指定聊天，
+// 复用 sampledChatIDs 的判定逻辑，避免 /recap 把未推送给本聊天的条目也算进去
+func (app *App) newsDeliveredToChat(news *models.News, chatID string) bool {
+	chatIDs, deliverable := app.sampledChatIDs(news)
+	if !deliverable {
+		return false
 	}
-	log.Printf("数据目录: %s", dataDir)
+	if chatIDs == nil {
+		return true
+	}
+	for _, id := range chatIDs {
+		if id == chatID {
+			return true
+		}
+	}
+	return false
+}
 
-	// 加载配置
-	cfg, err := config.LoadConfig("config/config.yaml")
-	if err != nil {
-		log.Fatalf("加载配置失败: %v", err)
+// handleReceiptsCommand 处理 /receipts <source> <id> 命令，返回该条目在各聊天的
+// 投递回执（发送时间、尝试次数、最终状态），用于排查"为什么这条没有出现在某个
+// 频道"而不必翻日志；需要开启 delivery_receipts 才有数据
+func (app *App) handleReceiptsCommand(ctx context.Context, msg *tgbotapi.Message, args string) (string, error) {
+	if app.archive == nil {
+		return "未开启投递回执记录", nil
 	}
 
-	// 创建应用实例
-	app, err := NewApp(cfg)
-	if err != nil {
-		log.Fatalf("初始化应用失败: %v", err)
+	fields := strings.Fields(args)
+	if len(fields) != 2 {
+		return "用法: /receipts <source> <id>", nil
 	}
 
-	// 运行应用
-	ctx := context.Background()
-	if err := app.Run(ctx); err != nil {
-		log.Fatalf("应用运行失败: %v", err)
+	record, ok := app.archive.Get(archive.Key(fields[0], fields[1]))
+	if !ok || len(record.Receipts) == 0 {
+		return "未找到该条目的投递记录", nil
+	}
+
+	chatIDs := make([]string, 0, len(record.Receipts))
+	for chatID := range record.Receipts {
+		chatIDs = append(chatIDs, chatID)
+	}
+	sort.Strings(chatIDs)
+
+	var text strings.Builder
+	text.WriteString("投递回执：\n")
+	for _, chatID := range chatIDs {
+		r := record.Receipts[chatID]
+		if r.Status == "sent" {
+			text.WriteString(fmt.Sprintf("- %s: 成功（消息ID %d，尝试 %d 次，%s）\n",
+				chatID, r.MessageID, r.Attempts, r.SentAt.Format("2006-01-02 15:04:05")))
+			continue
+		}
+		text.WriteString(fmt.Sprintf("- %s: 失败（尝试 %d 次，%s）：%s\n",
+			chatID, r.Attempts, r.SentAt.Format("2006-01-02 15:04:05"), r.Error))
+	}
+	return text.String(), nil
+}
+
+// retryDeadLetter 尝试把一条死信记录重新投递到其原本失败的聊天，成功后从死信队列
+// 移除，仍然失败则用最新的失败详情覆盖原记录；供 CLI 的 deadletter retry 子命令和
+// /deadletter 机器人命令共用
+func (a *App) retryDeadLetter(ctx context.Context, key string) error {
+	if a.deadLetter == nil {
+		return fmt.Errorf("未开启死信队列")
+	}
+	item, ok := a.deadLetter.Get(key)
+	if !ok {
+		return fmt.Errorf("未找到该死信记录: %s", key)
+	}
+
+	var timeouts config.StageTimeoutsConfig
+	if a.cfg.App != nil && a.cfg.App.Timeouts != nil {
+		timeouts = *a.cfg.App.Timeouts
+	}
+	sendCtx, cancel := stageContext(ctx, timeouts.Send)
+	defer cancel()
+
+	results, err := a.sender.SendNewsToChatsWithReceipts(sendCtx, item.News, item.ChatIDs)
+	if err != nil {
+		failedChats := make([]string, 0, len(results))
+		for _, r := range results {
+			if r.Err != nil {
+				failedChats = append(failedChats, r.ChatID)
+			}
+		}
+		if updateErr := a.deadLetter.Add(key, deadletter.Item{
+			News:     item.News,
+			ChatIDs:  failedChats,
+			Error:    err.Error(),
+			FailedAt: time.Now(),
+		}); updateErr != nil {
+			log.Printf("更新死信记录失败: %v", updateErr)
+		}
+		return err
+	}
+
+	if err := a.deadLetter.Remove(key); err != nil {
+		log.Printf("从死信队列移除记录失败: %v", err)
+	}
+	return nil
+}
+
+// handleDeadLetterCommand 处理 /deadletter list|retry <source> <id> 命令，用于在
+// 排查清楚投递失败原因后直接在 Telegram 里手动重新投递，而不必登录服务器执行 CLI
+func (app *App) handleDeadLetterCommand(ctx context.Context, msg *tgbotapi.Message, args string) (string, error) {
+	if app.deadLetter == nil {
+		return "未开启死信队列", nil
+	}
+
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		return "用法: /deadletter list|retry <source> <id>", nil
+	}
+
+	switch fields[0] {
+	case "list":
+		items := app.deadLetter.List()
+		if len(items) == 0 {
+			return "死信队列为空", nil
+		}
+		keys := make([]string, 0, len(items))
+		for k := range items {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var text strings.Builder
+		text.WriteString("死信队列：\n")
+		for _, k := range keys {
+			item := items[k]
+			text.WriteString(fmt.Sprintf("- %s：失败于 %s，失败聊天 %v：%s\n",
+				k, item.FailedAt.Format("2006-01-02 15:04:05"), item.ChatIDs, item.Error))
+		}
+		return text.String(), nil
+	case "retry":
+		if len(fields) != 3 {
+			return "用法: /deadletter retry <source> <id>", nil
+		}
+		key := deadletter.Key(fields[1], fields[2])
+		if err := app.retryDeadLetter(ctx, key); err != nil {
+			return fmt.Sprintf("重新投递失败: %v", err), nil
+		}
+		return "重新投递成功", nil
+	default:
+		return "用法: /deadletter list|retry <source> <id>", nil
+	}
+}
+
+// handleSubscribeCommand 处理 /subscribe <rss-url> 命令：校验并添加一个运行期 RSS 源，
+// 成功后立即持久化，保证进程重启后订阅依旧生效
+func (app *App) handleSubscribeCommand(ctx context.Context, msg *tgbotapi.Message, args string) (string, error) {
+	rssURL := strings.TrimSpace(args)
+	if rssURL == "" {
+		return "用法: /subscribe <RSS地址>", nil
+	}
+
+	if err := app.fetcher.AddRSSSource(ctx, &config.SourceConfig{URL: rssURL}); err != nil {
+		return "", fmt.Errorf("订阅失败: %v", err)
+	}
+
+	if err := app.subStore.Save(app.fetcher.RSSSourceURLs()); err != nil {
+		log.Printf("持久化订阅列表失败: %v", err)
+	}
+	return "已订阅: " + rssURL, nil
+}
+
+// handleUnsubscribeCommand 处理 /unsubscribe <rss-url> 命令
+func (app *App) handleUnsubscribeCommand(ctx context.Context, msg *tgbotapi.Message, args string) (string, error) {
+	rssURL := strings.TrimSpace(args)
+	if rssURL == "" {
+		return "用法: /unsubscribe <RSS地址>", nil
+	}
+
+	if !app.fetcher.RemoveRSSSource(rssURL) {
+		return "未找到该订阅: " + rssURL, nil
+	}
+
+	if err := app.subStore.Save(app.fetcher.RSSSourceURLs()); err != nil {
+		log.Printf("持久化订阅列表失败: %v", err)
+	}
+	return "已取消订阅: " + rssURL, nil
+}
+
+// handleWatchCommand 处理 /watch <关键词> 命令：为发起者本人订阅一个关键词，
+// 命中的条目除频道推送外还会额外私信给订阅者
+func (app *App) handleWatchCommand(ctx context.Context, msg *tgbotapi.Message, args string) (string, error) {
+	if msg.From == nil {
+		return "", fmt.Errorf("无法确定调用者身份")
+	}
+	keyword := strings.TrimSpace(args)
+	if keyword == "" {
+		return "用法: /watch <关键词>", nil
+	}
+	if err := app.userWatch.Add(msg.From.ID, keyword); err != nil {
+		return "", fmt.Errorf("订阅关键词失败: %v", err)
+	}
+	return "已订阅关键词: " + keyword, nil
+}
+
+// handleUnwatchCommand 处理 /unwatch <关键词> 命令
+func (app *App) handleUnwatchCommand(ctx context.Context, msg *tgbotapi.Message, args string) (string, error) {
+	if msg.From == nil {
+		return "", fmt.Errorf("无法确定调用者身份")
+	}
+	keyword := strings.TrimSpace(args)
+	if keyword == "" {
+		return "用法: /unwatch <关键词>", nil
+	}
+	removed, err := app.userWatch.Remove(msg.From.ID, keyword)
+	if err != nil {
+		return "", fmt.Errorf("取消订阅关键词失败: %v", err)
+	}
+	if !removed {
+		return "未找到该关键词订阅: " + keyword, nil
+	}
+	return "已取消订阅关键词: " + keyword, nil
+}
+
+// readAckMarkup 未开启已读状态跟踪时返回 nil（消息不带按钮），否则把这条条目记入
+// 该用户的未读列表并返回一个附带"标记已读"按钮的内联键盘
+func (app *App) readAckMarkup(userID int64, title string) *tgbotapi.InlineKeyboardMarkup {
+	if app.readState == nil {
+		return nil
+	}
+
+	token, err := app.readState.Record(userID, title)
+	if err != nil {
+		log.Printf("记录未读条目失败: %v", err)
+		return nil
+	}
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ 标记已读", fmt.Sprintf("%s%d", readAckCallbackPrefix, token)),
+		),
+	)
+	return &markup
+}
+
+// handleUnreadCommand 处理 /unread 命令，列出发起者本人尚未点击"标记已读"的条目
+func (app *App) handleUnreadCommand(ctx context.Context, msg *tgbotapi.Message, args string) (string, error) {
+	if msg.From == nil {
+		return "", fmt.Errorf("无法确定调用者身份")
+	}
+
+	entries := app.readState.Unread(msg.From.ID)
+	if len(entries) == 0 {
+		return "没有未读条目", nil
+	}
+
+	var text strings.Builder
+	text.WriteString("未读条目：\n")
+	for _, e := range entries {
+		text.WriteString(fmt.Sprintf("- %s\n", e.Title))
+	}
+	return text.String(), nil
+}
+
+// handleReadAckCallback 处理"标记已读"按钮点击，data 为 readstate.Store 分配的短序号
+func (app *App) handleReadAckCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, data string) (string, error) {
+	if callback.From == nil {
+		return "", fmt.Errorf("无法确定调用者身份")
+	}
+
+	token, err := strconv.Atoi(data)
+	if err != nil {
+		return "", fmt.Errorf("无效的已读标记: %s", data)
+	}
+
+	found, err := app.readState.Ack(callback.From.ID, token)
+	if err != nil {
+		return "", fmt.Errorf("标记已读失败: %v", err)
+	}
+	if !found {
+		return "该条目已标记过", nil
+	}
+	return "已标记为已读", nil
+}
+
+// handleMuteCommand 处理 /mute <数据源> <时长> 命令，时长格式与 Go 的 time.ParseDuration
+// 一致，如 "6h"、"30m"
+func (app *App) handleMuteCommand(ctx context.Context, msg *tgbotapi.Message, args string) (string, error) {
+	fields := strings.Fields(args)
+	if len(fields) != 2 {
+		return "用法: /mute <数据源> <时长，如 6h>", nil
+	}
+	source, durationStr := fields[0], fields[1]
+
+	duration, err := time.ParseDuration(durationStr)
+	if err != nil {
+		return "", fmt.Errorf("时长格式错误: %v", err)
+	}
+
+	app.muter.Mute(source, duration)
+	return fmt.Sprintf("已静音数据源 %s，持续 %s", source, duration), nil
+}
+
+// handleUnmuteCommand 处理 /unmute <数据源> 命令，提前解除静音
+func (app *App) handleUnmuteCommand(ctx context.Context, msg *tgbotapi.Message, args string) (string, error) {
+	source := strings.TrimSpace(args)
+	if source == "" {
+		return "用法: /unmute <数据源>", nil
+	}
+	if !app.muter.Unmute(source) {
+		return "该数据源未处于静音状态: " + source, nil
+	}
+	return "已解除静音: " + source, nil
+}
+
+// handleMutedCommand 处理 /muted 命令，列出当前仍处于静音状态的数据源及到期时间
+func (app *App) handleMutedCommand(ctx context.Context, msg *tgbotapi.Message, args string) (string, error) {
+	muted := app.muter.List()
+	if len(muted) == 0 {
+		return "当前没有被静音的数据源", nil
+	}
+
+	var text strings.Builder
+	text.WriteString("当前静音的数据源：\n")
+	for source, until := range muted {
+		text.WriteString(fmt.Sprintf("- %s (至 %s)\n", source, until.Format("2006-01-02 15:04:05")))
+	}
+	return text.String(), nil
+}
+
+// postMorningBriefing 汇总自上次推送以来累积的条目，按重要性（情绪分数绝对值）排序，
+// 取前 TopN 条组成一条结构化消息推送
+func (app *App) postMorningBriefing(ctx context.Context) {
+	app.briefingMu.Lock()
+	items := app.briefingItems
+	app.briefingItems = nil
+	app.briefingMu.Unlock()
+
+	if len(items) == 0 {
+		log.Printf("早间简报周期内没有新条目，跳过推送")
+		return
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return math.Abs(items[i].SentimentScore) > math.Abs(items[j].SentimentScore)
+	})
+
+	topN := app.cfg.Briefing.TopN
+	if topN > 0 && len(items) > topN {
+		items = items[:topN]
+	}
+
+	// 挑选出重要条目后，按发布时间稳定排序再展示，避免简报内条目顺序杂乱，看起来像随手排的
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].CreateTime.Before(items[j].CreateTime)
+	})
+
+	byCategory := make(map[string][]*models.News)
+	var categories []string
+	for _, news := range items {
+		if _, ok := byCategory[news.Source]; !ok {
+			categories = append(categories, news.Source)
+		}
+		byCategory[news.Source] = append(byCategory[news.Source], news)
+	}
+
+	var text strings.Builder
+	text.WriteString("🌅 早间简报\n\n")
+	for _, category := range categories {
+		text.WriteString(fmt.Sprintf("【%s】\n", category))
+		for _, news := range byCategory[category] {
+			text.WriteString(fmt.Sprintf("- %s\n", news.OriginalTitle))
+		}
+		text.WriteString("\n")
+	}
+
+	var chatIDs []string
+	if app.cfg.Briefing.ChatID != "" {
+		chatIDs = []string{app.cfg.Briefing.ChatID}
+	}
+
+	if err := app.sender.SendText(ctx, chatIDs, text.String()); err != nil {
+		log.Printf("推送早间简报失败: %v", err)
+	}
+}
+
+// postDigest 汇总一个摘要计划当前累积的条目并推送，推送后清空该计划的缓冲区；
+// IntervalSeconds 为 0 的计划每次只会累积一条，效果等同于命中后立即单独推送
+func (app *App) postDigest(ctx context.Context, schedule *digest.Schedule) {
+	items := schedule.Flush()
+	if len(items) == 0 {
+		return
+	}
+
+	topN := schedule.Config.TopN
+	if topN > 0 && len(items) > topN {
+		items = items[len(items)-topN:]
+	}
+
+	title := schedule.Config.Name
+	if title == "" {
+		title = "摘要"
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("📋 %s\n\n", title))
+	for _, news := range items {
+		text.WriteString(fmt.Sprintf("- %s\n", news.OriginalTitle))
+	}
+
+	var chatIDs []string
+	if schedule.Config.ChatID != "" {
+		chatIDs = []string{schedule.Config.ChatID}
+	}
+
+	if err := app.sender.SendText(ctx, chatIDs, text.String()); err != nil {
+		log.Printf("推送摘要计划 %q 失败: %v", schedule.Config.Name, err)
+	}
+}
+
+// escalateFailedNews 对主推送渠道投递失败的严重条目按配置的升级链依次尝试通知，
+// 返回实际尝试过的通道类型（无论成败），供调用方连同死信记录一并存档
+func (app *App) escalateFailedNews(ctx context.Context, news *models.News) []string {
+	text := fmt.Sprintf("🚨 严重条目主渠道投递失败，正在升级通知\n%s\n%s", news.OriginalTitle, news.Link)
+	path, err := app.escalator.Escalate(ctx, text)
+	if err != nil {
+		log.Printf("升级通知链全部失败（已尝试 %v）: %v", path, err)
+	} else {
+		log.Printf("升级通知链发送成功，尝试路径: %v", path)
+	}
+	return path
+}
+
+// postSentimentIndex 汇总自上次推送以来的情绪指数并发送到配置的聊天
+func (app *App) postSentimentIndex(ctx context.Context) {
+	scores := app.sentiment.Snapshot()
+	if len(scores) == 0 {
+		log.Printf("本周期没有可用于计算情绪指数的新闻，跳过推送")
+		return
+	}
+
+	var text strings.Builder
+	text.WriteString("📊 新闻情绪指数\n\n")
+	for category, score := range scores {
+		text.WriteString(fmt.Sprintf("%s: %.2f\n", category, score))
+	}
+
+	var chatIDs []string
+	if app.cfg.Sentiment.ChatID != "" {
+		chatIDs = []string{app.cfg.Sentiment.ChatID}
+	}
+
+	if err := app.sender.SendText(ctx, chatIDs, text.String()); err != nil {
+		log.Printf("推送情绪指数失败: %v", err)
+	}
+}
+
+// cycleStats 汇总一轮 processNews 的处理结果，供 cycle_summary 推送使用
+type cycleStats struct {
+	Fetched  int
+	New      int
+	Sent     int
+	Filtered int
+	Errors   int
+}
+
+// postCycleSummary 推送 cycle_completed 事件，并在配置了 cycle_summary 时额外向
+// 管理聊天推送本轮处理的简要统计；OnlyNotable 开启时只在本轮出现失败或抓取量明显
+// 高于近期平均值时才推送，抓取量基线用简单的指数移动平均在多轮之间累积，不需要
+// 额外持久化
+func (a *App) postCycleSummary(ctx context.Context, stats cycleStats) {
+	eventhook.Emit("cycle_completed", "", fmt.Sprintf("抓取 %d，新增 %d，发送 %d，过滤 %d，失败 %d",
+		stats.Fetched, stats.New, stats.Sent, stats.Filtered, stats.Errors))
+
+	cfg := a.cfg.App.CycleSummary
+	if cfg == nil || !cfg.Enabled {
+		return
+	}
+
+	a.cycleSummaryMu.Lock()
+	prevAvg := a.avgFetchedPerCycle
+	if prevAvg <= 0 {
+		a.avgFetchedPerCycle = float64(stats.Fetched)
+	} else {
+		a.avgFetchedPerCycle = prevAvg*0.8 + float64(stats.Fetched)*0.2
+	}
+	a.cycleSummaryMu.Unlock()
+
+	factor := cfg.VolumeFactor
+	if factor <= 0 {
+		factor = 3
+	}
+	volumeSpike := prevAvg > 0 && float64(stats.Fetched) > prevAvg*factor
+
+	if cfg.OnlyNotable && stats.Errors == 0 && !volumeSpike {
+		return
+	}
+
+	text := fmt.Sprintf("📋 本轮处理：抓取 %d，新增 %d，发送 %d，过滤 %d，失败 %d",
+		stats.Fetched, stats.New, stats.Sent, stats.Filtered, stats.Errors)
+	if volumeSpike {
+		text += fmt.Sprintf("\n⚠️ 抓取量 %d 明显高于近期平均 %.0f", stats.Fetched, prevAvg)
+	}
+
+	var chatIDs []string
+	if cfg.ChatID != "" {
+		chatIDs = []string{cfg.ChatID}
+	}
+	if err := a.sender.SendText(ctx, chatIDs, text); err != nil {
+		log.Printf("推送本轮处理摘要失败: %v", err)
+	}
+}
+
+// checkSourceAnomalies 检查本轮抓取结果中每个数据源的条目数是否明显超过其近期
+// 基线（指数移动平均），超过时向管理聊天告警，AutoThrottle 开启且配置了 mute
+// 时同时自动静音该数据源（较长的静音时长，需要管理员执行 /unmute 提前解除），
+// 避免改版、被刷量或解析器 bug 导致的异常大批量条目未经确认就直接推送出去
+func (a *App) checkSourceAnomalies(ctx context.Context, newsList []*models.News) {
+	cfg := a.cfg.App.AnomalyDetection
+	if cfg == nil || !cfg.Enabled {
+		return
+	}
+
+	counts := make(map[string]int)
+	for _, news := range newsList {
+		counts[news.Source]++
+	}
+
+	multiplier := cfg.Multiplier
+	if multiplier <= 0 {
+		multiplier = 10
+	}
+
+	var chatIDs []string
+	if cfg.ChatID != "" {
+		chatIDs = []string{cfg.ChatID}
+	}
+
+	a.sourceBaselineMu.Lock()
+	defer a.sourceBaselineMu.Unlock()
+	for source, count := range counts {
+		baseline := a.sourceBaseline[source]
+		if baseline <= 0 {
+			a.sourceBaseline[source] = float64(count)
+			continue
+		}
+
+		if float64(count) > baseline*multiplier {
+			log.Printf("数据源 %s 本轮抓取量 %d 明显高于基线 %.1f，疑似异常", source, count, baseline)
+			text := fmt.Sprintf("⚠️ 数据源 %s 本轮抓取量 %d 明显高于近期基线 %.1f，可能是改版、被刷量或解析器异常", source, count, baseline)
+			if cfg.AutoThrottle && a.muter != nil {
+				a.muter.Mute(source, 24*time.Hour)
+				text += "\n已自动静音该数据源，确认无误后请执行 /unmute 提前解除"
+			}
+			if err := a.sender.SendText(ctx, chatIDs, text); err != nil {
+				log.Printf("推送数据源异常告警失败: %v", err)
+			}
+		}
+
+		a.sourceBaseline[source] = baseline*0.8 + float64(count)*0.2
+	}
+}
+
+// sortNewsForDelivery 按配置的方式稳定重排本轮待投递的新闻：by 为 "importance" 时
+// 按情绪分数绝对值从高到低排列，其余情况（含空值）按发布时间从早到晚排列
+func sortNewsForDelivery(items []*models.News, by string) {
+	if by == "importance" {
+		sort.SliceStable(items, func(i, j int) bool {
+			return math.Abs(items[i].SentimentScore) > math.Abs(items[j].SentimentScore)
+		})
+		return
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].CreateTime.Before(items[j].CreateTime)
+	})
+}
+
+// sampledChatIDs 按 sampling 配置过滤出本条新闻实际应该投递的聊天列表：未开启抽样时
+// 返回 (nil, true)，nil 交给 sender 按其默认行为投递到 telegram.bot.chat_ids 全部聊天；
+// 开启抽样但该条目未命中任何聊天的抽样时返回 (nil, false)，调用方应仅归档不投递
+func (a *App) sampledChatIDs(news *models.News) ([]string, bool) {
+	if a.cfg.Telegram.Sampling == nil || !a.cfg.Telegram.Sampling.Enabled {
+		return nil, true
+	}
+	importance := math.Abs(news.SentimentScore)
+	var chatIDs []string
+	for _, chatID := range a.cfg.Telegram.Bot.ChatIDs {
+		if a.sampler.Allow(chatID, importance) {
+			chatIDs = append(chatIDs, chatID)
+		}
+	}
+	return chatIDs, len(chatIDs) > 0
+}
+
+// newDeliveryReceipt 根据一次投递尝试的结果构造一条投递回执，err 为 nil 时状态为
+// "sent"，否则为 "failed" 并附带错误信息
+func newDeliveryReceipt(messageID int64, attempts int, err error) archive.DeliveryReceipt {
+	status := "sent"
+	errMsg := ""
+	if err != nil {
+		status = "failed"
+		errMsg = err.Error()
+	}
+	return archive.DeliveryReceipt{
+		SentAt:    time.Now(),
+		MessageID: messageID,
+		Attempts:  attempts,
+		Status:    status,
+		Error:     errMsg,
+	}
+}
+
+// stageContext 若 seconds > 0，返回一个绑定该超时的子 context 及其 cancel 函数；
+// 否则原样返回 ctx 和一个空操作的 cancel，调用方始终可以无条件 defer cancel()
+func stageContext(ctx context.Context, seconds int) (context.Context, context.CancelFunc) {
+	if seconds <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, time.Duration(seconds)*time.Second)
+}
+
+func (a *App) processNews(ctx context.Context) error {
+	var timeouts config.StageTimeoutsConfig
+	if a.cfg.App != nil && a.cfg.App.Timeouts != nil {
+		timeouts = *a.cfg.App.Timeouts
+	}
+
+	// 整轮处理的总截止时间，防止某一篇文章或某个卡住的上游服务把整轮处理
+	// 无限期拖住，与下一次定时抓取重叠
+	ctx, cancelCycle := stageContext(ctx, timeouts.Cycle)
+	defer cancelCycle()
+
+	// 获取新闻列表
+	fetchCtx, cancelFetch := stageContext(ctx, timeouts.Fetch)
+	newsList, err := a.fetcher.Fetch(fetchCtx)
+	cancelFetch()
+	if err != nil {
+		return fmt.Errorf("获取新闻失败: %v", err)
+	}
+
+	log.Printf("原始获取到 %d 条新闻", len(newsList))
+
+	a.checkSourceAnomalies(ctx, newsList)
+
+	// 如果没有新闻，直接返回
+	if len(newsList) == 0 {
+		log.Printf("没有获取到新的新闻")
+		return nil
+	}
+
+	// 过滤并处理新新闻；开启了内容更新编辑推送时，已发送过的条目如果内容发生了变化
+	// （原文、译文或 AI 分析）也会被当作待处理条目放行，后续按编辑而不是重新发送处理；
+	// 开启了 AI 分析跨周期重试时，上次分析失败的条目在最大重试时长内也会被放行重新分析
+	var newNews []*models.News
+	for _, news := range newsList {
+		// 已经在优先级队列里排队等待发送（上一轮预算不够，留到本轮）的条目直接跳过：
+		// 它还没有被弹出、发送，所以既不会命中下面的 a.cache 去重，也不会命中 archive，
+		// 如果不在这里提前拦截，会被当作"新新闻"重新走一遍 AI 分析——分析结果最终还是
+		// 会在 Queue.Push 时因为 key 已存在而被静默丢弃，等于白白浪费一次分析额度
+		if a.deliveryQueue != nil && a.deliveryQueue.Contains(news.Source, news.ID) {
+			continue
+		}
+		if a.pendingAI != nil {
+			if firstFailed, ok := a.pendingAI.Get(archive.Key(news.Source, news.ID)); ok {
+				if time.Since(firstFailed) <= time.Duration(a.cfg.AI.RetryMaxAge)*time.Second {
+					log.Printf("重新尝试分析: %s (ID: %s)", news.OriginalTitle, news.ID)
+					newNews = append(newNews, news)
+					continue
+				}
+				log.Printf("分析重试已超过最大时长，放弃: %s (ID: %s)", news.OriginalTitle, news.ID)
+				if err := a.pendingAI.Clear(archive.Key(news.Source, news.ID)); err != nil {
+					log.Printf("清除待重试分析记录失败: %v", err)
+				}
+			}
+		}
+		if a.archive != nil {
+			if record, ok := a.archive.Get(archive.Key(news.Source, news.ID)); ok {
+				if archive.ContentHash(news.OriginalContent, news.TranslatedContent, news.Summary, news.Analysis) == record.ContentHash {
+					continue
+				}
+				log.Printf("发现内容更新: %s (ID: %s)", news.OriginalTitle, news.ID)
+				newNews = append(newNews, news)
+				continue
+			}
+		}
+		// 检查缓存
+		if _, exists := a.cache.Get(news.Source, news.ID); !exists {
+			log.Printf("发现新新闻: %s (ID: %s)", news.OriginalTitle, news.ID)
+			newNews = append(newNews, news)
+		} else if a.dedupStats != nil {
+			a.dedupStats.Record("id_cache")
+		}
+	}
+
+	// 如果没有新新闻，直接返回
+	if len(newNews) == 0 {
+		log.Printf("没有新的新闻需要处理")
+		return nil
+	}
+
+	// 记录本轮进入处理流水线的条目，崩溃重启后可以据此判断哪些条目已经
+	// 走完抓取、去重阶段，避免整批状态因为没有落盘而被误判为完全没处理过
+	if a.journal != nil {
+		for _, news := range newNews {
+			if err := a.journal.SetStage(journal.Key(news.Source, news.ID), "fetched"); err != nil {
+				log.Printf("记录处理进度失败: %v", err)
+			}
+		}
+	}
+
+	// 关注名单打标与过滤：命中的条目打上 watchlist:<entity> 标签，
+	// 配置了 suppress_others 时未命中的条目直接丢弃
+	if a.watchlist != nil {
+		var filtered []*models.News
+		for _, news := range newNews {
+			hits := a.watchlist.Match(news.OriginalTitle, news.OriginalContent)
+			for _, hit := range hits {
+				news.Tags = append(news.Tags, "watchlist:"+hit)
+			}
+			if len(hits) == 0 && a.cfg.Watchlist.SuppressOthers {
+				log.Printf("未命中关注名单，丢弃: %s (ID: %s)", news.OriginalTitle, news.ID)
+				continue
+			}
+			filtered = append(filtered, news)
+		}
+		newNews = filtered
+	}
+
+	if len(newNews) == 0 {
+		log.Printf("关注名单过滤后没有需要处理的新闻")
+		return nil
+	}
+
+	log.Printf("准备处理 %d 条新新闻", len(newNews))
+
+	// 正文为空但带有图片的条目（如交易所/Twitter 截图公告），尝试用 OCR 提取文字，
+	// 让翻译和 AI 分析仍然有内容可用
+	if a.ocr != nil {
+		for _, news := range newNews {
+			if news.OriginalContent != "" || news.ImageURL == "" {
+				continue
+			}
+			text, err := a.ocr.Extract(ctx, news.ImageURL)
+			if err != nil {
+				log.Printf("OCR 提取图片文字失败: %s: %v", news.ImageURL, err)
+				continue
+			}
+			news.OriginalContent = text
+		}
+	}
+
+	// 记录本轮通过跨周期重试补全了分析的条目，这些条目稍后按追加分析结果处理，
+	// 而不是当作全新条目重新发送一遍
+	retriedAnalysisOK := make(map[string]bool)
+
+	// 如果启用了AI分析
+	if a.cfg.AI != nil && a.cfg.AI.Enabled {
+		log.Printf("开始AI分析...")
+		analyzeCtx, cancelAnalyze := stageContext(ctx, timeouts.Analyze)
+		err := a.analyzer.AnalyzeNews(analyzeCtx, newNews)
+		cancelAnalyze()
+		if err != nil {
+			log.Printf("AI分析失败: %v", err)
+			return err
+		}
+		log.Printf("AI分析完成")
+
+		if a.journal != nil {
+			for _, news := range newNews {
+				if err := a.journal.SetStage(journal.Key(news.Source, news.ID), "analyzed"); err != nil {
+					log.Printf("记录处理进度失败: %v", err)
+				}
+			}
+		}
+
+		if a.pendingAI != nil {
+			for _, news := range newNews {
+				key := archive.Key(news.Source, news.ID)
+				_, wasPending := a.pendingAI.Get(key)
+				if news.TranslatedContent == "" {
+					if err := a.pendingAI.MarkFailed(key); err != nil {
+						log.Printf("记录待重试分析失败: %v", err)
+					}
+					continue
+				}
+				if wasPending {
+					retriedAnalysisOK[key] = true
+					if err := a.pendingAI.Clear(key); err != nil {
+						log.Printf("清除待重试分析记录失败: %v", err)
+					}
+				}
+			}
+		}
+
+		if a.cfg.Sentiment != nil && a.cfg.Sentiment.Enabled {
+			for _, news := range newNews {
+				if news.SentimentScore != 0 {
+					a.sentiment.Add(news.Source, news.SentimentScore)
+				}
+			}
+		}
+
+		// 降级策略为 drop 时，AI 分析失败的条目（TranslatedContent 为空，
+		// 即上面 pendingAI 判定为失败的同一批）直接丢弃，不再按原文发送
+		if a.cfg.Degradation != nil && a.cfg.Degradation.OnAIFailure == "drop" {
+			kept := newNews[:0]
+			for _, news := range newNews {
+				if news.TranslatedContent == "" {
+					log.Printf("AI 分析失败，降级策略为 drop，丢弃: %s (ID: %s)", news.OriginalTitle, news.ID)
+					continue
+				}
+				kept = append(kept, news)
+			}
+			newNews = kept
+		}
+	}
+
+	// 优先级队列：按重要性排序，只有本轮预算（max_per_cycle）内的条目进入发送阶段，
+	// 其余条目留在队列里等待下一轮，等待越久有效优先级越高，避免持续涌入的高重要性
+	// 条目让低重要性条目永远排不上号；开启此队列后由它的弹出顺序决定投递顺序，
+	// 下面简单的 Ordering 重排不再生效
+	if a.deliveryQueue != nil {
+		a.deliveryQueue.Push(newNews)
+		newNews = a.deliveryQueue.Pop()
+		if len(newNews) == 0 {
+			log.Printf("优先级队列本轮预算内没有可发送的条目，全部留待下一轮")
+			return nil
+		}
+	} else if a.cfg.Ordering != nil && a.cfg.Ordering.Enabled {
+		// 重排投递顺序：默认按数据源返回顺序发送，抓取量大、多个源交替出新时读起来会很跳，
+		// 配置后可按发布时间或重要性重排，让频道阅读体验更连贯
+		sortNewsForDelivery(newNews, a.cfg.Ordering.By)
+	}
+
+	// 发送阶段（含逐条编辑/推送和 webhook）的整体超时
+	sendCtx, cancelSend := stageContext(ctx, timeouts.Send)
+	defer cancelSend()
+
+	// 本轮处理统计，供 cycle_summary 开启时汇总推送
+	sentCount := 0
+	errorCount := 0
+
+	// 处理每条新闻
+	for _, news := range newNews {
+		log.Printf("正在处理新闻: %s", news.OriginalTitle)
+
+		// 跨周期重试补全了分析结果的条目：追加分析结果而不是当作新条目重新发送，
+		// 能编辑原消息时优先编辑，否则单独发一条补充分析的跟帖
+		if retriedAnalysisOK[archive.Key(news.Source, news.ID)] {
+			log.Printf("AI 分析重试成功，追加分析结果: %s (ID: %s)", news.OriginalTitle, news.ID)
+			edited := false
+			if a.archive != nil {
+				if record, ok := a.archive.Get(archive.Key(news.Source, news.ID)); ok && len(record.Messages) > 0 {
+					for chatID, messageID := range record.Messages {
+						if err := a.sender.EditNews(sendCtx, chatID, messageID, news); err != nil {
+							log.Printf("编辑聊天 %s 的消息失败: %v", chatID, err)
+						}
+					}
+					hash := archive.ContentHash(news.OriginalContent, news.TranslatedContent, news.Summary, news.Analysis)
+					if err := a.archive.Update(archive.Key(news.Source, news.ID), archive.Record{ContentHash: hash, Messages: record.Messages, Receipts: record.Receipts, TraceID: news.TraceID, Raw: simulationSnapshot(a.cfg, news)}); err != nil {
+						log.Printf("更新发送记录失败: %v", err)
+					}
+					edited = true
+				}
+			}
+			if !edited {
+				followUp := fmt.Sprintf("🤖 补充分析 - %s\n\n%s", news.OriginalTitle, news.Analysis)
+				if err := a.sender.SendText(sendCtx, nil, followUp); err != nil {
+					log.Printf("追加分析结果失败: %v", err)
+				}
+			}
+			continue
+		}
+
+		// 数据源被临时静音时，只归档去重状态，不做任何投递
+		if a.muter != nil && a.muter.IsMuted(news.Source) {
+			log.Printf("数据源 %s 已被静音，仅归档不投递: %s", news.Source, news.OriginalTitle)
+			if err := a.cache.Set(news.Source, news.ID, true, time.Duration(a.cfg.Cache.TTL)*time.Second); err != nil {
+				log.Printf("更新缓存失败: %v", err)
+			} else if a.journal != nil {
+				if err := a.journal.Clear(journal.Key(news.Source, news.ID)); err != nil {
+					log.Printf("清理处理进度失败: %v", err)
+				}
+			}
+			continue
+		}
+
+		// 重复抑制窗口：多个数据源转载同一条新闻时，窗口内已有来源报道过的故事
+		// 不再单独推送一条新消息，按配置丢弃或编辑此前的消息追加"还有其它来源"提示
+		if a.dupeWindow != nil {
+			fingerprint := dupewindow.Fingerprint(news.OriginalTitle)
+			if record, ok := a.dupeWindow.Get(fingerprint); ok &&
+				time.Since(record.FirstSeen) <= time.Duration(a.cfg.DuplicateWindow.Window)*time.Second {
+				if !dupewindow.HasSource(record, news.Source) {
+					record.Sources = append(record.Sources, news.Source)
+					if a.cfg.DuplicateWindow.Mode == "drop" {
+						log.Printf("命中重复抑制窗口，丢弃: %s (来源: %s)", news.OriginalTitle, news.Source)
+					} else {
+						note := fmt.Sprintf("📡 另有 %d 个来源报道: %s", len(record.Sources), strings.Join(record.Sources, ", "))
+						log.Printf("命中重复抑制窗口，追加来源: %s (来源: %s)", news.OriginalTitle, news.Source)
+						for chatID, messageID := range record.Messages {
+							if err := a.sender.EditNewsWithNote(sendCtx, chatID, messageID, news, note); err != nil {
+								log.Printf("编辑聊天 %s 的消息追加来源失败: %v", chatID, err)
+							}
+						}
+					}
+					if err := a.dupeWindow.Update(fingerprint, record); err != nil {
+						log.Printf("更新重复抑制窗口记录失败: %v", err)
+					}
+				}
+				if err := a.cache.Set(news.Source, news.ID, true, time.Duration(a.cfg.Cache.TTL)*time.Second); err != nil {
+					log.Printf("更新缓存失败: %v", err)
+				} else if a.journal != nil {
+					if err := a.journal.Clear(journal.Key(news.Source, news.ID)); err != nil {
+						log.Printf("清理处理进度失败: %v", err)
+					}
+				}
+				if a.dedupStats != nil {
+					a.dedupStats.Record("fuzzy_title")
+				}
+				continue
+			}
+		}
+
+		// dry-run 模式下只打印处理结果，用于上线前校验配置、过滤规则和消息模板，
+		// 不实际发送，也不更新缓存/游标等持久化状态，保证可重复执行
+		if a.dryRun {
+			fmt.Printf("[dry-run] 来源: %s\n标题: %s\n译文: %s\n分析: %s\n标签: %v\n\n",
+				news.Source, news.OriginalTitle, news.TranslatedTitle, news.Analysis, news.Tags)
+			continue
+		}
+
+		// 发送新闻：若此前已发送过同一条目（来源+ID相同）且这次内容发生了变化，
+		// 编辑各聊天里的原消息而不是重复推送一条新消息
+		archiveKey := archive.Key(news.Source, news.ID)
+		var previousRecord archive.Record
+		hasPreviousRecord := false
+		if a.archive != nil {
+			previousRecord, hasPreviousRecord = a.archive.Get(archiveKey)
+		}
+
+		var receipts map[string]archive.DeliveryReceipt
+		if a.deliveryReceipts {
+			receipts = make(map[string]archive.DeliveryReceipt)
+		}
+
+		messageIDs := previousRecord.Messages
+		sendFailed := false
+		if hasPreviousRecord {
+			for chatID, messageID := range previousRecord.Messages {
+				err := a.sender.EditNews(sendCtx, chatID, messageID, news)
+				if err != nil {
+					log.Printf("编辑聊天 %s 的消息失败: %v", chatID, err)
+				}
+				if receipts != nil {
+					receipts[chatID] = newDeliveryReceipt(messageID, 1, err)
+				}
+			}
+		} else {
+			chatIDs, deliverable := a.sampledChatIDs(news)
+			if !deliverable {
+				log.Printf("低重要性条目未命中任何频道抽样，仅归档不投递: %s (ID: %s)", news.OriginalTitle, news.ID)
+				if err := a.cache.Set(news.Source, news.ID, true, time.Duration(a.cfg.Cache.TTL)*time.Second); err != nil {
+					log.Printf("更新缓存失败: %v", err)
+				} else if a.journal != nil {
+					if err := a.journal.Clear(journal.Key(news.Source, news.ID)); err != nil {
+						log.Printf("清理处理进度失败: %v", err)
+					}
+				}
+				continue
+			}
+
+			// 崩溃安全的处理进度记录：跳过上次崩溃前已经投递成功的聊天，
+			// 避免进程重启后同一条消息在这些聊天里被重复发送一遍。
+			// 注意跳过的聊天不会出现在本轮的 messageIDs 里，此后依赖 archive
+			// 编辑原消息的功能对这些聊天不可用，只影响"内容更新时编辑"这类
+			// 增值功能，不影响不重复投递这个核心目标
+			journalKey := journal.Key(news.Source, news.ID)
+			if a.journal != nil {
+				if record, ok := a.journal.Get(journalKey); ok && len(record.SentChats) > 0 {
+					sent := make(map[string]bool, len(record.SentChats))
+					for _, c := range record.SentChats {
+						sent[c] = true
+					}
+					remaining := make([]string, 0, len(chatIDs))
+					for _, c := range chatIDs {
+						if !sent[c] {
+							remaining = append(remaining, c)
+						}
+					}
+					if len(remaining) < len(chatIDs) {
+						log.Printf("崩溃重启后跳过已投递过的聊天: %s (ID: %s)", news.OriginalTitle, news.ID)
+					}
+					chatIDs = remaining
+				}
+			}
+
+			var results []sender.DeliveryReceipt
+			var err error
+			if len(chatIDs) > 0 {
+				results, err = a.sender.SendNewsToChatsWithReceipts(sendCtx, news, chatIDs)
+			}
+			ids := make(map[string]int64, len(results))
+			for _, r := range results {
+				if r.Err == nil {
+					ids[r.ChatID] = r.MessageID
+				}
+				if receipts != nil {
+					receipts[r.ChatID] = newDeliveryReceipt(r.MessageID, r.Attempts, r.Err)
+				}
+			}
+			messageIDs = ids
+			if a.journal != nil {
+				succeeded := make([]string, 0, len(results))
+				for _, r := range results {
+					if r.Err == nil {
+						succeeded = append(succeeded, r.ChatID)
+					}
+				}
+				if jErr := a.journal.MarkChatsSent(journalKey, succeeded); jErr != nil {
+					log.Printf("记录处理进度失败: %v", jErr)
+				}
+			}
+			if err != nil {
+				log.Printf("发送新闻失败: %v", err)
+				sendFailed = true
+
+				var escalationPath []string
+				if a.escalator != nil && a.escalator.Critical(math.Abs(news.SentimentScore)) {
+					escalationPath = a.escalateFailedNews(sendCtx, news)
+				}
+
+				if a.deadLetter != nil {
+					failedChats := make([]string, 0, len(results))
+					for _, r := range results {
+						if r.Err != nil {
+							failedChats = append(failedChats, r.ChatID)
+						}
+					}
+					dlKey := deadletter.Key(news.Source, news.ID)
+					if dlErr := a.deadLetter.Add(dlKey, deadletter.Item{
+						News:           news,
+						ChatIDs:        failedChats,
+						Error:          err.Error(),
+						FailedAt:       time.Now(),
+						EscalationPath: escalationPath,
+					}); dlErr != nil {
+						log.Printf("写入死信队列失败: %v", dlErr)
+					}
+				}
+			}
+		}
+
+		if a.archive != nil {
+			hash := archive.ContentHash(news.OriginalContent, news.TranslatedContent, news.Summary, news.Analysis)
+			if err := a.archive.Update(archiveKey, archive.Record{ContentHash: hash, Messages: messageIDs, Receipts: receipts, TraceID: news.TraceID, Raw: simulationSnapshot(a.cfg, news)}); err != nil {
+				log.Printf("更新发送记录失败: %v", err)
+			}
+		}
+
+		if sendFailed {
+			errorCount++
+			continue
+		}
+		sentCount++
+
+		// 开启了重复抑制窗口时，记录本次发送作为该故事的首次发送，供窗口内
+		// 后续来源转载同一条新闻时识别并追加，而不是重复推送一条新消息
+		if a.dupeWindow != nil {
+			fingerprint := dupewindow.Fingerprint(news.OriginalTitle)
+			if err := a.dupeWindow.Update(fingerprint, dupewindow.Record{
+				FirstSeen: time.Now(),
+				Sources:   []string{news.Source},
+				Messages:  messageIDs,
+			}); err != nil {
+				log.Printf("记录重复抑制窗口失败: %v", err)
+			}
+		}
+
+		// 记录到最近发送列表，供 /latest 命令查询
+		a.recentMu.Lock()
+		a.recentSent = append(a.recentSent, news)
+		if len(a.recentSent) > maxRecentSent {
+			a.recentSent = a.recentSent[len(a.recentSent)-maxRecentSent:]
+		}
+		a.recentMu.Unlock()
+
+		// 命中关注名单且配置了专属聊天时，额外单独推送一份
+		if len(news.Tags) > 0 && a.cfg.Watchlist != nil && a.cfg.Watchlist.ChatID != "" {
+			if _, err := a.sender.SendNewsToChats(sendCtx, news, []string{a.cfg.Watchlist.ChatID}); err != nil {
+				log.Printf("推送到关注名单专属聊天失败: %v", err)
+			}
+		}
+
+		// 命中用户个人关键词订阅时，额外私信给对应用户（Telegram 私聊的 chat ID 等于用户ID）；
+		// 开启了已读状态跟踪时附带一个"标记已读"按钮，并记入该用户的未读列表供 /unread 查询
+		if a.userWatch != nil {
+			for _, userID := range a.userWatch.Match(news.OriginalTitle, news.OriginalContent) {
+				chatID := strconv.FormatInt(userID, 10)
+				markup := a.readAckMarkup(userID, news.OriginalTitle)
+				if _, err := a.sender.SendNewsToChats(sendCtx, news, []string{chatID}, markup); err != nil {
+					log.Printf("私信推送给订阅用户 %d 失败: %v", userID, err)
+				}
+			}
+		}
+
+		// 累积到早间简报缓冲区，供每日固定时刻汇总推送
+		if a.cfg.Briefing != nil && a.cfg.Briefing.Enabled {
+			a.briefingMu.Lock()
+			a.briefingItems = append(a.briefingItems, news)
+			a.briefingMu.Unlock()
+		}
+
+		// 按类目路由到对应的摘要计划：汇总周期为 0 的计划命中后立即单独推送一条摘要，
+		// 其余计划仅累积，等待各自的定时任务批量汇总
+		if a.digestRouter != nil {
+			if schedule := a.digestRouter.Route(news); schedule != nil && schedule.Config.IntervalSeconds <= 0 {
+				a.postDigest(sendCtx, schedule)
+			}
+		}
+
+		// 推送到 Webhook
+		if err := a.webhook.SendNews(sendCtx, news); err != nil {
+			log.Printf("Webhook 推送失败: %v", err)
+		}
+
+		// 只有在成功发送后才更新缓存
+		if err := a.cache.Set(news.Source, news.ID, true, time.Duration(a.cfg.Cache.TTL)*time.Second); err != nil {
+			log.Printf("更新缓存失败: %v", err)
+		} else {
+			log.Printf("成功缓存新闻: %s (ID: %s), TTL: %d秒", news.OriginalTitle, news.ID, a.cfg.Cache.TTL)
+			// 整条流水线已经走完（已抓取、已分析、已投递、已缓存），
+			// 清理处理进度记录，避免文件无限增长
+			if a.journal != nil {
+				if err := a.journal.Clear(journal.Key(news.Source, news.ID)); err != nil {
+					log.Printf("清理处理进度失败: %v", err)
+				}
+			}
+		}
+
+		log.Printf("成功处理新闻: %s", news.OriginalTitle)
+	}
+
+	a.postCycleSummary(sendCtx, cycleStats{
+		Fetched:  len(newsList),
+		New:      len(newNews),
+		Sent:     sentCount,
+		Filtered: len(newsList) - len(newNews),
+		Errors:   errorCount,
+	})
+
+	return nil
+}
+
+// runDeadLetterCommand 处理 `news-fetcher deadletter list|retry <source> <id>|purge`
+// 子命令，用于在服务器上直接排查投递失败的条目并在问题修复后手动重新投递，
+// 需要在配置文件中开启 dead_letter.enabled 才有数据
+// simulationSnapshot 在开启了 simulation.enabled 时返回该条新闻当前的完整快照
+// （含翻译/AI分析结果）的一份拷贝，供 archive 持久化；`news-fetcher simulate`
+// 命令据此用当前配置的过滤规则重放历史流量。未开启时返回 nil，不占用额外磁盘
+func simulationSnapshot(cfg *config.Config, news *models.News) *models.News {
+	if cfg.Simulation == nil || !cfg.Simulation.Enabled {
+		return nil
+	}
+	snapshot := *news
+	return &snapshot
+}
+
+// collectSourceConfigs 把 sources 下 api/rss/mock/hackernews/custom 各列表按 URL
+// 汇总成一份映射，供 simulate 按新闻的 Source 字段找回对应的质量门槛等配置
+func collectSourceConfigs(cfg *config.SourcesConfig) map[string]*config.SourceConfig {
+	byURL := make(map[string]*config.SourceConfig)
+	if cfg == nil {
+		return byURL
+	}
+	for _, list := range [][]*config.SourceConfig{cfg.API, cfg.RSS, cfg.Mock, cfg.HackerNews, cfg.Custom} {
+		for _, sc := range list {
+			byURL[sc.URL] = sc
+		}
+	}
+	return byURL
+}
+
+// parseSinceDuration 解析 --since 参数，在标准 time.ParseDuration 支持的单位
+// （h/m/s 等）之外额外支持 "d" 天后缀，因为调参场景常以"最近 7 天"表述窗口，
+// 而 Go 标准库出于夏令时等历时不固定的原因没有内置天这个单位
+func parseSinceDuration(s string) (time.Duration, error) {
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("非法的天数: %s", s)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// runSimulateCommand 用当前配置的过滤规则重放归档里保存的历史原始快照，报告
+// 哪些条目会被丢弃、会投递到哪些聊天，但不会真的发送。为了避免每次调参重放都
+// 产生真实的 API 调用和费用，这里不会重新调用 AI 分析——只重放确定性的过滤链路
+// （数据源静音状态、质量门槛）和顶层默认聊天路由，不覆盖 watchlist/重复抑制窗口/
+// 升级链等有状态或需要实时上下文的路由逻辑
+func runSimulateCommand(args []string) {
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	since := fs.String("since", "24h", `重放最近多长时间归档的历史条目，支持 Go duration 格式外加 "d" 天后缀，如 "7d"`)
+	fs.Parse(args)
+
+	window, err := parseSinceDuration(*since)
+	if err != nil {
+		log.Fatalf("解析 --since 失败: %v", err)
+	}
+
+	cfg, err := config.LoadConfig("config/config.yaml")
+	if err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
+	if cfg.Simulation == nil || !cfg.Simulation.Enabled {
+		log.Fatalf("未开启 simulation.enabled，历史归档不含用于重放的原始快照；开启后需要运行一段时间累积新的归档记录才能重放")
+	}
+
+	app, err := NewApp(cfg, "", nil)
+	if err != nil {
+		log.Fatalf("初始化应用失败: %v", err)
+	}
+	if app.archive == nil {
+		log.Fatalf("未开启 edit_on_update 或 delivery_receipts，没有可重放的归档文件")
+	}
+
+	sourceCfgs := collectSourceConfigs(cfg.Sources)
+	cutoff := time.Now().Add(-window)
+
+	keys := make([]string, 0)
+	records := app.archive.All()
+	for key := range records {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var total, keptByGate, delivered int
+	for _, key := range keys {
+		record := records[key]
+		if record.Raw == nil || record.ArchivedAt.Before(cutoff) {
+			continue
+		}
+		total++
+		news := record.Raw
+
+		if app.muter != nil && app.muter.IsMuted(news.Source) {
+			fmt.Printf("[simulate] %s\t丢弃（数据源已静音）\n", key)
+			continue
+		}
+
+		if srcCfg, ok := sourceCfgs[news.Source]; ok && srcCfg.QualityGate != nil && srcCfg.QualityGate.Enabled {
+			gate := srcCfg.QualityGate
+			if gate.RequireLink && news.Link == "" {
+				fmt.Printf("[simulate] %s\t丢弃（质量门槛：缺少链接）\n", key)
+				continue
+			}
+			if gate.MinContentLength > 0 && len([]rune(strings.TrimSpace(news.OriginalContent))) < gate.MinContentLength {
+				fmt.Printf("[simulate] %s\t丢弃（质量门槛：正文过短）\n", key)
+				continue
+			}
+		}
+		keptByGate++
+
+		chatIDs := cfg.Telegram.Bot.ChatIDs
+		delivered++
+		fmt.Printf("[simulate] %s\t投递到 %v\t标题: %s\n", key, chatIDs, news.OriginalTitle)
+	}
+
+	fmt.Printf("\n共 %d 条归档记录落在 %s 窗口内，%d 条通过质量门槛/静音过滤，%d 条会被投递\n", total, *since, keptByGate, delivered)
+}
+
+func runDeadLetterCommand(args []string) {
+	if len(args) == 0 {
+		log.Fatalf("用法: news-fetcher deadletter list|retry <source> <id>|purge")
+	}
+
+	cfg, err := config.LoadConfig("config/config.yaml")
+	if err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
+	if cfg.DeadLetter == nil || !cfg.DeadLetter.Enabled {
+		log.Fatalf("未开启死信队列，请先在配置文件中启用 dead_letter.enabled")
+	}
+
+	app, err := NewApp(cfg, "", nil)
+	if err != nil {
+		log.Fatalf("初始化应用失败: %v", err)
+	}
+
+	switch args[0] {
+	case "list":
+		items := app.deadLetter.List()
+		if len(items) == 0 {
+			fmt.Println("死信队列为空")
+			return
+		}
+		keys := make([]string, 0, len(items))
+		for k := range items {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			item := items[k]
+			fmt.Printf("%s\t失败于 %s\t失败聊天 %v\t错误: %s\n",
+				k, item.FailedAt.Format("2006-01-02 15:04:05"), item.ChatIDs, item.Error)
+		}
+	case "retry":
+		if len(args) != 3 {
+			log.Fatalf("用法: news-fetcher deadletter retry <source> <id>")
+		}
+		if err := app.retryDeadLetter(context.Background(), deadletter.Key(args[1], args[2])); err != nil {
+			log.Fatalf("重新投递失败: %v", err)
+		}
+		fmt.Println("重新投递成功")
+	case "purge":
+		if err := app.deadLetter.Purge(); err != nil {
+			log.Fatalf("清空死信队列失败: %v", err)
+		}
+		fmt.Println("已清空死信队列")
+	default:
+		log.Fatalf("未知子命令: %s，用法: news-fetcher deadletter list|retry <source> <id>|purge", args[0])
+	}
+}
+
+func main() {
+	// deadletter 是一个独立的运维子命令，参数形如 `news-fetcher deadletter list`，
+	// 必须在 flag.Parse() 之前拦截，否则 flag 包会把子命令和其参数当作未知选项报错
+	if len(os.Args) > 1 && os.Args[1] == "deadletter" {
+		runDeadLetterCommand(os.Args[2:])
+		return
+	}
+
+	// simulate 用当前配置的过滤规则重放归档的历史流量，形如
+	// `news-fetcher simulate --since 7d`，同样必须在 flag.Parse() 之前拦截
+	if len(os.Args) > 1 && os.Args[1] == "simulate" {
+		runSimulateCommand(os.Args[2:])
+		return
+	}
+
+	// service 是安装/卸载/启停系统服务的运维子命令，形如 `news-fetcher service install`，
+	// 同样必须在 flag.Parse() 之前拦截
+	if len(os.Args) > 1 && os.Args[1] == "service" {
+		runServiceCommand(os.Args[2:])
+		return
+	}
+
+	// config 是导出配置 Schema 等配置相关的运维子命令，形如 `news-fetcher config schema`，
+	// 同样必须在 flag.Parse() 之前拦截
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		runConfigCommand(os.Args[2:])
+		return
+	}
+
+	// snapshot 是打包/还原全部运行时状态的运维子命令，形如
+	// `news-fetcher snapshot create ./backup.tar.gz`，同样必须在 flag.Parse() 之前拦截
+	if len(os.Args) > 1 && os.Args[1] == "snapshot" {
+		runSnapshotCommand(os.Args[2:])
+		return
+	}
+
+	// version 打印当前二进制版本号，同时也是 update 子命令替换二进制后用来验证新
+	// 二进制能正常启动的探测手段，同样必须在 flag.Parse() 之前拦截
+	if len(os.Args) > 1 && os.Args[1] == "version" {
+		fmt.Println(appVersion)
+		return
+	}
+
+	// update 检查 GitHub release 并在有新版本时自我更新，形如 `news-fetcher update`，
+	// 同样必须在 flag.Parse() 之前拦截
+	if len(os.Args) > 1 && os.Args[1] == "update" {
+		runUpdateCommand(os.Args[2:])
+		return
+	}
+
+	// 已注册为系统服务并由 Windows 服务管理器/launchd 拉起时，进程是非交互式启动的，
+	// 此时把控制权交给系统服务生命周期（Start/Stop 回调），不再走下面的前台命令行流程
+	if !service.Interactive() {
+		runServiceHost()
+		return
+	}
+
+	recordDir := flag.String("record", "", "抓取一轮后把结果按数据源录制为固定文件到该目录，用于配合 mock 数据源离线联调，录制完成后退出")
+	dryRun := flag.Bool("dry-run", false, "只执行一轮抓取与处理流程并打印结果，不实际发送到 Telegram/Webhook，用于上线前校验配置、过滤规则和消息模板")
+	flag.Parse()
+
+	// 设置日志格式
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+	log.Println("启动新闻抓取服务...")
+
+	// 创建数据目录
+	dataDir := filepath.Join(os.Getenv("HOME"), ".news-fetcher")
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		log.Fatalf("创建数据目录失败: %v", err)
+	}
+	log.Printf("数据目录: %s", dataDir)
+
+	// 获取进程锁，防止同一份数据目录被多个实例同时使用而互相踩到缓存/游标等
+	// 持久化文件，导致重复推送或数据错乱
+	lock, err := pidlock.Acquire(filepath.Join(dataDir, "news-fetcher.pid"))
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	defer lock.Release()
+
+	// 加载配置
+	cfg, err := config.LoadConfig("config/config.yaml")
+	if err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
+
+	ctx := context.Background()
+
+	if *recordDir != "" {
+		app, err := NewApp(cfg, "", nil)
+		if err != nil {
+			log.Fatalf("初始化应用失败: %v", err)
+		}
+		newsList, err := app.fetcher.Fetch(ctx)
+		if err != nil {
+			log.Fatalf("抓取失败: %v", err)
+		}
+		if err := fetcher.DumpFixtures(newsList, *recordDir); err != nil {
+			log.Fatalf("录制固定文件失败: %v", err)
+		}
+		return
+	}
+
+	if *dryRun {
+		app, err := NewApp(cfg, "", nil)
+		if err != nil {
+			log.Fatalf("初始化应用失败: %v", err)
+		}
+		app.dryRun = true
+		if err := app.processNews(ctx); err != nil {
+			log.Fatalf("dry-run 处理失败: %v", err)
+		}
+		return
+	}
+
+	if err := runApp(ctx, cfg); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
+
+// runApp 按配置以前台方式运行应用直到收到终止信号：配置了 profiles 时为每个
+// profile 并发运行一个完全独立的 App，否则运行单个默认实例。main() 的默认
+// 运行路径和系统服务模式（cmd/service.go 中的 serviceProgram）共用这份启动逻辑，
+// 避免两处各写一份
+func runApp(ctx context.Context, cfg *config.Config) error {
+	if len(cfg.Profiles) > 0 {
+		// 携带相同 shared_dedup_key 的 profile 复用同一个 cache.Cache 实例，
+		// 使它们共享去重命名空间、不会重复分析同一条新闻，仍各自独立投递
+		sharedCaches := make(map[string]*cache.Cache)
+		var wg sync.WaitGroup
+		for _, p := range cfg.Profiles {
+			p := p
+			var sharedCache *cache.Cache
+			if p.SharedDedupKey != "" {
+				sc, ok := sharedCaches[p.SharedDedupKey]
+				if !ok {
+					var err error
+					cacheFile := filepath.Join(os.Getenv("HOME"), ".news-fetcher", fmt.Sprintf("news_cache_shared_%s.json", p.SharedDedupKey))
+					sc, err = cache.NewCache(cacheFile)
+					if err != nil {
+						return fmt.Errorf("初始化 profile %s 的共享去重缓存失败: %v", p.Name, err)
+					}
+					sharedCaches[p.SharedDedupKey] = sc
+				}
+				sharedCache = sc
+			}
+			app, err := NewApp(p.ToConfig(), p.Name, sharedCache)
+			if err != nil {
+				return fmt.Errorf("初始化 profile %s 失败: %v", p.Name, err)
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := app.Run(ctx); err != nil {
+					log.Printf("profile %s 运行失败: %v", p.Name, err)
+				}
+			}()
+		}
+		wg.Wait()
+		return nil
+	}
+
+	app, err := NewApp(cfg, "", nil)
+	if err != nil {
+		return fmt.Errorf("初始化应用失败: %v", err)
 	}
+	return app.Run(ctx)
 }
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/amylixing/news-fetcher/internal/config"
+)
+
+// runConfigCommand 处理 `news-fetcher config schema` 子命令，用于导出描述
+// config.yaml 完整结构的 JSON Schema，配合 YAML 编辑器（如 VS Code 的 YAML
+// 插件）实现字段校验和自动补全，随配置项增多这份说明比手写文档更不容易过时
+func runConfigCommand(args []string) {
+	if len(args) != 1 || args[0] != "schema" {
+		log.Fatalf("用法: news-fetcher config schema")
+	}
+
+	data, err := json.MarshalIndent(config.Schema(), "", "  ")
+	if err != nil {
+		log.Fatalf("生成配置 Schema 失败: %v", err)
+	}
+	fmt.Println(string(data))
+}
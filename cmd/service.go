@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/amylixing/news-fetcher/internal/config"
+	"github.com/kardianos/service"
+)
+
+// serviceName、serviceDisplayName、serviceDescription 用于向系统服务管理器
+// （Windows 服务控制管理器 / macOS launchd / Linux systemd）注册本程序
+const (
+	serviceName        = "news-fetcher"
+	serviceDisplayName = "News Fetcher"
+	serviceDescription = "抓取多数据源新闻并推送到 Telegram/Webhook 的后台服务"
+)
+
+// newServiceConfig 构造统一的系统服务描述，install/uninstall/start/stop 与
+// 实际作为服务运行时都必须用同一份配置创建 service.Service，否则可能操作到
+// 不同的服务注册项
+func newServiceConfig() *service.Config {
+	return &service.Config{
+		Name:        serviceName,
+		DisplayName: serviceDisplayName,
+		Description: serviceDescription,
+	}
+}
+
+// runServiceCommand 处理 `news-fetcher service install|uninstall|start|stop|restart`
+// 子命令，用于把本程序注册为原生系统服务（Windows 服务 / launchd agent /
+// systemd unit，由 kardianos/service 按运行平台自动选择），实现开机自启和后台常驻
+func runServiceCommand(args []string) {
+	if len(args) != 1 {
+		log.Fatalf("用法: news-fetcher service install|uninstall|start|stop|restart")
+	}
+
+	svc, err := service.New(&serviceProgram{}, newServiceConfig())
+	if err != nil {
+		log.Fatalf("创建系统服务失败: %v", err)
+	}
+
+	action := args[0]
+	switch action {
+	case "install", "uninstall", "start", "stop", "restart":
+		if err := service.Control(svc, action); err != nil {
+			log.Fatalf("执行服务操作 %s 失败: %v", action, err)
+		}
+		log.Printf("服务操作 %s 执行成功", action)
+	default:
+		log.Fatalf("未知子命令: %s，用法: news-fetcher service install|uninstall|start|stop|restart", action)
+	}
+}
+
+// runServiceHost 是被系统服务管理器非交互式拉起时的入口：把控制权交给
+// service.Service，由它在合适的时机回调 serviceProgram 的 Start/Stop
+func runServiceHost() {
+	svc, err := service.New(&serviceProgram{}, newServiceConfig())
+	if err != nil {
+		log.Fatalf("创建系统服务失败: %v", err)
+	}
+	if err := svc.Run(); err != nil {
+		log.Fatalf("系统服务运行失败: %v", err)
+	}
+}
+
+// serviceProgram 实现 service.Interface，把 runApp 的前台运行逻辑适配成
+// 系统服务要求的 Start（必须立即返回，实际工作放到 goroutine 里）/Stop 回调
+type serviceProgram struct {
+	cancel context.CancelFunc
+}
+
+// Start 由服务管理器在启动服务时调用，必须尽快返回，真正的抓取循环放到
+// 后台 goroutine 里跑
+func (p *serviceProgram) Start(s service.Service) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+
+	go func() {
+		cfg, err := config.LoadConfig("config/config.yaml")
+		if err != nil {
+			log.Fatalf("加载配置失败: %v", err)
+		}
+		if err := runApp(ctx, cfg); err != nil {
+			log.Printf("服务运行失败: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Stop 由服务管理器在停止/卸载服务时调用：取消 ctx 让抓取循环停止调度新的
+// 一轮处理；runApp 内部仍会等待正在进行的处理收尾，服务管理器一般会给予
+// 有限的关闭宽限期，超时未退出的进程会被强制结束
+func (p *serviceProgram) Stop(s service.Service) error {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// runSnapshotCommand 处理 `news-fetcher snapshot create|restore <文件路径>` 子命令，
+// 把数据目录下的缓存、archive、抓取游标、数据源状态等全部运行时状态打包成一个
+// tar.gz，用于换机迁移或升级前备份：一次性还原比逐个文件搬运更不容易漏掉某个
+// 状态文件而导致迁移后重复推送（去重缓存丢失）或漏发（游标状态丢失）
+func runSnapshotCommand(args []string) {
+	if len(args) != 2 {
+		log.Fatalf("用法: news-fetcher snapshot create|restore <文件路径>")
+	}
+
+	dataDir := filepath.Join(os.Getenv("HOME"), ".news-fetcher")
+
+	switch args[0] {
+	case "create":
+		if err := createSnapshot(dataDir, args[1]); err != nil {
+			log.Fatalf("创建快照失败: %v", err)
+		}
+		fmt.Printf("已创建快照: %s\n", args[1])
+	case "restore":
+		if err := restoreSnapshot(dataDir, args[1]); err != nil {
+			log.Fatalf("恢复快照失败: %v", err)
+		}
+		fmt.Println("快照已恢复，请确认 news-fetcher 未在运行后再启动，避免运行中的进程用旧状态覆盖刚恢复的文件")
+	default:
+		log.Fatalf("未知子命令: %s，用法: news-fetcher snapshot create|restore <文件路径>", args[0])
+	}
+}
+
+// snapshotSkipFiles 是数据目录下不属于运行时状态、快照时应跳过的文件：
+// news-fetcher.pid 只是当前进程的运行期互斥锁，打包或还原它没有意义，
+// 还原时甚至可能让新进程误以为旧实例仍在运行
+var snapshotSkipFiles = map[string]bool{
+	"news-fetcher.pid": true,
+}
+
+// createSnapshot 把 dataDir 下的全部状态文件打包写入 destPath（tar.gz）
+func createSnapshot(dataDir, destPath string) error {
+	entries, err := os.ReadDir(dataDir)
+	if err != nil {
+		return fmt.Errorf("读取数据目录失败: %v", err)
+	}
+
+	out, err := os.Create(destPath)
+	if err != nil {
+		return fmt.Errorf("创建快照文件失败: %v", err)
+	}
+	defer out.Close()
+
+	gw := gzip.NewWriter(out)
+	defer gw.Close()
+	tw := tar.NewWriter(gw)
+	defer tw.Close()
+
+	for _, entry := range entries {
+		if entry.IsDir() || snapshotSkipFiles[entry.Name()] {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return fmt.Errorf("读取文件信息失败: %v", err)
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return fmt.Errorf("构造归档头失败: %v", err)
+		}
+		header.Name = entry.Name()
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("写入归档头失败: %v", err)
+		}
+
+		data, err := os.ReadFile(filepath.Join(dataDir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("读取状态文件 %s 失败: %v", entry.Name(), err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return fmt.Errorf("写入状态文件 %s 失败: %v", entry.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// restoreSnapshot 把 srcPath（tar.gz）解压覆盖到 dataDir，已存在的同名文件直接覆盖
+func restoreSnapshot(dataDir, srcPath string) error {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return fmt.Errorf("创建数据目录失败: %v", err)
+	}
+
+	f, err := os.Open(srcPath)
+	if err != nil {
+		return fmt.Errorf("打开快照文件失败: %v", err)
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("解压快照文件失败: %v", err)
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("读取归档条目失败: %v", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		// 归档条目名不应包含路径分隔符，防止恶意或损坏的快照文件写到 dataDir 之外
+		name := filepath.Base(header.Name)
+		if name != header.Name || strings.Contains(header.Name, "..") {
+			return fmt.Errorf("快照中包含非法文件名: %s", header.Name)
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return fmt.Errorf("读取归档内容 %s 失败: %v", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dataDir, name), data, 0644); err != nil {
+			return fmt.Errorf("写入状态文件 %s 失败: %v", name, err)
+		}
+	}
+
+	return nil
+}
@@ -18,7 +18,7 @@ func main() {
 	}
 
 	// 创建发送器
-	s, err := sender.NewSender(cfg.Telegram, nil)
+	s, err := sender.NewSender(cfg.Telegram, nil, cfg.HTTPClient)
 	if err != nil {
 		log.Fatalf("创建发送器失败: %v", err)
 	}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// updateRepo 是发布二进制的 GitHub 仓库，与 go.mod 中的模块路径保持一致
+const updateRepo = "amylixing/news-fetcher"
+
+// updateAssetPrefix 是发布资产的文件名前缀，实际资产名形如
+// "news-fetcher_linux_amd64"，与 checksums.txt 里的记录一一对应
+const updateAssetPrefix = "news-fetcher"
+
+// githubRelease 只解析我们关心的字段，GitHub API 返回的其余字段忽略
+type githubRelease struct {
+	TagName string        `json:"tag_name"`
+	Assets  []githubAsset `json:"assets"`
+}
+
+type githubAsset struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+// runUpdateCommand 处理 `news-fetcher update [--check]` 子命令：查询 GitHub 最新
+// release，比对版本号，必要时下载对应平台的二进制、校验 checksum 后原地替换，
+// 替换后先探测新二进制能否正常启动，探测失败则回滚到替换前的旧二进制
+func runUpdateCommand(args []string) {
+	checkOnly := false
+	for _, arg := range args {
+		if arg == "--check" {
+			checkOnly = true
+		}
+	}
+
+	release, err := fetchLatestRelease(updateRepo)
+	if err != nil {
+		log.Fatalf("查询最新版本失败: %v", err)
+	}
+
+	latest := strings.TrimPrefix(release.TagName, "v")
+	current := strings.TrimPrefix(appVersion, "v")
+	if current == latest {
+		fmt.Printf("当前已是最新版本: %s\n", appVersion)
+		return
+	}
+
+	fmt.Printf("发现新版本: %s -> %s\n", appVersion, release.TagName)
+	if checkOnly {
+		return
+	}
+
+	assetName := fmt.Sprintf("%s_%s_%s", updateAssetPrefix, runtime.GOOS, runtime.GOARCH)
+	assetURL := findAssetURL(release, assetName)
+	if assetURL == "" {
+		log.Fatalf("release %s 中未找到当前平台（%s/%s）对应的资产 %s", release.TagName, runtime.GOOS, runtime.GOARCH, assetName)
+	}
+	checksumsURL := findAssetURL(release, "checksums.txt")
+	if checksumsURL == "" {
+		log.Fatalf("release %s 中缺少 checksums.txt，拒绝在无法校验完整性的情况下自我更新", release.TagName)
+	}
+
+	newBinary, err := downloadBytes(assetURL)
+	if err != nil {
+		log.Fatalf("下载新版本二进制失败: %v", err)
+	}
+	checksums, err := downloadBytes(checksumsURL)
+	if err != nil {
+		log.Fatalf("下载 checksums.txt 失败: %v", err)
+	}
+	if err := verifyChecksum(newBinary, checksums, assetName); err != nil {
+		log.Fatalf("校验新版本二进制失败: %v", err)
+	}
+
+	execPath, err := os.Executable()
+	if err != nil {
+		log.Fatalf("定位当前二进制路径失败: %v", err)
+	}
+	if err := replaceBinary(execPath, newBinary); err != nil {
+		log.Fatalf("替换二进制失败: %v", err)
+	}
+
+	fmt.Printf("已更新到 %s\n", release.TagName)
+}
+
+// fetchLatestRelease 查询 repo（形如 "owner/name"）在 GitHub 上的最新 release
+func fetchLatestRelease(repo string) (*githubRelease, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/releases/latest", repo)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求 GitHub API 失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub API 响应状态码异常: %d", resp.StatusCode)
+	}
+
+	var release githubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("解析 GitHub API 响应失败: %v", err)
+	}
+	return &release, nil
+}
+
+// findAssetURL 在 release 的资产列表中按文件名精确匹配，找不到返回空字符串
+func findAssetURL(release *githubRelease, name string) string {
+	for _, asset := range release.Assets {
+		if asset.Name == name {
+			return asset.BrowserDownloadURL
+		}
+	}
+	return ""
+}
+
+// downloadBytes 下载 url 指向的内容并整体读入内存，发布资产体积不大，无需流式处理
+func downloadBytes(url string) ([]byte, error) {
+	client := &http.Client{Timeout: 2 * time.Minute}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("发送请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("响应状态码异常: %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %v", err)
+	}
+	return data, nil
+}
+
+// verifyChecksum 在 checksums 文本（形如 goreleaser 生成的 "<sha256>  <文件名>" 逐行格式）
+// 中找到 assetName 对应的记录，并核对 data 的 sha256 是否一致；
+//
+// 只做 checksum 校验，不做签名验证——签名验证需要项目维护者事先发布并分发一份
+// 公钥供本工具内置或用户手动信任，目前发布流程里还没有这一环，等 CI 里加上 GPG/
+// minisign 签名后再补上，避免在没有可信公钥的情况下伪造出"已验证签名"的假象
+func verifyChecksum(data, checksums []byte, assetName string) error {
+	scanner := bufio.NewScanner(strings.NewReader(string(checksums)))
+	var want string
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		if fields[1] == assetName || strings.TrimPrefix(fields[1], "*") == assetName {
+			want = fields[0]
+			break
+		}
+	}
+	if want == "" {
+		return fmt.Errorf("checksums.txt 中未找到 %s 的记录", assetName)
+	}
+
+	sum := sha256.Sum256(data)
+	got := hex.EncodeToString(sum[:])
+	if !strings.EqualFold(got, want) {
+		return fmt.Errorf("sha256 不匹配，期望 %s，实际 %s", want, got)
+	}
+	return nil
+}
+
+// replaceBinary 把 newBinary 写到 execPath 所在目录的临时文件，冒烟测试通过后
+// 原地替换当前二进制；替换前把旧二进制备份到同目录的 .bak 文件，冒烟测试失败
+// 或替换过程出错时用备份回滚，避免把线上进程换成一个起不来的坏二进制
+func replaceBinary(execPath string, newBinary []byte) error {
+	dir := filepath.Dir(execPath)
+	tmpPath := filepath.Join(dir, ".news-fetcher.update.tmp")
+	backupPath := execPath + ".bak"
+
+	if err := os.WriteFile(tmpPath, newBinary, 0755); err != nil {
+		return fmt.Errorf("写入临时文件失败: %v", err)
+	}
+	defer os.Remove(tmpPath)
+
+	if err := smokeTestBinary(tmpPath); err != nil {
+		return fmt.Errorf("新二进制无法正常启动，已放弃更新: %v", err)
+	}
+
+	if err := os.Rename(execPath, backupPath); err != nil {
+		return fmt.Errorf("备份旧二进制失败: %v", err)
+	}
+	if err := os.Rename(tmpPath, execPath); err != nil {
+		// 尽力回滚，回滚也失败的话把两个错误都报出来，避免用户以为还在用旧版本
+		if rollbackErr := os.Rename(backupPath, execPath); rollbackErr != nil {
+			return fmt.Errorf("落地新二进制失败: %v；回滚旧二进制也失败: %v", err, rollbackErr)
+		}
+		return fmt.Errorf("落地新二进制失败，已回滚到旧版本: %v", err)
+	}
+
+	os.Remove(backupPath)
+	return nil
+}
+
+// smokeTestBinary 通过运行 `<path> version` 验证新二进制至少能正常启动并退出，
+// 而不是一个损坏或不匹配当前系统的可执行文件
+func smokeTestBinary(path string) error {
+	cmd := exec.Command(path, "version")
+	if err := cmd.Run(); err != nil {
+		return err
+	}
+	return nil
+}
@@ -4,41 +4,47 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"net/http"
-	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/amylixing/news-fetcher/internal/budget"
 	"github.com/amylixing/news-fetcher/internal/config"
+	"github.com/amylixing/news-fetcher/internal/eventhook"
+	"github.com/amylixing/news-fetcher/internal/httpclient"
 	"github.com/amylixing/news-fetcher/internal/models"
 	"github.com/sashabaranov/go-openai"
 )
 
+// sentimentPattern 匹配分析结果末尾的"情绪分数: <浮点数>"行，用于提取供情绪指数使用的数值分数
+var sentimentPattern = regexp.MustCompile(`情绪分数[:：]\s*(-?[0-9]+(?:\.[0-9]+)?)`)
+
+// budgetKey 是 Analyzer 在 budget.Tracker 中的计数键
+const budgetKey = "ai"
+
 type Analyzer struct {
 	cfg    *config.AIConfig
 	client *openai.Client
+	budget *budget.Tracker
 }
 
-func NewAnalyzer(cfg *config.AIConfig) (*Analyzer, error) {
+// NewAnalyzer 创建AI分析器，poolCfg 为空时使用共享的默认连接池参数
+func NewAnalyzer(cfg *config.AIConfig, poolCfg *config.HTTPClientConfig) (*Analyzer, error) {
 	if !cfg.Enabled {
 		return &Analyzer{cfg: cfg}, nil
 	}
 
 	log.Printf("正在初始化AI分析器，使用模型: %s", cfg.Model)
 
-	// 配置HTTP代理
-	proxyURL, err := url.Parse("http://127.0.0.1:7890")
-	if err != nil {
-		return nil, fmt.Errorf("解析代理URL失败: %v", err)
-	}
-
 	// 创建HTTP客户端
-	httpClient := &http.Client{
-		Transport: &http.Transport{
-			Proxy:               http.ProxyURL(proxyURL),
-			MaxIdleConns:        100,
-			MaxIdleConnsPerHost: 100,
-		},
-		Timeout: time.Duration(cfg.Timeout) * time.Second,
+	httpClient, err := httpclient.New(httpclient.Options{
+		ProxyURL: "http://127.0.0.1:7890",
+		Timeout:  time.Duration(cfg.Timeout) * time.Second,
+		Pool:     httpclient.PoolFromConfig(poolCfg),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP客户端失败: %v", err)
 	}
 
 	// 初始化OpenAI客户端
@@ -50,15 +56,30 @@ func NewAnalyzer(cfg *config.AIConfig) (*Analyzer, error) {
 	return &Analyzer{
 		cfg:    cfg,
 		client: client,
+		budget: budget.New(),
 	}, nil
 }
 
+// BudgetUsage 返回今天已消耗的 AI 请求次数及上限，供 /budget 一类的用量查询命令使用；
+// 未开启每日额度时 Limit 为 0
+func (a *Analyzer) BudgetUsage() (used int, limit int) {
+	if a.budget == nil {
+		return 0, 0
+	}
+	return a.budget.Usage()[budgetKey].Used, a.cfg.DailyBudget
+}
+
 func (a *Analyzer) AnalyzeNews(ctx context.Context, newsList []*models.News) error {
 	if !a.cfg.Enabled || len(newsList) == 0 {
 		return nil
 	}
 
 	for _, news := range newsList {
+		if !a.budget.TryConsume(budgetKey, a.cfg.DailyBudget) {
+			log.Printf("AI 每日请求额度已用尽（%d 次），跳过分析直接发送原文: %s", a.cfg.DailyBudget, news.OriginalTitle)
+			eventhook.Emit("budget_exhausted", "ai", fmt.Sprintf("AI 每日请求额度已用尽（%d 次）", a.cfg.DailyBudget))
+			continue
+		}
 		if err := a.analyzeNewsItem(ctx, news); err != nil {
 			log.Printf("分析新闻失败 [%s]: %v", news.ID, err)
 			continue
@@ -68,8 +89,56 @@ func (a *Analyzer) AnalyzeNews(ctx context.Context, newsList []*models.News) err
 	return nil
 }
 
+// Recap 把一批新闻标题归纳成一份简短的中文简报，供 /recap 一类"这段时间错过了
+// 什么"的命令使用；未开启 AI 或当日额度已用尽时返回 ok=false，调用方应退化为
+// 直接列出标题
+func (a *Analyzer) Recap(ctx context.Context, newsList []*models.News) (recap string, ok bool) {
+	if !a.cfg.Enabled || len(newsList) == 0 {
+		return "", false
+	}
+	if !a.budget.TryConsume(budgetKey, a.cfg.DailyBudget) {
+		log.Printf("AI 每日请求额度已用尽（%d 次），/recap 退化为标题列表", a.cfg.DailyBudget)
+		return "", false
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(a.cfg.Timeout)*time.Second)
+	defer cancel()
+
+	resp, err := a.client.CreateChatCompletion(
+		timeoutCtx,
+		openai.ChatCompletionRequest{
+			Model: a.cfg.Model,
+			Messages: []openai.ChatCompletionMessage{
+				{
+					Role:    openai.ChatMessageRoleUser,
+					Content: buildRecapPrompt(newsList),
+				},
+			},
+			MaxTokens:   a.cfg.Params.MaxTokens,
+			Temperature: float32(a.cfg.Params.Temperature),
+		},
+	)
+	if err != nil || len(resp.Choices) == 0 {
+		log.Printf("生成简报失败: %v", err)
+		return "", false
+	}
+	return strings.TrimSpace(resp.Choices[0].Message.Content), true
+}
+
+// buildRecapPrompt 把一批新闻标题（按发布时间标注）拼成请求模型归纳要点的提示词
+func buildRecapPrompt(newsList []*models.News) string {
+	var titles strings.Builder
+	for _, news := range newsList {
+		titles.WriteString(fmt.Sprintf("- [%s] %s\n", news.CreateTime.Format("01-02 15:04"), news.OriginalTitle))
+	}
+	return fmt.Sprintf(`以下是过去一段时间推送过的新闻标题，请用简短的中文归纳这段时间的要点，
+按重要性排序，不需要逐条复述，5条以内即可：
+
+%s`, titles.String())
+}
+
 func (a *Analyzer) analyzeNewsItem(ctx context.Context, news *models.News) error {
-	log.Printf("开始分析新闻: %s (ID: %s)", news.OriginalTitle, news.ID)
+	log.Printf("开始分析新闻: %s (ID: %s, TraceID: %s)", news.OriginalTitle, news.ID, news.TraceID)
 
 	// 创建带超时的上下文
 	timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(a.cfg.Timeout)*time.Second)
@@ -107,8 +176,9 @@ func (a *Analyzer) analyzeNewsItem(ctx context.Context, news *models.News) error
 		}
 
 		if len(resp.Choices) > 0 {
-			news.TranslatedContent = resp.Choices[0].Message.Content
-			log.Printf("新闻分析完成: %s", news.OriginalTitle)
+			content := resp.Choices[0].Message.Content
+			applyAnalysisResult(news, content)
+			log.Printf("新闻分析完成: %s (TraceID: %s)", news.OriginalTitle, news.TraceID)
 			return nil
 		}
 	}
@@ -116,18 +186,78 @@ func (a *Analyzer) analyzeNewsItem(ctx context.Context, news *models.News) error
 	return lastErr
 }
 
-func (a *Analyzer) buildPrompt(news *models.News) string {
-	return fmt.Sprintf(`请分析以下新闻：
+// sectionLabelPattern 匹配 buildPrompt 要求模型输出的分节标签（如"标题:"、"分析:"），
+// 用于把一次回复按标签切分成翻译、摘要、分析等各自独立的字段
+var sectionLabelPattern = regexp.MustCompile(`(?m)^(标题|正文|摘要|分析|情绪分数)[:：]`)
+
+// parseSections 按 sectionLabelPattern 把模型回复切分成 标签 -> 该标签下的正文 的映射，
+// 每个标签的内容从标签结束处一直延伸到下一个标签开始处（或文本结尾）
+func parseSections(content string) map[string]string {
+	locs := sectionLabelPattern.FindAllStringSubmatchIndex(content, -1)
+	sections := make(map[string]string, len(locs))
+	for i, loc := range locs {
+		label := content[loc[2]:loc[3]]
+		start := loc[1]
+		end := len(content)
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
+		}
+		sections[label] = strings.TrimSpace(content[start:end])
+	}
+	return sections
+}
+
+// applyAnalysisResult 把模型一次回复里的翻译、摘要、分析、情绪分数分别写入新闻对应的字段；
+// 模型没有按要求的格式输出任何分节标签时，退化为把整段回复当作分析结果，
+// 避免因为格式不严格匹配就把这次分析白白丢弃
+func applyAnalysisResult(news *models.News, content string) {
+	sections := parseSections(content)
+	if len(sections) == 0 {
+		news.Analysis = strings.TrimSpace(content)
+		return
+	}
+	if v, ok := sections["标题"]; ok && v != "" {
+		news.TranslatedTitle = v
+	}
+	if v, ok := sections["正文"]; ok && v != "" {
+		news.TranslatedContent = v
+	}
+	if v, ok := sections["摘要"]; ok && v != "" {
+		news.Summary = v
+	}
+	if v, ok := sections["分析"]; ok && v != "" {
+		news.Analysis = v
+	}
+	if score, ok := parseSentiment(content); ok {
+		news.SentimentScore = score
+	}
+}
 
-标题：%s
-内容：%s
+// parseSentiment 从分析结果文本中提取情绪分数，未找到时返回 ok=false
+func parseSentiment(content string) (float64, bool) {
+	matches := sentimentPattern.FindStringSubmatch(content)
+	if len(matches) != 2 {
+		return 0, false
+	}
+	score, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	return score, true
+}
+
+func (a *Analyzer) buildPrompt(news *models.News) string {
+	return fmt.Sprintf(`请依次给出以下新闻的翻译、摘要和分析，严格按下面的标签分节输出，
+每个标签独占一行，标签之间不要有多余的说明文字：
 
-请提供以下分析：
-1. 新闻类型（政治、经济、科技、加密货币等）
-2. 影响范围（局部、区域、全球）
-3. 重要性评估（低、中、高）
-4. 潜在影响（市场、政策、技术等）
-5. 建议行动（关注、观望、采取行动等）
+标题: 原标题的中文翻译，原文已经是中文则原样输出
+正文: 原文内容的中文翻译，原文已经是中文则原样输出
+摘要: 一句话概括新闻要点，不超过50字
+分析: 依次说明新闻类型（政治、经济、科技、加密货币等）、影响范围（局部、区域、全球）、
+重要性评估（低、中、高）、潜在影响（市场、政策、技术等）、建议行动（关注、观望、采取行动等），
+每项用1-2句话说明
+情绪分数: 一个 -1 到 1 之间的小数，-1 表示极度负面，0 表示中性，1 表示极度正面
 
-请用简洁明了的语言进行分析，每个部分用1-2句话说明。`, news.OriginalTitle, news.OriginalContent)
+原标题：%s
+原正文：%s`, news.OriginalTitle, news.OriginalContent)
 }
@@ -0,0 +1,115 @@
+// Package archive 记录每条已发送新闻在各个聊天里对应的 Telegram 消息ID及发送时的
+// 内容指纹，供同一条目（相同来源+ID）再次抓取到但内容发生变化时，编辑原消息而不是
+// 重复推送一条新消息
+package archive
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/amylixing/news-fetcher/internal/models"
+)
+
+// Record 记录一条新闻发送时的内容指纹和各聊天对应的消息ID
+type Record struct {
+	ContentHash string                     `json:"content_hash"`
+	Messages    map[string]int64           `json:"messages"`           // chatID -> Telegram 消息ID
+	Receipts    map[string]DeliveryReceipt `json:"receipts,omitempty"` // chatID -> 投递回执，开启 delivery_receipts 时记录
+	// TraceID 是该条新闻抓取时生成的追踪ID，贯穿抓取/翻译/分析/发送各阶段的日志，
+	// 记录在这里便于事后从归档记录反查某条新闻当时完整的处理链路
+	TraceID string `json:"trace_id,omitempty"`
+	// ArchivedAt 是这条记录最近一次调用 Update 落盘的时间，由 Store.Update 自动
+	// 填写；用于 `news-fetcher simulate --since` 圈定重放窗口
+	ArchivedAt time.Time `json:"archived_at,omitempty"`
+	// Raw 是该条新闻当时的完整快照（含翻译/AI分析结果），仅在 simulation.enabled
+	// 时才会被写入；供 `news-fetcher simulate` 用当前配置的过滤规则重放历史流量，
+	// 评估调参效果。未开启时为空，归档文件不会因此显著增大
+	Raw *models.News `json:"raw,omitempty"`
+}
+
+// DeliveryReceipt 记录一条新闻在某个聊天里最近一次投递尝试的时间、次数和最终
+// 状态，供 /receipts 命令排查"为什么这条没有出现在某个频道"而不必翻日志
+type DeliveryReceipt struct {
+	SentAt    time.Time `json:"sent_at"`
+	MessageID int64     `json:"message_id"`      // 投递成功时对应的 Telegram 消息ID，失败时为 0
+	Attempts  int       `json:"attempts"`        // 本次投递累计尝试次数（含失败的重试）
+	Status    string    `json:"status"`          // "sent" 或 "failed"
+	Error     string    `json:"error,omitempty"` // Status 为 failed 时的错误信息
+}
+
+// Store 把每条新闻（以 "source:id" 为键）的发送记录保存到一个 JSON 文件
+type Store struct {
+	mu      sync.Mutex
+	path    string
+	records map[string]Record
+}
+
+// NewStore 创建一个发送记录存储，文件不存在时视为空
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path, records: make(map[string]Record)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取发送记录文件失败: %v", err)
+	}
+	if err := json.Unmarshal(data, &s.records); err != nil {
+		return nil, fmt.Errorf("解析发送记录文件失败: %v", err)
+	}
+	return s, nil
+}
+
+// Key 生成一条新闻的发送记录键
+func Key(source, id string) string {
+	return source + ":" + id
+}
+
+// Get 返回某条新闻当前的发送记录，不存在时返回零值和 false
+func (s *Store) Get(key string) (Record, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.records[key]
+	return r, ok
+}
+
+// Update 更新某条新闻的发送记录并立即持久化
+func (s *Store) Update(key string, r Record) error {
+	r.ArchivedAt = time.Now()
+	s.mu.Lock()
+	s.records[key] = r
+	data, err := json.MarshalIndent(s.records, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("序列化发送记录失败: %v", err)
+	}
+	if err := os.WriteFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("写入发送记录失败: %v", err)
+	}
+	return nil
+}
+
+// All 返回当前所有发送记录，键为 "source:id"，供 `news-fetcher simulate`
+// 一类需要遍历全部历史记录的只读场景使用
+func (s *Store) All() map[string]Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]Record, len(s.records))
+	for k, v := range s.records {
+		out[k] = v
+	}
+	return out
+}
+
+// ContentHash 计算新闻正文相关字段的指纹，用于判断同一条目再次抓取到时内容
+// （原文、译文、AI 摘要、AI 分析）是否发生了变化
+func ContentHash(originalContent, translatedContent, summary, analysis string) string {
+	sum := sha256.Sum256([]byte(originalContent + "\x00" + translatedContent + "\x00" + summary + "\x00" + analysis))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,181 @@
+// Package botcmd 提供一个最小的 Telegram Bot 命令分发器：监听 /command 消息，
+// 按注册时声明的权限级别校验调用者身份，再转交给具体的处理函数。
+package botcmd
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Level 表示命令所需的权限级别
+type Level int
+
+const (
+	LevelPublic Level = iota // 任何人可执行，如只读查询命令
+	LevelAdmin               // 仅限管理员白名单内的用户可执行的控制类命令
+)
+
+// Handler 处理一条命令并返回要回复的文本，返回空字符串表示不回复
+type Handler func(ctx context.Context, msg *tgbotapi.Message, args string) (string, error)
+
+// CallbackHandler 处理一次内联按钮点击，data 为去掉注册前缀后剩余的
+// callback_data，返回要作为 toast 提示回显给点击者的文本，返回空字符串表示不提示
+type CallbackHandler func(ctx context.Context, callback *tgbotapi.CallbackQuery, data string) (string, error)
+
+// InlineQueryHandler 处理一次内联查询（用户在任意聊天输入 "@botname 关键词"触发），
+// 返回要展示给用户选择的结果列表
+type InlineQueryHandler func(ctx context.Context, query *tgbotapi.InlineQuery) ([]interface{}, error)
+
+type command struct {
+	level   Level
+	handler Handler
+}
+
+type callbackRoute struct {
+	prefix  string
+	handler CallbackHandler
+}
+
+// Dispatcher 监听 Telegram 更新，按权限级别把命令分发给已注册的处理函数
+type Dispatcher struct {
+	bot         *tgbotapi.BotAPI
+	adminIDs    map[int64]bool
+	commands    map[string]*command
+	callbacks   []*callbackRoute
+	inlineQuery InlineQueryHandler // 为空表示不响应内联查询
+}
+
+// NewDispatcher 创建命令分发器，adminIDs 为空表示没有用户可以执行管理类命令
+func NewDispatcher(bot *tgbotapi.BotAPI, adminIDs []int64) *Dispatcher {
+	admins := make(map[int64]bool, len(adminIDs))
+	for _, id := range adminIDs {
+		admins[id] = true
+	}
+	return &Dispatcher{
+		bot:      bot,
+		adminIDs: admins,
+		commands: make(map[string]*command),
+	}
+}
+
+// Register 注册一个命令处理函数，name 不含前导 "/"
+func (d *Dispatcher) Register(name string, level Level, handler Handler) {
+	d.commands[name] = &command{level: level, handler: handler}
+}
+
+// RegisterCallback 注册一个内联按钮回调处理函数，callback_data 以 prefix 开头
+// 的按钮点击都会分发给 handler，多个前缀之间不做冲突检测，按注册顺序匹配第一个
+func (d *Dispatcher) RegisterCallback(prefix string, handler CallbackHandler) {
+	d.callbacks = append(d.callbacks, &callbackRoute{prefix: prefix, handler: handler})
+}
+
+// RegisterInlineQuery 注册内联查询处理函数，同一时刻只生效一个，重复调用后一次覆盖前一次
+func (d *Dispatcher) RegisterInlineQuery(handler InlineQueryHandler) {
+	d.inlineQuery = handler
+}
+
+// Listen 阻塞式拉取 Telegram 更新并分发命令，直到 ctx 被取消
+func (d *Dispatcher) Listen(ctx context.Context) {
+	u := tgbotapi.NewUpdate(0)
+	u.Timeout = 60
+	updates := d.bot.GetUpdatesChan(u)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case update := <-updates:
+			if update.CallbackQuery != nil {
+				d.dispatchCallback(ctx, update.CallbackQuery)
+				continue
+			}
+			if update.InlineQuery != nil {
+				d.dispatchInlineQuery(ctx, update.InlineQuery)
+				continue
+			}
+			if update.Message == nil || !update.Message.IsCommand() {
+				continue
+			}
+			d.dispatch(ctx, update.Message)
+		}
+	}
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context, msg *tgbotapi.Message) {
+	name := msg.Command()
+	cmd, ok := d.commands[name]
+	if !ok {
+		return
+	}
+
+	if cmd.level == LevelAdmin && (msg.From == nil || !d.adminIDs[msg.From.ID]) {
+		log.Printf("拒绝执行管理命令 /%s：调用者不在管理员白名单中", name)
+		d.reply(msg.Chat.ID, "权限不足，该命令仅限管理员使用")
+		return
+	}
+
+	reply, err := cmd.handler(ctx, msg, msg.CommandArguments())
+	if err != nil {
+		log.Printf("命令 /%s 执行失败: %v", name, err)
+		d.reply(msg.Chat.ID, "执行失败: "+err.Error())
+		return
+	}
+	if reply != "" {
+		d.reply(msg.Chat.ID, reply)
+	}
+}
+
+func (d *Dispatcher) dispatchCallback(ctx context.Context, callback *tgbotapi.CallbackQuery) {
+	for _, route := range d.callbacks {
+		data, ok := strings.CutPrefix(callback.Data, route.prefix)
+		if !ok {
+			continue
+		}
+
+		text, err := route.handler(ctx, callback, data)
+		if err != nil {
+			log.Printf("处理内联按钮回调 %q 失败: %v", route.prefix, err)
+			d.answerCallback(callback.ID, "处理失败: "+err.Error())
+			return
+		}
+		d.answerCallback(callback.ID, text)
+		return
+	}
+}
+
+func (d *Dispatcher) dispatchInlineQuery(ctx context.Context, query *tgbotapi.InlineQuery) {
+	if d.inlineQuery == nil {
+		return
+	}
+
+	results, err := d.inlineQuery(ctx, query)
+	if err != nil {
+		log.Printf("处理内联查询 %q 失败: %v", query.Query, err)
+		return
+	}
+
+	inlineConfig := tgbotapi.InlineConfig{
+		InlineQueryID: query.ID,
+		Results:       results,
+		CacheTime:     0,
+		IsPersonal:    true,
+	}
+	if _, err := d.bot.Request(inlineConfig); err != nil {
+		log.Printf("应答内联查询失败: %v", err)
+	}
+}
+
+func (d *Dispatcher) answerCallback(callbackID, text string) {
+	if _, err := d.bot.Request(tgbotapi.NewCallback(callbackID, text)); err != nil {
+		log.Printf("应答内联按钮回调失败: %v", err)
+	}
+}
+
+func (d *Dispatcher) reply(chatID int64, text string) {
+	if _, err := d.bot.Send(tgbotapi.NewMessage(chatID, text)); err != nil {
+		log.Printf("回复命令消息失败: %v", err)
+	}
+}
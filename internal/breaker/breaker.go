@@ -0,0 +1,139 @@
+// Package breaker 为每个数据源提供一个独立的熔断器：连续失败达到阈值后短路，
+// 冷却时间过后进入半开状态放行少量探测请求，避免一个失效的上游拖慢每一轮抓取、
+// 白白消耗其余数据源的重试预算
+package breaker
+
+import (
+	"sync"
+	"time"
+)
+
+// State 表示熔断器当前状态
+type State int
+
+const (
+	StateClosed   State = iota // 正常放行
+	StateOpen                  // 短路，直接拒绝
+	StateHalfOpen              // 冷却结束，放行少量探测请求
+)
+
+func (s State) String() string {
+	switch s {
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// Breaker 是一个简单的熔断器：连续失败 FailureThreshold 次后进入 open，
+// 冷却 Cooldown 时长后进入 half-open 放行 HalfOpenMaxProbes 次探测，
+// 探测成功则回到 closed，失败则重新回到 open 并重新计冷却时间
+type Breaker struct {
+	failureThreshold int
+	cooldown         time.Duration
+	halfOpenMax      int
+
+	mu           sync.Mutex
+	state        State
+	failures     int
+	openedAt     time.Time
+	halfOpenUsed int
+}
+
+// New 创建一个熔断器，failureThreshold<=0 时使用默认值 5，cooldown<=0 时使用默认值 1 分钟，
+// halfOpenMaxProbes<=0 时使用默认值 1
+func New(failureThreshold int, cooldown time.Duration, halfOpenMaxProbes int) *Breaker {
+	if failureThreshold <= 0 {
+		failureThreshold = 5
+	}
+	if cooldown <= 0 {
+		cooldown = time.Minute
+	}
+	if halfOpenMaxProbes <= 0 {
+		halfOpenMaxProbes = 1
+	}
+	return &Breaker{
+		failureThreshold: failureThreshold,
+		cooldown:         cooldown,
+		halfOpenMax:      halfOpenMaxProbes,
+	}
+}
+
+// Allow 判断本次调用是否应该放行，open 状态下冷却时间一到会自动转入 half-open
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = StateHalfOpen
+		b.halfOpenUsed = 0
+		fallthrough
+	case StateHalfOpen:
+		if b.halfOpenUsed >= b.halfOpenMax {
+			return false
+		}
+		b.halfOpenUsed++
+		return true
+	default:
+		return true
+	}
+}
+
+// RecordSuccess 记录一次成功调用，closed 状态下清零失败计数，half-open 下直接恢复 closed
+func (b *Breaker) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.state = StateClosed
+}
+
+// RecordFailure 记录一次失败调用，达到阈值或探测失败时进入/重新进入 open
+func (b *Breaker) RecordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateHalfOpen {
+		b.trip()
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.failureThreshold {
+		b.trip()
+	}
+}
+
+// RecordPermanentFailure 记录一次判定为永久性（不可通过重试恢复）的失败调用，
+// 不等待连续失败达到阈值，直接进入/重新进入 open——例如上游返回 404/401 意味着
+// 这个数据源本身已经失效，没有必要再消耗探测配额才确认
+func (b *Breaker) RecordPermanentFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trip()
+}
+
+func (b *Breaker) trip() {
+	b.state = StateOpen
+	b.openedAt = time.Now()
+	b.failures = b.failureThreshold
+}
+
+// Snapshot 描述熔断器当前状态，供 /status 一类的只读查询使用
+type Snapshot struct {
+	State    string `json:"state"`
+	Failures int    `json:"failures"`
+}
+
+// Snapshot 返回熔断器当前状态的一份只读快照
+func (b *Breaker) Snapshot() Snapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return Snapshot{State: b.state.String(), Failures: b.failures}
+}
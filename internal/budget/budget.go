@@ -0,0 +1,74 @@
+// Package budget 为翻译/AI 等按次计费的外部服务提供一个按天重置的用量计数器：
+// 达到当日上限后拒绝放行，调用方据此优雅降级（跳过分析、发送原文），避免
+// 因为轮询/重试异常导致当天账单意外飙升
+package budget
+
+import (
+	"sync"
+	"time"
+)
+
+// Tracker 按 key（如 "ai"、"translator"，或某个数据源的 URL）分别计数，
+// 每个 key 的计数在自然日切换时自动清零
+type Tracker struct {
+	mu   sync.Mutex
+	day  string
+	used map[string]int
+}
+
+// New 创建一个用量计数器
+func New() *Tracker {
+	return &Tracker{
+		day:  today(),
+		used: make(map[string]int),
+	}
+}
+
+func today() string {
+	return time.Now().Format("2006-01-02")
+}
+
+// resetIfNewDay 假定调用方已持有锁
+func (t *Tracker) resetIfNewDay() {
+	if d := today(); d != t.day {
+		t.day = d
+		t.used = make(map[string]int)
+	}
+}
+
+// TryConsume 判断 key 今天的用量是否还未达到 limit，未达到则计数加一并返回 true；
+// limit<=0 表示不限额，始终放行且不计数
+func (t *Tracker) TryConsume(key string, limit int) bool {
+	if limit <= 0 {
+		return true
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.resetIfNewDay()
+
+	if t.used[key] >= limit {
+		return false
+	}
+	t.used[key]++
+	return true
+}
+
+// Snapshot 描述某个 key 当天的用量，供 /budget 一类的只读查询使用
+type Snapshot struct {
+	Day  string `json:"day"`
+	Used int    `json:"used"`
+}
+
+// Usage 返回当前所有 key 的用量快照
+func (t *Tracker) Usage() map[string]Snapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.resetIfNewDay()
+
+	out := make(map[string]Snapshot, len(t.used))
+	for k, v := range t.used {
+		out[k] = Snapshot{Day: t.day, Used: v}
+	}
+	return out
+}
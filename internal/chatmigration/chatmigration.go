@@ -0,0 +1,57 @@
+// Package chatmigration 记录群组升级为超级群组后 Telegram 分配的新聊天ID，
+// 供发送器在运行期把配置里的旧聊天ID透明地替换成新聊天ID，避免升级后所有推送永久失败
+package chatmigration
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Store 把旧聊天ID到新聊天ID的映射保存到一个 JSON 文件
+type Store struct {
+	mu   sync.Mutex
+	path string
+	m    map[string]string
+}
+
+// NewStore 创建一个聊天ID迁移记录存储，文件不存在时视为空
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path, m: make(map[string]string)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取聊天ID迁移记录文件失败: %v", err)
+	}
+	if err := json.Unmarshal(data, &s.m); err != nil {
+		return nil, fmt.Errorf("解析聊天ID迁移记录文件失败: %v", err)
+	}
+	return s, nil
+}
+
+// Get 返回某个旧聊天ID当前对应的新聊天ID，不存在时返回空串和 false
+func (s *Store) Get(oldChatID string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	newChatID, ok := s.m[oldChatID]
+	return newChatID, ok
+}
+
+// Update 记录一次聊天ID迁移并立即持久化
+func (s *Store) Update(oldChatID, newChatID string) error {
+	s.mu.Lock()
+	s.m[oldChatID] = newChatID
+	data, err := json.MarshalIndent(s.m, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("序列化聊天ID迁移记录失败: %v", err)
+	}
+	if err := os.WriteFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("写入聊天ID迁移记录文件失败: %v", err)
+	}
+	return nil
+}
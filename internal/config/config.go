@@ -9,36 +9,703 @@ import (
 
 // Config 应用配置
 type Config struct {
-	Sources  *SourcesConfig  `yaml:"sources"`
-	Telegram *TelegramConfig `yaml:"telegram"`
-	AI       *AIConfig       `yaml:"ai"`
-	Cache    *CacheConfig    `yaml:"cache"`
-	App      *AppConfig      `yaml:"app"`
+	Sources          *SourcesConfig          `yaml:"sources"`
+	Telegram         *TelegramConfig         `yaml:"telegram"`
+	AI               *AIConfig               `yaml:"ai"`
+	Cache            *CacheConfig            `yaml:"cache"`
+	App              *AppConfig              `yaml:"app"`
+	Webhook          *WebhookConfig          `yaml:"webhook"`
+	HTTPClient       *HTTPClientConfig       `yaml:"http_client"`
+	Sentiment        *SentimentConfig        `yaml:"sentiment"`
+	Watchlist        *WatchlistConfig        `yaml:"watchlist"`
+	Briefing         *BriefingConfig         `yaml:"briefing"`
+	Subscription     *SubscriptionConfig     `yaml:"subscription"`
+	UserWatch        *UserWatchConfig        `yaml:"user_watch"`
+	Mute             *MuteConfig             `yaml:"mute"`
+	OCR              *OCRConfig              `yaml:"ocr"`
+	Ordering         *OrderingConfig         `yaml:"ordering"`
+	EditOnUpdate     *EditOnUpdateConfig     `yaml:"edit_on_update"`
+	DuplicateWindow  *DuplicateWindowConfig  `yaml:"duplicate_window"`
+	DeliveryReceipts *DeliveryReceiptsConfig `yaml:"delivery_receipts"`
+	DeadLetter       *DeadLetterConfig       `yaml:"dead_letter"`
+	ReadState        *ReadStateConfig        `yaml:"read_state"`
+	Digest           *DigestConfig           `yaml:"digest"`
+	Escalation       *EscalationConfig       `yaml:"escalation"`
+	Journal          *JournalConfig          `yaml:"journal"`
+	LifecycleNotify  *LifecycleNotifyConfig  `yaml:"lifecycle_notify"`
+	Degradation      *DegradationConfig      `yaml:"degradation"`
+	Simulation       *SimulationConfig       `yaml:"simulation"`
+	EventHook        *EventHookConfig        `yaml:"event_hook"`
+	Profiles         []*ProfileConfig        `yaml:"profiles"`
+}
+
+// EventHookConfig 管道生命周期事件推送配置：开启后，数据源熔断/恢复、单轮处理
+// 完成、每日额度用尽等内部状态变化会以结构化 JSON POST 到 URL，供外部监控和自动化
+// 系统据此响应，而不必解析日志。仓库尚未引入 MQTT 客户端依赖，暂只支持 HTTP webhook
+type EventHookConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	URL     string `yaml:"url"`
+	Timeout int    `yaml:"timeout"` // 秒，<=0 时默认 10 秒
+}
+
+// SimulationConfig 控制 archive 是否额外持久化每条新闻抓取时的完整快照（含翻译/
+// AI分析结果），供 `news-fetcher simulate --since` 子命令用当前配置的过滤规则
+// 重放历史流量、评估调参效果；默认关闭，开启后归档文件体积会明显增大
+type SimulationConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// DegradationConfig 声明流水线中可选阶段失败时的降级行为，替代此前"跳过该
+// 条目""返回错误中断整批""静默丢弃"混杂不一致的处理方式；未配置时保持
+// 各阶段原有的默认行为不变
+type DegradationConfig struct {
+	// OnAIFailure 声明单条新闻 AI 分析失败（含每日额度用尽）时的处理方式：
+	//   "raw"（默认）跳过分析，直接按原文（未翻译、未摘要）发送
+	//   "drop" 直接丢弃该条新闻，不发送、不重试
+	// 本项目的翻译和 AI 分析共用同一次模型调用（见 internal/ai），没有独立
+	// 的翻译阶段，因此这一项同时覆盖"AI 不可用"和"翻译不可用"两种场景；
+	// "所有发送渠道都失败后排队重试"由 dead_letter.enabled 单独控制
+	OnAIFailure string `yaml:"on_ai_failure"`
+}
+
+// DuplicateWindowConfig 近似重复稿件的抑制窗口配置：多个数据源转载同一条新闻时，
+// 同一时间窗口内的后续来源不再各自推送一条新消息，而是丢弃或合并进已发送的消息
+type DuplicateWindowConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Window  int    `yaml:"window"` // 单位秒，窗口内的近似重复稿件按 Mode 处理，超过此时长视为新故事
+	Mode    string `yaml:"mode"`   // "drop"（直接丢弃）或 "append"（编辑已发送的消息追加来源），默认 "append"
+}
+
+// SubscriptionConfig 自助订阅配置：控制 /subscribe、/unsubscribe 命令是否开放，
+// 以及是否要求管理员权限
+type SubscriptionConfig struct {
+	Enabled   bool `yaml:"enabled"`
+	AdminOnly bool `yaml:"admin_only"` // true 时仅 telegram.commands.admin_ids 内的用户可用，否则任何人可用
+}
+
+// UserWatchConfig 用户个人关键词订阅配置：命中的条目除频道推送外，
+// 还会私信给订阅了该关键词的用户，MaxPerHour 限制每个用户每小时收到的私信条数，避免刷屏
+type UserWatchConfig struct {
+	Enabled    bool `yaml:"enabled"`
+	MaxPerHour int  `yaml:"max_per_hour"` // 单个用户每小时最多收到的私信条数，0 表示不限制
+}
+
+// MuteConfig 数据源临时静音配置：控制 /mute、/unmute、/muted 命令是否开放
+type MuteConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// ReadStateConfig 私信已读状态配置：控制个人关键词订阅私信是否附带"标记已读"
+// 内联按钮、以及 /unread 命令是否开放，二者共用同一个开关
+type ReadStateConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// DigestConfig 按类目拆分的摘要推送配置：区别于 briefing 单一的全局早间简报，
+// 允许为不同类目各自配置独立的汇总周期和推送目标
+type DigestConfig struct {
+	Enabled   bool                    `yaml:"enabled"`
+	Schedules []*DigestScheduleConfig `yaml:"schedules"`
+}
+
+// DigestScheduleConfig 描述一个摘要计划：标题/正文命中 Categories 关键词的条目
+// 归入本计划的独立缓冲区，每隔 IntervalSeconds 汇总推送一次
+type DigestScheduleConfig struct {
+	Name string `yaml:"name"` // 计划名称，用于摘要消息标题，如"监管快讯"
+	// Categories 为大小写不敏感的标题/正文子串匹配关键词，命中其一即归入本计划；
+	// 为空表示匹配未被其它计划命中的所有条目，可用作排在最后的兜底默认计划
+	Categories []string `yaml:"categories"`
+	// IntervalSeconds 为汇总周期，单位秒；0 表示不批量，命中后立即单独推送一条摘要，
+	// 用于需要即时触达的类目（如新币上线公告）
+	IntervalSeconds int    `yaml:"interval_seconds"`
+	ChatID          string `yaml:"chat_id"` // 推送目标聊天，为空则使用 telegram.bot.chat_ids
+	TopN            int    `yaml:"top_n"`   // 单次汇总最多包含的条目数，0 表示不限制
+}
+
+// OCRConfig 图片正文识别配置：当条目正文为空但带有图片（如交易所/Twitter 截图公告）时，
+// 调用本机安装的 OCR 命令行工具提取文字，供后续翻译和 AI 分析使用
+type OCRConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Command string `yaml:"command"` // OCR 可执行文件名或路径，为空默认使用 "tesseract"
+	Timeout int    `yaml:"timeout"` // 秒，下载图片加执行 OCR 的总超时时间，为空默认 30 秒
+}
+
+// OrderingConfig 单轮抓取内的投递顺序配置：数据源本身返回的顺序在抓取量大、
+// 多个源交替出新的时段读起来会很跳，按时间或重要性重排后频道阅读体验更连贯
+type OrderingConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	By      string `yaml:"by"` // "time"（按发布时间从早到晚）或 "importance"（按情绪分数绝对值从高到低），默认 "time"
+}
+
+// EditOnUpdateConfig 内容更新时的编辑推送配置：同一条目（相同来源+ID）再次抓取到但
+// 内容发生变化（原文、译文或 AI 分析）时，编辑此前已发送的消息而不是重复推送一条新消息
+type EditOnUpdateConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// DeliveryReceiptsConfig 开启后，每条新闻在各个聊天的投递结果（发送时间、
+// Telegram 消息ID、尝试次数、最终状态）都会被记录进 archive，
+// 供 /receipts 命令和后续排查"为什么这条没有出现在某个频道"时直接查数据，
+// 而不必翻日志；复用 edit_on_update 的同一份 archive 文件
+type DeliveryReceiptsConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// DeadLetterConfig 死信队列配置：开启后，一条新闻在本轮所有目标聊天都投递失败时，
+// 会连同失败原因存入死信存储，供 news-fetcher deadletter 子命令或
+// /deadletter 机器人命令排查问题、修复后手动重新投递；未开启不影响原有的
+// "下一轮抓取周期自然重试"行为，仅是失去了失败明细和手动重投的入口
+type DeadLetterConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// JournalConfig 崩溃安全的处理进度记录配置：开启后，每条新闻在流水线中走到
+// 已抓取、已完成 AI 分析、已投递到某些聊天、已写入缓存等每一步都会立即落盘，
+// 进程崩溃重启后可以跳过已经投递成功的聊天，不会重复发送，也不会因为整批
+// 状态没有落盘而误判为完全没处理过。未开启不影响原有行为，仅是崩溃后
+// 可能重复投递或漏发部分聊天，与开启前的表现一致
+type JournalConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// LifecycleNotifyConfig 启动/关闭通知配置：开启后，进程启动时推送一条携带版本号、
+// 已启用数据源数量的问候消息，收到终止信号正常关闭时推送一条告别消息，运维人员
+// 从频道本身就能及时发现意外重启或崩溃循环，而不必盯着服务器日志
+type LifecycleNotifyConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	ChatID  string `yaml:"chat_id"` // 通知目标聊天，为空则使用 telegram.bot.chat_ids
+}
+
+// EscalationConfig 严重条目升级通知配置：开启后，评级达到 ImportanceThreshold 的条目
+// 一旦在主推送渠道投递失败，会依次尝试 Channels 中配置的通道，直到某个成功或链路耗尽
+type EscalationConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// ImportanceThreshold 与 sampling.importance_threshold 含义一致，取 |SentimentScore|，
+	// 达到此值才视为"严重条目"从而触发升级链，默认 0 表示任何投递失败的条目都会升级
+	ImportanceThreshold float64                    `yaml:"importance_threshold"`
+	Channels            []*EscalationChannelConfig `yaml:"channels"` // 按顺序尝试的通道链，如 telegram -> ntfy -> sms
+}
+
+// EscalationChannelConfig 描述升级链上的一个通道
+type EscalationChannelConfig struct {
+	// Type 为 "telegram"（转发到 ChatID 指定的管理群/用户，为空则使用 telegram.bot.chat_ids）、
+	// "ntfy"（POST 到 URL 指定的 ntfy.sh 主题地址）或 "sms"（POST 到 URL 指定的短信网关地址），
+	// ntfy/sms 均以纯文本 POST 请求体发送，网关鉴权、号码等细节需体现在 URL 本身
+	Type   string `yaml:"type"`
+	URL    string `yaml:"url"`
+	ChatID string `yaml:"chat_id"`
+}
+
+// ProfileConfig 描述一个独立的租户：拥有自己的一整套数据源、Telegram 目标、
+// AI/过滤/推送配置和去重状态，配置了 profiles 后顶层的 sources/telegram 等字段不再生效，
+// 由各 profile 各自携带一整套，便于同一进程内为多个社区提供互相隔离的抓取与推送
+type ProfileConfig struct {
+	Name             string                  `yaml:"name"`
+	Sources          *SourcesConfig          `yaml:"sources"`
+	Telegram         *TelegramConfig         `yaml:"telegram"`
+	AI               *AIConfig               `yaml:"ai"`
+	Cache            *CacheConfig            `yaml:"cache"`
+	App              *AppConfig              `yaml:"app"`
+	Webhook          *WebhookConfig          `yaml:"webhook"`
+	HTTPClient       *HTTPClientConfig       `yaml:"http_client"`
+	Sentiment        *SentimentConfig        `yaml:"sentiment"`
+	Watchlist        *WatchlistConfig        `yaml:"watchlist"`
+	Briefing         *BriefingConfig         `yaml:"briefing"`
+	Subscription     *SubscriptionConfig     `yaml:"subscription"`
+	UserWatch        *UserWatchConfig        `yaml:"user_watch"`
+	Mute             *MuteConfig             `yaml:"mute"`
+	OCR              *OCRConfig              `yaml:"ocr"`
+	Ordering         *OrderingConfig         `yaml:"ordering"`
+	EditOnUpdate     *EditOnUpdateConfig     `yaml:"edit_on_update"`
+	DuplicateWindow  *DuplicateWindowConfig  `yaml:"duplicate_window"`
+	DeliveryReceipts *DeliveryReceiptsConfig `yaml:"delivery_receipts"`
+	DeadLetter       *DeadLetterConfig       `yaml:"dead_letter"`
+	ReadState        *ReadStateConfig        `yaml:"read_state"`
+	Digest           *DigestConfig           `yaml:"digest"`
+	Escalation       *EscalationConfig       `yaml:"escalation"`
+	Journal          *JournalConfig          `yaml:"journal"`
+	LifecycleNotify  *LifecycleNotifyConfig  `yaml:"lifecycle_notify"`
+	Degradation      *DegradationConfig      `yaml:"degradation"`
+	Simulation       *SimulationConfig       `yaml:"simulation"`
+	EventHook        *EventHookConfig        `yaml:"event_hook"`
+
+	// SharedDedupKey 非空时，与其他携带相同 SharedDedupKey 的 profile 共用同一份
+	// 去重缓存（内存中同一个 cache.Cache 实例），使共享同一批数据源的多个 profile
+	// 不会各自重复分析、翻译同一条新闻，同时仍按各自的 Telegram/Webhook 配置独立投递
+	SharedDedupKey string `yaml:"shared_dedup_key"`
+}
+
+// ToConfig 把 ProfileConfig 展开为一个独立的 Config，供每个 profile 各自
+// 构建互不共享状态的应用实例
+func (p *ProfileConfig) ToConfig() *Config {
+	return &Config{
+		Sources:          p.Sources,
+		Telegram:         p.Telegram,
+		AI:               p.AI,
+		Cache:            p.Cache,
+		App:              p.App,
+		Webhook:          p.Webhook,
+		HTTPClient:       p.HTTPClient,
+		Sentiment:        p.Sentiment,
+		Watchlist:        p.Watchlist,
+		Briefing:         p.Briefing,
+		Subscription:     p.Subscription,
+		UserWatch:        p.UserWatch,
+		Mute:             p.Mute,
+		OCR:              p.OCR,
+		Ordering:         p.Ordering,
+		EditOnUpdate:     p.EditOnUpdate,
+		DuplicateWindow:  p.DuplicateWindow,
+		DeliveryReceipts: p.DeliveryReceipts,
+		DeadLetter:       p.DeadLetter,
+		ReadState:        p.ReadState,
+		Digest:           p.Digest,
+		Escalation:       p.Escalation,
+		Journal:          p.Journal,
+		LifecycleNotify:  p.LifecycleNotify,
+		Degradation:      p.Degradation,
+		Simulation:       p.Simulation,
+		EventHook:        p.EventHook,
+	}
+}
+
+// BriefingConfig 早间简报配置：在每天本地时间固定时刻，把此前累积的条目
+// 汇总成一条结构化消息推送，独立于实时推送流程
+type BriefingConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Hour     int    `yaml:"hour"`     // 0-23，本地时间
+	Minute   int    `yaml:"minute"`   // 0-59
+	Timezone string `yaml:"timezone"` // IANA 时区名，如 "Asia/Shanghai"，为空使用系统本地时区
+	TopN     int    `yaml:"top_n"`    // 简报中最多包含的条目数，0 表示不限制
+	ChatID   string `yaml:"chat_id"`  // 推送目标聊天，为空则使用 telegram.bot.chat_ids
+}
+
+// WatchlistConfig 关注名单配置：命中标题/正文的条目会被打标签，可选路由到专属聊天，
+// 也可以选择直接丢弃未命中的条目
+type WatchlistConfig struct {
+	Enabled        bool     `yaml:"enabled"`
+	Entities       []string `yaml:"entities"`        // 关注的代币/项目名称，大小写不敏感的子串匹配
+	ChatID         string   `yaml:"chat_id"`         // 命中条目额外推送到的专属聊天，为空则不额外推送
+	SuppressOthers bool     `yaml:"suppress_others"` // 是否丢弃未命中关注名单的条目，仅推送命中的
+}
+
+// SentimentConfig 新闻情绪指数配置
+type SentimentConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Interval int    `yaml:"interval"` // 单位秒，多久汇总并推送一次，如 3600 表示每小时
+	ChatID   string `yaml:"chat_id"`  // 推送目标聊天，为空则使用 telegram.bot.chat_ids
+}
+
+// HTTPClientConfig 各组件共享的 HTTP 连接池默认参数
+type HTTPClientConfig struct {
+	MaxIdleConns        int        `yaml:"max_idle_conns"`
+	MaxIdleConnsPerHost int        `yaml:"max_idle_conns_per_host"`
+	IdleConnTimeout     int        `yaml:"idle_conn_timeout"` // 秒
+	DisableKeepAlives   bool       `yaml:"disable_keep_alives"`
+	DisableHTTP2        bool       `yaml:"disable_http2"`
+	DNS                 *DNSConfig `yaml:"dns"`
+}
+
+// DNSConfig 域名解析相关配置
+type DNSConfig struct {
+	CacheTTL int      `yaml:"cache_ttl"` // 秒，0 表示不缓存
+	Servers  []string `yaml:"servers"`   // 自定义 DNS 服务器，形如 "8.8.8.8:53"，为空则使用系统默认解析器
 }
 
 // SourcesConfig 数据源配置
 type SourcesConfig struct {
 	API []*SourceConfig `yaml:"api"`
 	RSS []*SourceConfig `yaml:"rss"`
+	// RSSOPML 指向一个 OPML 订阅列表文件，其中的每个 feed 按 RSS 数据源加入，
+	// 用于从 RSS 阅读器批量迁移大量订阅而不必逐条手写 YAML；与 RSS 里手写的
+	// 数据源合并生效，同一个 URL 重复出现时各自单独抓取
+	RSSOPML    string          `yaml:"rss_opml"`
+	Mock       []*SourceConfig `yaml:"mock"`       // 从本地固定文件回放新闻，用于录制/回放式的离线联调
+	HackerNews []*SourceConfig `yaml:"hackernews"` // 内置的 Hacker News 数据源，从 Firebase API 拉取 top/new/best 故事
+	// Custom 是通过 fetcher.Register 注册的第三方数据源类型，每一项的 Type 字段
+	// 对应注册时使用的名字；内置的 api/rss/mock/hackernews 各自有专属的熔断、
+	// 自适应轮询、WebSub 推送等能力，不走这里，仍然各自单独配置
+	Custom []*SourceConfig `yaml:"custom"`
+	// WASMPlugins 从一个目录批量发现 WASM 数据源插件，每个插件展开为一条 Custom
+	// 条目（type: wasm），详见 WASMPluginConfig
+	WASMPlugins *WASMPluginConfig      `yaml:"wasm_plugins"`
+	Normalize   *NormalizeConfig       `yaml:"normalize"`
+	Breaker     *BreakerConfig         `yaml:"breaker"`
+	RateLimit   *SourceRateLimitConfig `yaml:"rate_limit"`
+	Health      *HealthConfig          `yaml:"health"`
+	// WebSub 开启后为声明了 hub 的 RSS 源启用推送订阅，收到更新由 hub 主动推送到
+	// 本地回调地址，具体哪些 RSS 源参与由各自的 SourceConfig.WebSub 字段决定
+	WebSub      *WebSubConfig `yaml:"websub"`
+	Concurrency int           `yaml:"concurrency"` // 同时抓取的数据源数量上限，<=0 时使用抓取器内置默认值
+	// ProxyPool 全局代理池，未在具体数据源上单独配置 proxy_pool 时使用这一份，
+	// 与单一的 proxy_url 相比支持多个代理轮转，并对连续失败的代理自动摘除
+	ProxyPool *ProxyPoolConfig `yaml:"proxy_pool"`
+	// UserAgent 全局默认 User-Agent，未在具体数据源上单独配置 user_agent 时使用这一份，
+	// 未配置时沿用 Go 标准库默认的 "Go-http-client/1.1"
+	UserAgent string `yaml:"user_agent"`
+	// HeaderProfile 全局默认命名请求头集合（如 "chrome"/"firefox"/"safari"），未在
+	// 具体数据源上单独配置 header_profile 时使用这一份，取值见 fetcher 包内置的
+	// headerProfiles；配置了不存在的名称等价于不使用任何预设
+	HeaderProfile string `yaml:"header_profile"`
+}
+
+// WASMPluginConfig 从一个目录批量加载 WASM 数据源插件：目录下每个 .wasm 文件展开
+// 为一条 sources.custom 里 type 为 "wasm" 的条目，由内嵌的 wazero 运行时在进程内
+// 直接执行（见 fetcher.WASMSource），不依赖操作系统上另外安装的 WASM 运行时
+type WASMPluginConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Dir     string `yaml:"dir"`     // 存放 .wasm 插件文件的目录
+	Timeout int    `yaml:"timeout"` // 秒，单次运行超时，<=0 时默认 30 秒
+}
+
+// ProxyPoolConfig 代理池配置：List 中的每个代理各自独立计数连续失败次数，
+// 达到阈值后按熔断器语义临时摘除，冷却时间结束后自动恢复重新参与轮转
+type ProxyPoolConfig struct {
+	List []string `yaml:"list"`
+	// Strategy 为 "round_robin"（默认，依次轮转）或 "least_failure"
+	// （优先选择当前失败次数最少的代理）
+	Strategy string `yaml:"strategy"`
+}
+
+// WebSubConfig 配置 RSS 数据源的 WebSub（PubSubHubbub）推送订阅回调服务：hub
+// 支持的 feed 收到更新时会由 hub 主动推送到这里指定的回调地址，而不必持续轮询；
+// 发现不了 hub 或订阅请求失败的数据源自动回退为按 poll_interval 轮询
+type WebSubConfig struct {
+	Enabled         bool   `yaml:"enabled"`
+	CallbackBaseURL string `yaml:"callback_base_url"` // 公网可达的回调基地址，如 "https://example.com:8090"，hub 需要能访问到
+	ListenAddr      string `yaml:"listen_addr"`       // 回调 HTTP 服务的本地监听地址，如 ":8090"
+	LeaseSeconds    int    `yaml:"lease_seconds"`     // 订阅租约时长（秒），<=0 时不携带该参数，使用 hub 的默认值
+}
+
+// HealthConfig 数据源健康监控配置：统计每个数据源的成功率、平均延迟和最近一次
+// 成功时间，连续失败达到阈值时自动禁用该数据源，避免长期失效的上游继续消耗
+// 抓取轮次；与 breaker 的短期熔断-冷却-恢复不同，这里是长期的"判定为失效"
+type HealthConfig struct {
+	AutoDisableAfter int `yaml:"auto_disable_after"` // 连续失败多少次后自动禁用，<=0 表示不自动禁用
 }
 
-// SourceConfig 单个数据源配置
+// BreakerConfig 单数据源熔断器配置：连续失败达到阈值后短路一段时间，
+// 避免一个失效的上游拖慢每一轮抓取、消耗其余数据源的重试预算
+type BreakerConfig struct {
+	Enabled           bool `yaml:"enabled"`
+	FailureThreshold  int  `yaml:"failure_threshold"`    // 连续失败多少次后短路，默认 5
+	CooldownSeconds   int  `yaml:"cooldown_seconds"`     // 短路后多久进入半开状态探测，默认 60 秒
+	HalfOpenMaxProbes int  `yaml:"half_open_max_probes"` // 半开状态下最多放行几次探测请求，默认 1
+}
+
+// SourceRateLimitConfig 单数据源令牌桶限速配置：控制每个数据源自己的请求频率上限，
+// 避免过于激进的轮询触发上游的封禁策略；与 telegram.rate_limit（控制消息发送速率）是
+// 两套独立的限速
+type SourceRateLimitConfig struct {
+	Enabled           bool `yaml:"enabled"`
+	RequestsPerMinute int  `yaml:"requests_per_minute"` // 每分钟最多放行多少次请求，默认 60
+	Burst             int  `yaml:"burst"`               // 令牌桶容量，允许短时突发的请求数，默认等于 RequestsPerMinute
+}
+
+// NormalizeConfig 链接规范化配置：去除易变的追踪参数，避免同一篇文章因
+// utm_* 等参数不同而被反复当作新的条目
+type NormalizeConfig struct {
+	Enabled          bool     `yaml:"enabled"`
+	StripParams      []string `yaml:"strip_params"`      // 支持以 "*" 结尾的前缀匹配，为空使用内置默认列表
+	ResolveRedirects bool     `yaml:"resolve_redirects"` // 是否额外发起一次 HEAD 请求跟随重定向解析出最终地址
+	// MaxRedirectHops 限制跟随重定向的最大跳数，用于 Google News 等聚合器的多级
+	// 中间跳转链接，避免个别链接的重定向异常（如循环跳转）拖住抓取；0 表示使用
+	// Go 标准库默认上限（10 跳）
+	MaxRedirectHops int `yaml:"max_redirect_hops"`
+}
+
+// SourceConfig 单个数据源配置。对于 mock 类型的数据源，URL 字段被复用为本地
+// 固定文件（JSON，内容为 models.News 数组）的路径
 type SourceConfig struct {
-	URL      string                 `yaml:"url"`
+	// Type 仅供 sources.custom 里的条目使用，对应 fetcher.Register 注册时使用的
+	// 名字，决定用哪个构造函数创建这条数据源；内置的 api/rss/mock/hackernews
+	// 各自有独立的配置列表，不使用这个字段
+	Type string `yaml:"type"`
+	URL  string `yaml:"url"`
+	// Args 仅供 exec 类型的数据源使用，作为 URL 指定的可执行文件的命令行参数，
+	// 例如通过 WASM 运行时命令行工具运行插件时传 ["run", "plugin.wasm"]
+	Args     []string               `yaml:"args"`
 	Params   map[string]interface{} `yaml:"params"`
 	Headers  map[string]string      `yaml:"headers"`
 	Retry    *RetryConfig           `yaml:"retry"`
 	Timeout  int                    `yaml:"timeout"`
 	ProxyURL string                 `yaml:"proxy_url"`
+	// ProxyPool 覆盖全局的 sources.proxy_pool，仅对当前数据源生效；同时配置了
+	// proxy_url 时以 ProxyPool 优先
+	ProxyPool *ProxyPoolConfig `yaml:"proxy_pool"`
+	// UserAgent 覆盖全局的 sources.user_agent，仅对当前数据源生效；HeaderProfile
+	// 里预设的 User-Agent 会先被应用，再被这里显式配置的值覆盖
+	UserAgent string `yaml:"user_agent"`
+	// HeaderProfile 覆盖全局的 sources.header_profile，仅对当前数据源生效，
+	// 用于伪装成浏览器请求头以访问屏蔽默认 Go 客户端 UA 的数据源
+	HeaderProfile string     `yaml:"header_profile"`
+	MaxItems      int        `yaml:"max_items"` // 单次抓取最多处理的条目数，0 表示不限制
+	MaxAge        int        `yaml:"max_age"`   // 单位秒，早于此时间的条目将被丢弃，0 表示不限制
+	TLS           *TLSConfig `yaml:"tls"`
+	// Priority 为 "high" 时表示突发新闻源，配合更短的 PollInterval 实现快速轮询，
+	// 空值等价于 "normal"
+	Priority     string            `yaml:"priority"`
+	PollInterval int               `yaml:"poll_interval"` // 单位秒，该数据源自己的抓取间隔，0 表示跟随全局 app.fetch_interval
+	Backfill     *BackfillConfig   `yaml:"backfill"`      // 首次抓取（尚无抓取游标）时的历史补发策略，未配置则维持原样一次性全量处理
+	BasicAuth    *BasicAuthConfig  `yaml:"basic_auth"`    // HTTP Basic Auth 凭据，用于需要认证的付费 RSS 源
+	Cookies      map[string]string `yaml:"cookies"`       // 请求时附带的 Cookie，键为名称、值为内容，用于依赖会话态的付费 RSS 源
+	// Lenient 为 true 时，对不符合规范的 XML（如未转义的裸 "&"）尝试修复后重新解析，
+	// 修复后仍无法解析时退化为用正则从 <item> 块中提取标题和链接，尽量产出条目
+	// 而不是让整个源因为个别不规范字符直接抓取失败
+	Lenient bool `yaml:"lenient"`
+	// ScheduleWindow 限制该数据源仅在特定时间窗口内被轮询，未配置则不限制，任意时刻均可轮询
+	ScheduleWindow *ScheduleWindowConfig `yaml:"schedule_window"`
+	// AdaptivePolling 开启后按该源实际产出新条目的频率，在配置的上下限范围内自动
+	// 拉长/缩短其有效轮询间隔，未配置或未开启则维持固定的 PollInterval（或全局间隔）
+	AdaptivePolling *AdaptivePollingConfig `yaml:"adaptive_polling"`
+	// StoryType 仅 hackernews 类型数据源使用，取值 "top"/"new"/"best"，为空默认 "top"
+	StoryType string `yaml:"story_type"`
+	// MinScore 仅 hackernews 类型数据源使用，忽略得分低于此值的故事，0 表示不限制
+	MinScore int `yaml:"min_score"`
+	// Mapping 仅 api 类型数据源使用，把任意形状的 JSON 响应映射到 News 字段，
+	// 未配置时沿用内置的 {status, data.list[].{id,title,content}} 默认解析逻辑
+	Mapping *JSONMappingConfig `yaml:"mapping"`
+	// ParserCommand 为空表示不使用外部自定义解析器；非空时优先级最高，忽略 Mapping
+	// 和内置默认解析逻辑，把原始响应体交给这个外部命令（可执行文件或脚本）处理，
+	// 命令在标准输出打印一个 JSON 数组，每个元素形如 {"id","title","content","link","time"}，
+	// 用于内置字段映射仍无法覆盖的畸形/私有格式数据源，不必为此修改代码、重新编译
+	ParserCommand string `yaml:"parser_command"`
+	// ParserTimeout 为 ParserCommand 的执行超时，单位秒，0 表示使用默认值（30 秒）
+	ParserTimeout int `yaml:"parser_timeout"`
+	// Method 仅 api 类型数据源使用，为空默认 "GET"；设为 "POST" 时按 Body 构造请求体，
+	// 用于部分新闻 API 要求以 POST 方式携带查询条件而非拼在 URL 参数里
+	Method string `yaml:"method"`
+	// Body 仅 Method 为 "POST" 时使用，原样作为请求体发送，Content-Type 固定为
+	// "application/json"（若 Headers 里已显式设置 Content-Type 则以 Headers 为准）
+	Body string `yaml:"body"`
+	// Auth 配置后自动获取并按需刷新 OAuth2 客户端凭证令牌，作为 Bearer token 附加到
+	// 每次请求，用于 Reddit、部分 Twitter 兼容 API 等要求认证的数据源，无需手动轮换令牌
+	Auth *SourceAuthConfig `yaml:"auth"`
+	// ContentCleanup 配置后在翻译/AI分析之前对该数据源产出的正文做规则化清洗，
+	// 去除转载版权声明、"The post X appeared first on Y" 之类的固定尾巴，
+	// 减少喂给下游的噪声、降低 AI token 开销
+	ContentCleanup *ContentCleanupConfig `yaml:"content_cleanup"`
+	// FetchDailyBudget 限制该数据源每天最多实际发起多少次抓取请求，用于在
+	// PollInterval 之外再兜底一层总量上限；达到上限后当天剩余轮次直接跳过，
+	// 不消耗熔断/限速的计数；0 表示不限额
+	FetchDailyBudget int `yaml:"fetch_daily_budget"`
+	// FetchFullContent 开启后在返回抓取结果前额外请求每条新闻的 Link 页面，用简化的
+	// 可读性提取算法覆盖 OriginalContent，弥补 RSS/API 摘要经常被截断、影响翻译和
+	// AI 分析质量的问题；单条提取失败只记录日志，不影响其余条目，原有摘要保留
+	FetchFullContent bool `yaml:"fetch_full_content"`
+	// WebSub 仅对 RSS 源生效：为 true 且顶层 sources.websub 已开启时，会尝试从该
+	// feed 发现 WebSub hub 并订阅推送；未声明 hub 或订阅失败时自动回退为轮询，
+	// 不影响其余数据源
+	WebSub bool `yaml:"websub"`
+	// QualityGate 在翻译/AI分析之前过滤掉正文过短、缺少链接的占位条目，
+	// 避免它们消耗后续处理额度、污染频道；未配置或未开启时不做任何过滤
+	QualityGate *QualityGateConfig `yaml:"quality_gate"`
+	// CookieJar 为 true 时，该数据源使用持久化到磁盘的 Cookie Jar（需要顶层
+	// Fetcher.EnableCookieJar 已开启），自动保存服务端下发的 Set-Cookie 并在
+	// 后续请求携带，进程重启后无需重新登录；仅静态附带 Cookies 字段时无需开启此项
+	CookieJar bool `yaml:"cookie_jar"`
+	// Login 配置后会在该数据源第一次 Fetch 之前，以表单方式向 URL 发起一次 POST
+	// 登录请求，产生的会话 Cookie 由 CookieJar 保存，用于登录后才能访问正文的数据源；
+	// 未开启 CookieJar 时登录产生的 Cookie 不会被保留，下次请求仍是匿名状态
+	Login *LoginConfig `yaml:"login"`
+}
+
+// LoginConfig 描述数据源在开始抓取前需要先执行的登录步骤
+type LoginConfig struct {
+	URL    string `yaml:"url"`
+	Method string `yaml:"method"` // 为空默认 "POST"
+	// Fields 以 application/x-www-form-urlencoded 形式提交，键为表单字段名
+	Fields map[string]string `yaml:"fields"`
+}
+
+// QualityGateConfig 是数据源级别的内容质量门槛：正文过短或缺少链接的占位条目
+// 会在进入翻译/AI分析之前被丢弃，各数据源类型（api/rss/hackernews/mock）通用
+type QualityGateConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// MinContentLength 单位字符数，原文长度（去除首尾空白后）低于此值视为空洞
+	// 内容予以丢弃，0 表示不限制
+	MinContentLength int `yaml:"min_content_length"`
+	// RequireLink 为 true 时，缺少 Link 字段（无法追溯原文）的条目直接丢弃
+	RequireLink bool `yaml:"require_link"`
+}
+
+// ContentCleanupConfig 描述一个数据源的正文清洗规则
+type ContentCleanupConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// StripPatterns 为一组正则表达式，命中的片段从正文中整体删除，用于版权声明、
+	// 页脚、"The post .* appeared first on .*" 这类可以用正则覆盖的固定尾巴
+	StripPatterns []string `yaml:"strip_patterns"`
+	// CollapseWhitespace 为 true 时把连续的空白（含换行）压缩成单个空格，
+	// 用于清理转载时残留的排版空行
+	CollapseWhitespace bool `yaml:"collapse_whitespace"`
+}
+
+// SourceAuthConfig 描述数据源的 OAuth2 客户端凭证认证，令牌由 golang.org/x/oauth2
+// 的 clientcredentials 包按需获取并在过期前自动刷新，调用方无需感知刷新时机
+type SourceAuthConfig struct {
+	TokenURL     string   `yaml:"token_url"`
+	ClientID     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"`
+	Scopes       []string `yaml:"scopes"`
+}
+
+// JSONMappingConfig 描述如何从任意 JSON API 响应中取出条目列表和每个条目的字段，
+// 路径均为点号分隔的字段名（如 "data.list"、"attributes.title"），不支持数组下标，
+// 因为 ListPath 本身已经定位到数组，其余路径都是相对数组内每个元素的
+type JSONMappingConfig struct {
+	ListPath    string `yaml:"list_path"`    // 定位条目数组的路径，为空默认 "data.list"
+	IDPath      string `yaml:"id_path"`      // 为空默认 "id"
+	TitlePath   string `yaml:"title_path"`   // 为空默认 "title"
+	ContentPath string `yaml:"content_path"` // 为空默认 "content"
+	LinkPath    string `yaml:"link_path"`    // 为空默认 "link"
+	// TimePath 为空表示不解析发布时间，条目一律以抓取时刻作为 CreateTime；
+	// 取到的值为字符串时按 RFC3339 解析，为数字时按 Unix 秒时间戳解析
+	TimePath string `yaml:"time_path"`
+}
+
+// AdaptivePollingConfig 让数据源的实际轮询间隔随其产出新条目的频率自动伸缩：
+// 长期没有新条目就逐步拉长间隔以减少无意义的请求，一旦重新出新条目就逐步收紧
+// 回更短的间隔以保持时效性，全程被限制在 [MinSeconds, MaxSeconds] 范围内
+type AdaptivePollingConfig struct {
+	Enabled    bool `yaml:"enabled"`
+	MinSeconds int  `yaml:"min_seconds"` // 有效轮询间隔下限（秒），0 表示不设下限
+	MaxSeconds int  `yaml:"max_seconds"` // 有效轮询间隔上限（秒），0 表示不设上限
+	// StepFactor 每轮判定后按此倍数调整有效间隔：没有新条目时间隔乘以该倍数，
+	// 出新条目时间隔除以该倍数，必须 > 1，未配置或 <= 1 时使用默认值 1.5
+	StepFactor float64 `yaml:"step_factor"`
+}
+
+// ScheduleWindowConfig 数据源的轮询时间窗口，用于股票行情等只在特定时段（如
+// 工作日开盘时间）才有更新意义的内容，窗口外跳过轮询以节省请求、减少非交易
+// 时段的无意义噪音
+type ScheduleWindowConfig struct {
+	Days      []string `yaml:"days"`         // 允许轮询的星期几，如 ["mon","tue","wed","thu","fri"]，为空表示每天都允许
+	StartHour int      `yaml:"start_hour"`   // 窗口开始时间，0-23，本地时间
+	StartMin  int      `yaml:"start_minute"` // 0-59
+	EndHour   int      `yaml:"end_hour"`     // 窗口结束时间（不含），0-23
+	EndMin    int      `yaml:"end_minute"`   // 0-59
+	// Timezone 为空时使用系统本地时区；StartHour/StartMin 均为 0 且 EndHour/EndMin
+	// 均为 0 时视为未设置起止时间，仅按 Days 过滤（如有）
+	Timezone string `yaml:"timezone"`
+}
+
+// BasicAuthConfig 单个数据源的 HTTP Basic Auth 凭据
+type BasicAuthConfig struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// BackfillConfig 数据源首次接入时的历史补发策略：避免既没有游标记录时把 feed
+// 当前的全部条目一次性推送刷屏，也避免因为没有游标而悄悄丢弃这些历史条目。
+// 按时间升序逐批放行，每轮抓取受 MaxItems/MaxAge 限制，游标随已放行的条目推进，
+// 因此某一轮被中断或进程重启后，下一轮会从游标记录的位置继续补发，不会重复也不会漏发
+type BackfillConfig struct {
+	Enabled  bool `yaml:"enabled"`
+	MaxAge   int  `yaml:"max_age"`   // 单位秒，只回补此时长以内的历史条目，0 表示不按时间限制
+	MaxItems int  `yaml:"max_items"` // 每轮抓取最多放行的历史条目数，超出部分留到下一轮，0 表示不限制；SkipSend 为 true 时不生效
+	// SkipSend 为 true 时，新接入数据源首次抓取到的历史条目（按 MaxAge 过滤后）
+	// 只用来把抓取游标推进到最新一条，不会被投递，避免接入存量内容较多的数据源
+	// 时第一轮就把历史内容全部刷屏推送出去；此后的抓取从这个基线之后正常投递
+	SkipSend bool `yaml:"skip_send"`
+}
+
+// TLSConfig 单个数据源的自定义 TLS 配置
+type TLSConfig struct {
+	CAFile             string `yaml:"ca_file"`              // 自定义 CA 证书，用于校验内部/自签名证书
+	CertFile           string `yaml:"cert_file"`            // 客户端证书
+	KeyFile            string `yaml:"key_file"`             // 客户端证书私钥
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"` // 跳过证书校验，仅用于已知有问题的站点
+	MinVersion         string `yaml:"min_version"`          // 如 "1.2"、"1.3"，为空使用 Go 默认值
 }
 
 // TelegramConfig Telegram配置
 type TelegramConfig struct {
-	Enabled  bool         `yaml:"enabled"`
-	Bot      *BotConfig   `yaml:"bot"`
-	Retry    *RetryConfig `yaml:"retry"`
-	Timeout  int          `yaml:"timeout"`
-	ProxyURL string       `yaml:"proxy_url"`
+	Enabled          bool                           `yaml:"enabled"`
+	Bot              *BotConfig                     `yaml:"bot"`
+	Retry            *RetryConfig                   `yaml:"retry"`
+	Timeout          int                            `yaml:"timeout"`
+	ProxyURL         string                         `yaml:"proxy_url"`
+	RateLimit        *RateLimitConfig               `yaml:"rate_limit"`
+	Commands         *CommandsConfig                `yaml:"commands"`
+	TimeFormat       *TimeFormatConfig              `yaml:"time_format"`        // 消息中发布时间的默认展示方式，未配置则用系统本地时区的默认布局
+	ChatTimeFormats  map[string]*TimeFormatConfig   `yaml:"chat_time_formats"`  // 按 chat_id 覆盖默认展示方式，用于服务多个时区的受众
+	HTML             *HTMLConfig                    `yaml:"html"`               // 正文 HTML 转文本的默认处理方式，未配置则按链接地址附在文字后面处理
+	ChatHTML         map[string]*HTMLConfig         `yaml:"chat_html"`          // 按 chat_id 覆盖默认处理方式，如标题类频道用 strip，需要溯源的频道用 footnote
+	Content          *ContentConfig                 `yaml:"content"`            // 消息正文的默认长度与展示方式，未配置则不限长、展示原文摘要+AI分析
+	ChatContent      map[string]*ContentConfig      `yaml:"chat_content"`       // 按 chat_id 覆盖默认展示方式，如headline频道只推标题+链接，精读频道展示完整正文
+	TitleRewrite     *TitleRewriteConfig            `yaml:"title_rewrite"`      // 标题党标题中性化改写的默认设置，未配置则展示原标题
+	ChatTitleRewrite map[string]*TitleRewriteConfig `yaml:"chat_title_rewrite"` // 按 chat_id 覆盖默认设置，如公告频道保留原标题、资讯频道改写
+	Hashtag          *HashtagConfig                 `yaml:"hashtag"`            // 消息末尾附加话题标签的默认设置，未配置则不附加
+	ChatHashtag      map[string]*HashtagConfig      `yaml:"chat_hashtag"`       // 按 chat_id 覆盖默认设置
+	Sampling         *SamplingConfig                `yaml:"sampling"`           // 大频道的低重要性条目抽样投递设置，未配置则不抽样，全部投递
+	DuplicateGuard   *DuplicateGuardConfig          `yaml:"duplicate_guard"`    // 发送前最后一道去重防线，未配置则不做此项检查
+}
+
+// DuplicateGuardConfig 发送前最后一道去重防线：在窗口内记住每个聊天最近发出的
+// 消息内容哈希，拒绝向同一聊天重复发送字节完全相同的消息，用于兜底上游任何
+// 逻辑缺陷（重复抓取、重试未去重等）导致的重复推送，区别于 duplicate_window
+// 按标题指纹识别"同一故事被不同来源转载"，这里只做最朴素的逐字节精确匹配
+type DuplicateGuardConfig struct {
+	Enabled bool `yaml:"enabled"`
+	Window  int  `yaml:"window"` // 单位秒，窗口内向同一聊天发送的字节完全相同的消息会被拒绝
+}
+
+// SamplingConfig 大频道的抽样投递配置：重要性达到阈值的条目总是投递，未达到阈值的
+// 条目按各聊天各自配置的抽样概率随机决定是否投递，用于控制大频道的推送频率，
+// 相比直接按阈值硬性丢弃能保留一部分低重要性内容的曝光
+type SamplingConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// ImportanceThreshold 重要性（|SentimentScore|）达到或超过此值的条目总是投递，
+	// 不参与抽样，默认 0 即所有条目重要性都视为达到阈值（等价于对全部条目抽样）
+	ImportanceThreshold float64 `yaml:"importance_threshold"`
+	// ChatRates 按 chat_id 配置低重要性条目的投递概率，取值范围 [0, 1]，
+	// 未在此列出的聊天不抽样，低重要性条目也照常投递
+	ChatRates map[string]float64 `yaml:"chat_rates"`
+}
+
+// TitleRewriteConfig 标题党标题中性化改写配置：把"震惊！""刷屏了！"之类的夸张标题
+// 改写成客观陈述句展示在消息里，原标题仍然保留在 archive/日志中，不影响去重键
+type TitleRewriteConfig struct {
+	Enabled      bool `yaml:"enabled"`
+	ShowOriginal bool `yaml:"show_original"` // true 时在改写后的标题后用括号附上原标题
+}
+
+// HashtagConfig 消息末尾话题标签配置：从命中的关注名单实体和数据源名称生成
+// "#Bitcoin #SEC" 形式的标签附在消息末尾，便于频道订阅者按话题筛选和搜索
+type HashtagConfig struct {
+	Enabled bool `yaml:"enabled"`
+	MaxTags int  `yaml:"max_tags"` // 单条消息最多附加的标签数，0 表示不限制
+}
+
+// HTMLConfig 正文 HTML 转文本时的处理方式配置
+type HTMLConfig struct {
+	LinkMode string `yaml:"link_mode"` // "inline"（文字后附地址，默认）、"footnote"（正文末尾编号列出）或 "strip"（只保留文字）
+}
+
+// ContentConfig 消息正文的长度与展示方式配置：标题类频道和深度阅读频道对正文的
+// 需求不同，前者只想要标题+链接，后者想要完整正文
+type ContentConfig struct {
+	MaxLength int    `yaml:"max_length"` // 单位字符数，正文摘要超过此长度时按句子边界截断，0 表示不限制
+	Mode      string `yaml:"mode"`       // "full"（原文摘要+AI分析，默认）、"summary_only"（仅展示 AI 摘要）、"title_link"（仅标题+链接）或 "bilingual"（原文标题/译文标题及正文并排展示，供中英文混合受众频道使用）
+}
+
+// TimeFormatConfig 消息中发布时间的展示方式配置
+type TimeFormatConfig struct {
+	Timezone string `yaml:"timezone"` // IANA 时区名，如 "America/New_York"，为空使用系统本地时区
+	Layout   string `yaml:"layout"`   // Go 时间格式布局，为空默认 "2006-01-02 15:04"
+	Relative bool   `yaml:"relative"` // true 时显示相对时间（如 "5分钟前"），优先于 layout/timezone
+}
+
+// CommandsConfig 交互式 Bot 命令的访问控制配置
+type CommandsConfig struct {
+	Enabled  bool    `yaml:"enabled"`
+	AdminIDs []int64 `yaml:"admin_ids"` // 允许执行管理类命令（如 /subscribe、/mute）的 Telegram 用户ID白名单
+}
+
+// RateLimitConfig 限速配置
+type RateLimitConfig struct {
+	PerSecond float64 `yaml:"per_second"` // 每秒最多发送的消息数
+	Burst     int     `yaml:"burst"`      // 允许的突发数量
 }
 
 // BotConfig 机器人配置
@@ -49,13 +716,17 @@ type BotConfig struct {
 
 // AIConfig AI配置
 type AIConfig struct {
-	Enabled  bool         `yaml:"enabled"`
-	Provider string       `yaml:"provider"`
-	Model    string       `yaml:"model"`
-	APIKey   string       `yaml:"api_key"`
-	Params   *AIParams    `yaml:"params"`
-	Timeout  int          `yaml:"timeout"`
-	Retry    *RetryConfig `yaml:"retry"`
+	Enabled     bool         `yaml:"enabled"`
+	Provider    string       `yaml:"provider"`
+	Model       string       `yaml:"model"`
+	APIKey      string       `yaml:"api_key"`
+	Params      *AIParams    `yaml:"params"`
+	Timeout     int          `yaml:"timeout"`
+	Retry       *RetryConfig `yaml:"retry"`
+	RetryMaxAge int          `yaml:"retry_max_age"` // 单位秒，分析失败的条目在此时长内会在后续抓取周期重新尝试分析，0 表示不跨周期重试
+	// DailyBudget 限制每天最多发起多少次 AI 请求，避免异常重试或数据源激增导致当天账单
+	// 意外飙升，达到上限后当天剩余条目跳过分析、直接发送原文；0 表示不限额
+	DailyBudget int `yaml:"daily_budget"`
 }
 
 // AIParams AI参数
@@ -72,8 +743,83 @@ type CacheConfig struct {
 
 // AppConfig 应用配置
 type AppConfig struct {
-	FetchInterval int    `yaml:"fetch_interval"`
-	LogLevel      string `yaml:"log_level"`
+	FetchInterval    int                     `yaml:"fetch_interval"`
+	LogLevel         string                  `yaml:"log_level"`
+	DebugDump        bool                    `yaml:"debug_dump"`        // 是否在日志中输出原始响应体/消息全文，默认关闭以避免正文泄露
+	DumpMaxSize      int                     `yaml:"dump_max_size"`     // debug_dump 开启时单条日志的最大字节数，0 使用默认值
+	Timeouts         *StageTimeoutsConfig    `yaml:"timeouts"`          // 单轮处理各阶段的超时与整体截止时间，未配置则不限制
+	Concurrency      *CycleConcurrencyConfig `yaml:"concurrency"`       // 单轮处理耗时超过抓取间隔时的调度策略，未配置则维持默认的 queue-one 行为
+	CycleSummary     *CycleSummaryConfig     `yaml:"cycle_summary"`     // 每轮处理结束后向管理聊天推送的简要统计，未配置则不推送
+	AnomalyDetection *AnomalyDetectionConfig `yaml:"anomaly_detection"` // 数据源单轮抓取量异常偏高时的告警配置，未配置则不检测
+	DeliveryQueue    *DeliveryQueueConfig    `yaml:"delivery_queue"`    // AI 分析与发送阶段之间的优先级队列，未配置则维持逐条按到达顺序直接发送
+}
+
+// DeliveryQueueConfig AI 分析和发送阶段之间的优先级队列配置：开启后，条目按
+// |SentimentScore| 排序，只有本轮预算 MaxPerCycle 内的条目会被发送，其余留到
+// 下一轮；每留一轮，有效优先级按 AgingBoost 提高一次，避免持续涌入的高重要性
+// 条目让低重要性条目永远排不上号（starvation）。开启此队列后 app.ordering 的
+// 简单重排不再生效，由队列的弹出顺序统一决定投递顺序
+type DeliveryQueueConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// MaxPerCycle 每轮最多从队列中弹出多少条交给发送阶段，<=0 表示不限（等价于一次性发完，
+	// 只按重要性重排顺序，不产生跨轮排队）
+	MaxPerCycle int `yaml:"max_per_cycle"`
+	// AgingBoost 条目每多等待一轮，有效优先级增加的量，<=0 时使用默认值 0.1
+	AgingBoost float64 `yaml:"aging_boost"`
+}
+
+// AnomalyDetectionConfig 数据源抓取量异常检测配置：为每个数据源维护一个近期
+// 抓取量基线，某一轮的抓取量明显超过基线（改版/被刷量/解析器 bug 等）时向管理
+// 聊天告警，AutoThrottle 开启时同时自动静音该数据源直到管理员执行 /unmute 确认
+type AnomalyDetectionConfig struct {
+	Enabled      bool    `yaml:"enabled"`
+	ChatID       string  `yaml:"chat_id"`       // 告警推送目标聊天，为空则使用 telegram.bot.chat_ids
+	Multiplier   float64 `yaml:"multiplier"`    // 单轮抓取量超过基线的这个倍数视为异常，<=0 时默认 10
+	AutoThrottle bool    `yaml:"auto_throttle"` // true 时告警的同时自动静音该数据源，需要同时开启 mute.enabled
+}
+
+// CycleSummaryConfig 每轮处理结束后的摘要推送配置：向管理聊天汇报本轮抓取、新增、
+// 发送、过滤、失败的条目数，OnlyNotable 开启时只在出现失败或抓取量明显偏高时才
+// 推送，避免正常运行时每轮都刷屏
+type CycleSummaryConfig struct {
+	Enabled      bool    `yaml:"enabled"`
+	ChatID       string  `yaml:"chat_id"`       // 推送目标聊天，为空则使用 telegram.bot.chat_ids
+	OnlyNotable  bool    `yaml:"only_notable"`  // true 时只在本轮出现失败或抓取量异常偏高时才推送
+	VolumeFactor float64 `yaml:"volume_factor"` // 本轮抓取量超过近期平均值的这个倍数视为异常偏高，<=0 时默认 3
+}
+
+// CycleConcurrencyConfig 单轮处理（一次 processNews）耗时超过抓取间隔时的
+// 调度策略：默认的定时器行为是最多排队紧跟着开始下一轮，这里给出显式可配置的策略
+type CycleConcurrencyConfig struct {
+	// Policy 为 "skip"（丢弃本该紧跟开始的这一轮，严格按间隔等到下一次 tick）、
+	// "queue-one"（默认，维持 time.Ticker 原有行为：耗时超过间隔时下一轮立即紧跟开始）
+	// 或 "run-concurrent-with-limit"（允许多轮并发执行，受 MaxConcurrent 限制）
+	Policy        string `yaml:"policy"`
+	MaxConcurrent int    `yaml:"max_concurrent"` // policy 为 run-concurrent-with-limit 时最多允许同时运行的轮次数，默认 2
+}
+
+// StageTimeoutsConfig 单轮处理各阶段的超时时间（单位秒，0 表示不限制），
+// 防止某一篇文章或某个卡住的上游服务把整轮处理无限期拖住、与下一次定时抓取重叠
+type StageTimeoutsConfig struct {
+	Cycle   int `yaml:"cycle"`   // 整轮处理（抓取+翻译+分析+发送）的总截止时间
+	Fetch   int `yaml:"fetch"`   // 抓取阶段
+	Analyze int `yaml:"analyze"` // AI 分析阶段
+	Send    int `yaml:"send"`    // 发送阶段（含 webhook 推送）
+}
+
+// WebhookConfig Webhook配置
+type WebhookConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	URL     string `yaml:"url"`
+	// Format 决定负载格式："ifttt"（value1/value2/value3 扁平结构）、"zapier"（通用 JSON 结构）
+	// 或 "schema"（携带 schema_version 的完整 News JSON，见 models.NewsPayload）
+	Format  string       `yaml:"format"`
+	Timeout int          `yaml:"timeout"`
+	Retry   *RetryConfig `yaml:"retry"`
+	// SchemaVersion 仅在 Format 为 "schema" 时生效，指定发送给该 Webhook 的 schema
+	// 版本号；0 或等于 models.CurrentSchemaVersion 时发送最新字段集，小于当前版本
+	// 时自动降级为对应的兼容负载，供尚未升级的老消费方继续按原字段集解析
+	SchemaVersion int `yaml:"schema_version"`
 }
 
 // RetryConfig 重试配置
@@ -92,6 +838,9 @@ type TranslatorConfig struct {
 	TargetLanguage string `yaml:"target_language"`
 	Timeout        int    `yaml:"timeout"`
 	ProxyURL       string `yaml:"proxy_url"`
+	// DailyBudget 限制每天最多发起多少次翻译请求，达到上限后当天剩余内容跳过翻译、
+	// 保留原文；0 表示不限额
+	DailyBudget int `yaml:"daily_budget"`
 }
 
 // LoadConfig 从文件加载配置
@@ -113,38 +862,57 @@ func LoadConfig(filename string) (*Config, error) {
 	return &cfg, nil
 }
 
-// validateConfig 验证配置
+// validateConfig 验证配置。配置了 profiles 时改为逐个校验每个 profile，
+// 顶层的 sources/telegram 等字段此时不再要求填写
 func validateConfig(cfg *Config) error {
-	if cfg.Sources == nil || (len(cfg.Sources.API) == 0 && len(cfg.Sources.RSS) == 0) {
+	if len(cfg.Profiles) > 0 {
+		for i, p := range cfg.Profiles {
+			if p.Name == "" {
+				return fmt.Errorf("第%d个profile未配置name", i+1)
+			}
+			if err := validateTenant(p.Sources, p.Telegram, p.AI, p.App); err != nil {
+				return fmt.Errorf("profile %s: %v", p.Name, err)
+			}
+		}
+		return nil
+	}
+
+	return validateTenant(cfg.Sources, cfg.Telegram, cfg.AI, cfg.App)
+}
+
+// validateTenant 校验单个租户（顶层配置或某个 profile）必须具备的字段，
+// 被 validateConfig 在单租户和多租户两种模式下复用
+func validateTenant(sources *SourcesConfig, telegram *TelegramConfig, ai *AIConfig, app *AppConfig) error {
+	if sources == nil || (len(sources.API) == 0 && len(sources.RSS) == 0 && len(sources.HackerNews) == 0) {
 		return fmt.Errorf("未配置数据源")
 	}
 
-	if cfg.Telegram == nil || cfg.Telegram.Bot == nil {
+	if telegram == nil || telegram.Bot == nil {
 		return fmt.Errorf("未配置Telegram")
 	}
 
-	if cfg.Telegram.Bot.Token == "" {
+	if telegram.Bot.Token == "" {
 		return fmt.Errorf("未配置Telegram机器人令牌")
 	}
 
-	if len(cfg.Telegram.Bot.ChatIDs) == 0 {
+	if len(telegram.Bot.ChatIDs) == 0 {
 		return fmt.Errorf("未配置Telegram聊天ID")
 	}
 
-	if cfg.AI != nil && cfg.AI.Enabled {
-		if cfg.AI.APIKey == "" {
+	if ai != nil && ai.Enabled {
+		if ai.APIKey == "" {
 			return fmt.Errorf("未配置AI API密钥")
 		}
-		if cfg.AI.Model == "" {
+		if ai.Model == "" {
 			return fmt.Errorf("未配置AI模型")
 		}
 	}
 
-	if cfg.App == nil {
+	if app == nil {
 		return fmt.Errorf("未配置应用参数")
 	}
 
-	if cfg.App.FetchInterval <= 0 {
+	if app.FetchInterval <= 0 {
 		return fmt.Errorf("抓取间隔必须大于0")
 	}
 
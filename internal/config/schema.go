@@ -0,0 +1,81 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// jsonSchema 是一段 JSON Schema 片段，直接用 map 表示以便原样 json.Marshal，
+// 不需要为 JSON Schema 本身的语法额外定义一套结构体
+type jsonSchema map[string]interface{}
+
+// Schema 基于 Config 结构体的字段和 yaml 标签生成一份 JSON Schema（Draft-07），
+// 供 YAML 编辑器（如 VS Code 的 YAML 插件，通过 "# yaml-language-server: $schema=..."
+// 注释或工作区设置关联）据此校验 config.yaml 并提供字段自动补全，
+// 字段说明沿用各结构体自身的 Go doc 注释，此处不重复维护一份
+func Schema() jsonSchema {
+	schema := typeSchema(reflect.TypeOf(Config{}))
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = "news-fetcher 配置"
+	return schema
+}
+
+// typeSchema 递归地把一个 Go 类型转换为对应的 JSON Schema 片段
+func typeSchema(t reflect.Type) jsonSchema {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return typeSchema(t.Elem())
+	case reflect.Struct:
+		return structSchema(t)
+	case reflect.Slice, reflect.Array:
+		return jsonSchema{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		// 配置里的 map 字段（如 SourceConfig.Params、Headers、Cookies）键均为运行时
+		// 动态确定，没有固定的属性集合可供枚举，只能声明为通用 object
+		return jsonSchema{"type": "object"}
+	case reflect.Bool:
+		return jsonSchema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return jsonSchema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return jsonSchema{"type": "number"}
+	case reflect.String:
+		return jsonSchema{"type": "string"}
+	default:
+		// interface{}（如 SourceConfig.Params 的值类型）等无法归类的类型不作约束
+		return jsonSchema{}
+	}
+}
+
+// structSchema 把一个结构体类型转换为 JSON Schema 的 object 节点，字段名取自
+// yaml 标签而不是 Go 字段名，与 config.yaml 实际的键保持一致
+func structSchema(t reflect.Type) jsonSchema {
+	properties := jsonSchema{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := yamlFieldName(field)
+		if name == "" {
+			continue
+		}
+		properties[name] = typeSchema(field.Type)
+	}
+	return jsonSchema{
+		"type":                 "object",
+		"additionalProperties": false,
+		"properties":           properties,
+	}
+}
+
+// yamlFieldName 从字段的 yaml 标签中取出字段名，未打标签或显式忽略（"-"）时返回空串
+func yamlFieldName(field reflect.StructField) string {
+	tag := field.Tag.Get("yaml")
+	if tag == "" {
+		return ""
+	}
+	name := strings.Split(tag, ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,56 @@
+// Package contentcleanup 在翻译/AI分析之前按数据源各自配置的规则清洗正文：
+// 去除转载版权声明、"The post X appeared first on Y" 之类的固定尾巴、压缩
+// 排版空白，减少喂给下游的噪声、降低 AI token 开销
+package contentcleanup
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/amylixing/news-fetcher/internal/config"
+)
+
+// Cleaner 持有一个数据源编译好的清洗规则
+type Cleaner struct {
+	patterns           []*regexp.Regexp
+	collapseWhitespace bool
+}
+
+// New 按配置编译清洗规则，StripPatterns 中出现无法编译的正则时直接返回错误，
+// 而不是悄悄跳过某条规则
+func New(cfg *config.ContentCleanupConfig) (*Cleaner, error) {
+	if cfg == nil || !cfg.Enabled {
+		return nil, nil
+	}
+
+	patterns := make([]*regexp.Regexp, 0, len(cfg.StripPatterns))
+	for _, p := range cfg.StripPatterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("编译清洗正则 %q 失败: %v", p, err)
+		}
+		patterns = append(patterns, re)
+	}
+
+	return &Cleaner{patterns: patterns, collapseWhitespace: cfg.CollapseWhitespace}, nil
+}
+
+var whitespaceRun = regexp.MustCompile(`\s+`)
+
+// Clean 依次应用 StripPatterns 删除命中片段，再按需压缩连续空白
+func (c *Cleaner) Clean(content string) string {
+	if c == nil || content == "" {
+		return content
+	}
+
+	for _, re := range c.patterns {
+		content = re.ReplaceAllString(content, "")
+	}
+
+	if c.collapseWhitespace {
+		content = whitespaceRun.ReplaceAllString(content, " ")
+	}
+
+	return strings.TrimSpace(content)
+}
@@ -0,0 +1,135 @@
+// Package cookiejar 为需要登录态才能访问的数据源提供一个可持久化到磁盘的
+// http.CookieJar：按数据源（以 URL 为键）分区保存各自的 Cookie，整体持久化
+// 到一个 JSON 文件，与 sourcestate.Store 是同样的按数据源分区、单文件持久化
+// 思路，进程重启后无需重新执行 login 步骤，沿用上次保存的会话 Cookie 直到
+// 服务端使其过期
+package cookiejar
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// cookieEntry 是单个 Cookie 的可持久化形式
+type cookieEntry struct {
+	Name    string    `json:"name"`
+	Value   string    `json:"value"`
+	Domain  string    `json:"domain"`
+	Path    string    `json:"path"`
+	Expires time.Time `json:"expires"` // 零值表示会话 Cookie，不受过期时间限制
+}
+
+// Store 按数据源分区保存各自的 Cookie，整体持久化到一个 JSON 文件
+type Store struct {
+	mu   sync.Mutex
+	path string
+	data map[string][]cookieEntry
+}
+
+// NewStore 创建一个 Cookie 存储，文件不存在时视为空
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path, data: make(map[string][]cookieEntry)}
+
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取 Cookie 存储文件失败: %v", err)
+	}
+	if err := json.Unmarshal(raw, &s.data); err != nil {
+		return nil, fmt.Errorf("解析 Cookie 存储文件失败: %v", err)
+	}
+	return s, nil
+}
+
+// For 返回绑定到某个数据源的 http.CookieJar 视图，供 http.Client.Jar 直接使用
+func (s *Store) For(source string) *Jar {
+	return &Jar{store: s, source: source}
+}
+
+func (s *Store) cookies(source string) []cookieEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]cookieEntry(nil), s.data[source]...)
+}
+
+// setCookies 用 fresh 覆盖 source 下同名的 Cookie（同名视为更新），并立即持久化；
+// 持久化失败只记录日志，因为 http.CookieJar 接口的 SetCookies 方法本身不允许返回错误
+func (s *Store) setCookies(source string, fresh []cookieEntry) {
+	s.mu.Lock()
+	existing := s.data[source]
+	for _, c := range fresh {
+		replaced := false
+		for i, old := range existing {
+			if old.Name == c.Name && old.Domain == c.Domain {
+				existing[i] = c
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			existing = append(existing, c)
+		}
+	}
+	s.data[source] = existing
+	raw, err := json.MarshalIndent(s.data, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		log.Printf("序列化 Cookie 存储失败: %v", err)
+		return
+	}
+	if err := os.WriteFile(s.path, raw, 0644); err != nil {
+		log.Printf("写入 Cookie 存储文件失败: %v", err)
+	}
+}
+
+// Jar 是绑定到单个数据源的 http.CookieJar 实现
+type Jar struct {
+	store  *Store
+	source string
+}
+
+// SetCookies 实现 http.CookieJar，登录响应或后续请求下发的 Set-Cookie 都会
+// 经此写入，立即落盘
+func (j *Jar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	entries := make([]cookieEntry, 0, len(cookies))
+	for _, c := range cookies {
+		domain := c.Domain
+		if domain == "" {
+			domain = u.Host
+		}
+		entries = append(entries, cookieEntry{
+			Name:    c.Name,
+			Value:   c.Value,
+			Domain:  domain,
+			Path:    c.Path,
+			Expires: c.Expires,
+		})
+	}
+	j.store.setCookies(j.source, entries)
+}
+
+// Cookies 实现 http.CookieJar，跳过已经过期的 Cookie；不做 RFC 6265 完整的
+// domain/path 匹配，按数据源单一站点的场景只要求 Host 一致即可，足够覆盖
+// "登录后访问同一站点其余接口" 的常见用法
+func (j *Jar) Cookies(u *url.URL) []*http.Cookie {
+	var result []*http.Cookie
+	now := time.Now()
+	for _, e := range j.store.cookies(j.source) {
+		if !e.Expires.IsZero() && e.Expires.Before(now) {
+			continue
+		}
+		if e.Domain != "" && e.Domain != u.Host {
+			continue
+		}
+		result = append(result, &http.Cookie{Name: e.Name, Value: e.Value})
+	}
+	return result
+}
@@ -0,0 +1,65 @@
+// Package cursor 持久化每个数据源最新已见条目的发布时间与ID，用于在下一次
+// 抓取周期或进程重启后跳过更旧的条目，独立于带 TTL 的去重缓存——即使缓存被清空，
+// 抓取游标依然保证不会把旧条目重新当作新闻处理
+package cursor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Entry 记录一个数据源目前已见过的最新条目
+type Entry struct {
+	ID   string    `json:"id"`
+	Time time.Time `json:"time"`
+}
+
+// Store 把每个数据源（以 URL 为键）的游标保存到一个 JSON 文件
+type Store struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]Entry
+}
+
+// NewStore 创建一个游标存储，文件不存在时视为空
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path, entries: make(map[string]Entry)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取抓取游标文件失败: %v", err)
+	}
+	if err := json.Unmarshal(data, &s.entries); err != nil {
+		return nil, fmt.Errorf("解析抓取游标文件失败: %v", err)
+	}
+	return s, nil
+}
+
+// Get 返回某个数据源当前的游标，不存在时返回零值和 false
+func (s *Store) Get(key string) (Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	return e, ok
+}
+
+// Update 更新某个数据源的游标并立即持久化
+func (s *Store) Update(key string, e Entry) error {
+	s.mu.Lock()
+	s.entries[key] = e
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("序列化抓取游标失败: %v", err)
+	}
+	if err := os.WriteFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("写入抓取游标文件失败: %v", err)
+	}
+	return nil
+}
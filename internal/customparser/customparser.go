@@ -0,0 +1,63 @@
+// Package customparser 为内置 JSON 字段映射仍无法覆盖的畸形/私有格式数据源提供一个
+// 外部命令扩展点：把原始响应体交给用户自己提供的可执行文件或脚本处理，而不必为每一个
+// 这样的数据源修改代码、重新编译。仓库里没有引入嵌入式脚本引擎（如 starlark），沿用
+// internal/ocr 已经确立的"调用本机可执行文件"的做法，保持依赖和实现方式一致
+package customparser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// Item 是外部解析命令通过标准输出打印的单条条目，字段命名对齐 News 的核心字段，
+// 由调用方（各数据源的 Fetch）转换为 models.News
+type Item struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
+	Link    string `json:"link"`
+	// Time 为 RFC3339 格式的字符串，留空则由调用方回退为抓取时刻
+	Time string `json:"time"`
+}
+
+// Parser 通过外部命令把原始响应体转换为条目列表
+type Parser struct {
+	command string
+	timeout time.Duration
+}
+
+// New 创建一个自定义解析器，command 为可执行文件名或脚本路径，timeout<=0 时默认 30 秒
+func New(command string, timeout time.Duration) *Parser {
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &Parser{command: command, timeout: timeout}
+}
+
+// Parse 把 body 写入外部命令的标准输入，命令需要在标准输出打印一个 JSON 数组的 Item；
+// 命令的标准错误输出会拼接进返回的错误信息，便于排查脚本本身的问题
+func (p *Parser) Parse(ctx context.Context, body []byte) ([]Item, error) {
+	runCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(runCtx, p.command)
+	cmd.Stdin = bytes.NewReader(body)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("执行自定义解析命令失败: %v: %s", err, stderr.String())
+	}
+
+	var items []Item
+	if err := json.Unmarshal(stdout.Bytes(), &items); err != nil {
+		return nil, fmt.Errorf("解析自定义解析命令输出失败: %v", err)
+	}
+	return items, nil
+}
@@ -0,0 +1,112 @@
+// Package deadletter 记录一条新闻在本轮所有目标聊天都投递失败的详情，供人工
+// 排查问题后通过 CLI 子命令或机器人命令手动重新投递，而不是任其在缓存未标记
+// 成功的情况下静默等待下一轮抓取周期偶然重试
+package deadletter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/amylixing/news-fetcher/internal/models"
+)
+
+// Item 记录一条投递失败的新闻及失败详情
+type Item struct {
+	News     *models.News `json:"news"`
+	ChatIDs  []string     `json:"chat_ids"` // 投递失败的目标聊天
+	Error    string       `json:"error"`
+	FailedAt time.Time    `json:"failed_at"`
+	// EscalationPath 记录曾经尝试过的升级通道类型，按尝试顺序排列，为空表示
+	// 未触发升级链（未开启 escalation 或该条目未达到严重程度阈值）
+	EscalationPath []string `json:"escalation_path,omitempty"`
+}
+
+// Store 把投递失败的新闻条目（以 "source:id" 为键）保存到一个 JSON 文件
+type Store struct {
+	mu    sync.Mutex
+	path  string
+	items map[string]Item
+}
+
+// NewStore 创建一个死信存储，文件不存在时视为空
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path, items: make(map[string]Item)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取死信文件失败: %v", err)
+	}
+	if err := json.Unmarshal(data, &s.items); err != nil {
+		return nil, fmt.Errorf("解析死信文件失败: %v", err)
+	}
+	return s, nil
+}
+
+// Key 生成一条新闻的死信键
+func Key(source, id string) string {
+	return source + ":" + id
+}
+
+// Add 记录一条投递失败的新闻并立即持久化
+func (s *Store) Add(key string, item Item) error {
+	s.mu.Lock()
+	s.items[key] = item
+	err := s.save()
+	s.mu.Unlock()
+	return err
+}
+
+// Get 返回某条死信记录，不存在时返回零值和 false
+func (s *Store) Get(key string) (Item, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, ok := s.items[key]
+	return item, ok
+}
+
+// Remove 从死信存储里移除一条记录并立即持久化，用于重新投递成功或人工放弃后清理
+func (s *Store) Remove(key string) error {
+	s.mu.Lock()
+	delete(s.items, key)
+	err := s.save()
+	s.mu.Unlock()
+	return err
+}
+
+// List 返回当前所有死信记录，键为 "source:id"
+func (s *Store) List() map[string]Item {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]Item, len(s.items))
+	for k, v := range s.items {
+		out[k] = v
+	}
+	return out
+}
+
+// Purge 清空所有死信记录并立即持久化
+func (s *Store) Purge() error {
+	s.mu.Lock()
+	s.items = make(map[string]Item)
+	err := s.save()
+	s.mu.Unlock()
+	return err
+}
+
+// save 假定调用方已持有锁
+func (s *Store) save() error {
+	data, err := json.MarshalIndent(s.items, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化死信记录失败: %v", err)
+	}
+	if err := os.WriteFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("写入死信文件失败: %v", err)
+	}
+	return nil
+}
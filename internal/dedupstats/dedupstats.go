@@ -0,0 +1,91 @@
+// Package dedupstats 按天统计各个去重环节淘汰掉的条目数量，用于生成调优报告：
+// 帮助判断某个环节的阈值是设得太松（几乎没拦到重复）还是太紧（淘汰量异常偏高，
+// 可能存在误伤），不必只凭感觉调参数
+package dedupstats
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultKeepDays 是未指定保留天数时的默认值，足够覆盖一到两周的观察窗口，
+// 又不至于让长期运行的进程无限积累历史数据
+const defaultKeepDays = 14
+
+// Recorder 按去重环节名称（layer）分别计数，每天自动归入独立的桶，只保留
+// 最近 keepDays 天的记录
+type Recorder struct {
+	mu       sync.Mutex
+	keepDays int
+	days     map[string]map[string]int // day -> layer -> count
+}
+
+// New 创建一个计数器，keepDays<=0 时使用默认保留天数
+func New(keepDays int) *Recorder {
+	if keepDays <= 0 {
+		keepDays = defaultKeepDays
+	}
+	return &Recorder{keepDays: keepDays, days: make(map[string]map[string]int)}
+}
+
+func today() string {
+	return time.Now().Format("2006-01-02")
+}
+
+// Record 记录 layer 这个去重环节今天又淘汰了一条条目
+func (r *Recorder) Record(layer string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	d := today()
+	if r.days[d] == nil {
+		r.days[d] = make(map[string]int)
+	}
+	r.days[d][layer]++
+	r.prune()
+}
+
+// prune 假定调用方已持有锁，只保留最近 keepDays 天的记录，避免长期运行的
+// 进程内存无限增长
+func (r *Recorder) prune() {
+	if len(r.days) <= r.keepDays {
+		return
+	}
+	dates := make([]string, 0, len(r.days))
+	for d := range r.days {
+		dates = append(dates, d)
+	}
+	sort.Strings(dates)
+	for _, d := range dates[:len(dates)-r.keepDays] {
+		delete(r.days, d)
+	}
+}
+
+// DaySnapshot 是某一天各去重环节的淘汰计数
+type DaySnapshot struct {
+	Day    string
+	Counts map[string]int
+}
+
+// Report 按日期升序返回当前保留的每日统计快照，供 /dedupstats 一类的调优报告展示趋势
+func (r *Recorder) Report() []DaySnapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	dates := make([]string, 0, len(r.days))
+	for d := range r.days {
+		dates = append(dates, d)
+	}
+	sort.Strings(dates)
+
+	out := make([]DaySnapshot, 0, len(dates))
+	for _, d := range dates {
+		counts := make(map[string]int, len(r.days[d]))
+		for layer, n := range r.days[d] {
+			counts[layer] = n
+		}
+		out = append(out, DaySnapshot{Day: d, Counts: counts})
+	}
+	return out
+}
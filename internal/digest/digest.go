@@ -0,0 +1,77 @@
+// Package digest 支持按类目拆分的摘要推送：把命中的条目累积在各自独立的缓冲区中，
+// 每个类目可以配置自己的汇总周期和推送目标（如监管类新闻每 6 小时汇总一次，
+// 上币类新闻命中后立即推送），区别于全局统一时刻推送的早间简报（briefing）
+package digest
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/amylixing/news-fetcher/internal/config"
+	"github.com/amylixing/news-fetcher/internal/models"
+)
+
+// Schedule 是一个按类目独立缓冲的摘要计划
+type Schedule struct {
+	Config *config.DigestScheduleConfig
+
+	mu    sync.Mutex
+	items []*models.News
+}
+
+// NewSchedule 创建一个摘要计划
+func NewSchedule(cfg *config.DigestScheduleConfig) *Schedule {
+	return &Schedule{Config: cfg}
+}
+
+// Match 判断该条目是否归入本计划：Categories 为空的计划匹配任何条目，可用作
+// 排在最后的兜底默认计划；否则对标题/正文按 Categories 关键词做大小写不敏感的子串匹配
+func (s *Schedule) Match(title, content string) bool {
+	if len(s.Config.Categories) == 0 {
+		return true
+	}
+	haystack := strings.ToLower(title + " " + content)
+	for _, category := range s.Config.Categories {
+		if category != "" && strings.Contains(haystack, strings.ToLower(category)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Add 把条目加入本计划的累积缓冲区
+func (s *Schedule) Add(news *models.News) {
+	s.mu.Lock()
+	s.items = append(s.items, news)
+	s.mu.Unlock()
+}
+
+// Flush 取出并清空当前累积的条目
+func (s *Schedule) Flush() []*models.News {
+	s.mu.Lock()
+	items := s.items
+	s.items = nil
+	s.mu.Unlock()
+	return items
+}
+
+// Router 按注册顺序把条目路由到第一个命中类目的计划
+type Router struct {
+	schedules []*Schedule
+}
+
+// NewRouter 创建路由器，schedules 按顺序匹配，建议把 Categories 为空的兜底计划放在最后
+func NewRouter(schedules []*Schedule) *Router {
+	return &Router{schedules: schedules}
+}
+
+// Route 把条目加入第一个命中的计划并返回该计划，未命中任何计划时返回 nil
+func (r *Router) Route(news *models.News) *Schedule {
+	for _, s := range r.schedules {
+		if s.Match(news.OriginalTitle, news.OriginalContent) {
+			s.Add(news)
+			return s
+		}
+	}
+	return nil
+}
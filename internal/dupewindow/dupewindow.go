@@ -0,0 +1,93 @@
+// Package dupewindow 在一个可配置的时间窗口内识别近似重复的稿件：多个数据源
+// 转载同一条新闻时，标题规范化后指纹相同即视为同一个故事，窗口内的后续来源
+// 不再各自推送一条新消息，而是丢弃或编辑已发送的消息追加来源，避免刷屏
+package dupewindow
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Record 记录一个故事首次发送的时间、已知来源和各聊天对应的消息ID
+type Record struct {
+	FirstSeen time.Time        `json:"first_seen"`
+	Sources   []string         `json:"sources"`
+	Messages  map[string]int64 `json:"messages"` // chatID -> Telegram 消息ID
+}
+
+// Store 把每个故事（以标题指纹为键）的抑制窗口记录保存到一个 JSON 文件
+type Store struct {
+	mu      sync.Mutex
+	path    string
+	records map[string]Record
+}
+
+// NewStore 创建一个重复抑制窗口存储，文件不存在时视为空
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path, records: make(map[string]Record)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取重复抑制窗口文件失败: %v", err)
+	}
+	if err := json.Unmarshal(data, &s.records); err != nil {
+		return nil, fmt.Errorf("解析重复抑制窗口文件失败: %v", err)
+	}
+	return s, nil
+}
+
+// Fingerprint 计算标题的规范化指纹：转小写、去除标点和空白后取哈希，
+// 使同一条新闻被不同来源转载、标点或大小写略有差异时仍能识别为同一个故事
+func Fingerprint(title string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// Get 返回某个故事当前的抑制窗口记录，不存在时返回零值和 false
+func (s *Store) Get(key string) (Record, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.records[key]
+	return r, ok
+}
+
+// Update 更新某个故事的抑制窗口记录并立即持久化
+func (s *Store) Update(key string, r Record) error {
+	s.mu.Lock()
+	s.records[key] = r
+	data, err := json.MarshalIndent(s.records, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("序列化重复抑制窗口记录失败: %v", err)
+	}
+	if err := os.WriteFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("写入重复抑制窗口文件失败: %v", err)
+	}
+	return nil
+}
+
+// HasSource 判断某个来源是否已经计入过该故事
+func HasSource(r Record, source string) bool {
+	for _, s := range r.Sources {
+		if s == source {
+			return true
+		}
+	}
+	return false
+}
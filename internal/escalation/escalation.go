@@ -0,0 +1,98 @@
+// Package escalation 为评级达到严重程度、在主推送渠道投递失败的条目提供一条
+// 升级通知链：按配置顺序依次尝试链上的通道（如 Telegram 管理群 → ntfy → 短信网关），
+// 直到某个通道发送成功或链路耗尽，调用方负责记录实际尝试过的通道路径
+package escalation
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/amylixing/news-fetcher/internal/config"
+)
+
+// TelegramSender 是升级链中 telegram 类型通道所需的最小发送能力，由
+// internal/sender.Sender 实现；escalation 只依赖这个最小接口而不直接依赖 sender 包，
+// 避免引入不必要的包间耦合
+type TelegramSender interface {
+	SendText(ctx context.Context, chatIDs []string, text string) error
+}
+
+// Escalator 按配置的通道链依次尝试发送告警文本
+type Escalator struct {
+	cfg      *config.EscalationConfig
+	telegram TelegramSender
+	client   *http.Client
+}
+
+// New 创建一个升级链发送器，cfg 为空或未开启时 Critical 总是返回 false
+func New(cfg *config.EscalationConfig, telegram TelegramSender) *Escalator {
+	return &Escalator{
+		cfg:      cfg,
+		telegram: telegram,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Critical 判断给定重要性（通常取 |SentimentScore|）是否达到触发升级链的阈值
+func (e *Escalator) Critical(importance float64) bool {
+	return e.cfg != nil && e.cfg.Enabled && importance >= e.cfg.ImportanceThreshold
+}
+
+// Escalate 依次尝试通道链上的每个通道，直到某个成功或全部失败为止，返回实际
+// 尝试过的通道类型列表（供调用方记录升级路径）；全部失败时错误为最后一个通道的错误
+func (e *Escalator) Escalate(ctx context.Context, text string) ([]string, error) {
+	var path []string
+	var lastErr error
+	for _, ch := range e.cfg.Channels {
+		path = append(path, ch.Type)
+		if err := e.send(ctx, ch, text); err != nil {
+			lastErr = fmt.Errorf("通道 %s 发送失败: %v", ch.Type, err)
+			continue
+		}
+		return path, nil
+	}
+	return path, lastErr
+}
+
+func (e *Escalator) send(ctx context.Context, ch *config.EscalationChannelConfig, text string) error {
+	switch ch.Type {
+	case "telegram":
+		var chatIDs []string
+		if ch.ChatID != "" {
+			chatIDs = []string{ch.ChatID}
+		}
+		return e.telegram.SendText(ctx, chatIDs, text)
+	case "ntfy", "sms":
+		return e.postPlainText(ctx, ch.URL, text)
+	default:
+		return fmt.Errorf("未知的升级通道类型: %s", ch.Type)
+	}
+}
+
+// postPlainText 向 url 发送一个纯文本 POST 请求：ntfy.sh 约定直接把消息正文作为
+// 请求体推送，通用短信网关多数也接受纯文本请求体，这里取两者的最简公共子集，
+// 具体网关地址、鉴权、号码等细节留给用户在 url 中自行拼装
+func (e *Escalator) postPlainText(ctx context.Context, url, text string) error {
+	if url == "" {
+		return fmt.Errorf("未配置目标地址")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBufferString(text))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %v", err)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("响应状态码异常: %d", resp.StatusCode)
+	}
+	return nil
+}
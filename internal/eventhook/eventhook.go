@@ -0,0 +1,73 @@
+// Package eventhook 把抓取管道内部的生命周期事件（数据源熔断/恢复、单轮处理完成、
+// 每日额度用尽等）以结构化 JSON POST 到一个可配置的 Webhook 地址，供外部监控和自动化
+// 系统据此响应管道状态变化，而不必解析日志。仓库尚未引入 MQTT 客户端依赖，暂只支持
+// HTTP webhook；采用 eventlog 已经确立的包级单例做法，避免为了在 fetcher/ai 等多个
+// 互不相关的包里触发事件而把配置层层传参
+package eventhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/amylixing/news-fetcher/internal/config"
+)
+
+var (
+	mu     sync.RWMutex
+	cfg    *config.EventHookConfig
+	client *http.Client
+)
+
+// Configure 按 event_hook 配置启用/关闭事件推送，应在启动时调用一次；未调用或
+// 未开启时 Emit 直接返回，不影响调用方
+func Configure(c *config.EventHookConfig) {
+	timeout := 10 * time.Second
+	if c != nil && c.Timeout > 0 {
+		timeout = time.Duration(c.Timeout) * time.Second
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	cfg = c
+	client = &http.Client{Timeout: timeout}
+}
+
+// Event 是推送到 event_hook.url 的结构化事件负载
+type Event struct {
+	// Type 目前有 source_failed、source_recovered、cycle_completed、budget_exhausted 四种
+	Type    string    `json:"type"`
+	Source  string    `json:"source,omitempty"`
+	Message string    `json:"message,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// Emit 推送一个生命周期事件，未开启 event_hook 时直接返回；推送失败只记录日志，
+// 不返回错误也不重试，避免监控通道自身的问题影响主流程
+func Emit(eventType, source, message string) {
+	mu.RLock()
+	c, httpClient := cfg, client
+	mu.RUnlock()
+	if c == nil || !c.Enabled || c.URL == "" {
+		return
+	}
+
+	body, err := json.Marshal(Event{Type: eventType, Source: source, Message: message, Time: time.Now()})
+	if err != nil {
+		log.Printf("[event_hook] 序列化事件失败: %v", err)
+		return
+	}
+
+	resp, err := httpClient.Post(c.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Printf("[event_hook] 推送事件失败（type=%s）: %v", eventType, err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		log.Printf("[event_hook] 推送事件响应状态码异常（type=%s）: %d", eventType, resp.StatusCode)
+	}
+}
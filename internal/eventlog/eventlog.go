@@ -0,0 +1,67 @@
+// Package eventlog 提供结构化的事件日志，取代此前 fetcher、sender 里
+// 直接把响应体/消息全文打进日志的做法，避免正文和令牌等敏感信息泄露到日志中。
+package eventlog
+
+import (
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// defaultDumpMaxSize 是 dump 开关未配置时的默认截断长度（字节）
+const defaultDumpMaxSize = 512
+
+var (
+	dumpEnabled int32 // 0/1，通过 atomic 读写，允许运行期由配置决定
+	dumpMaxSize int32 = defaultDumpMaxSize
+	debugLevel  int32 // 0/1，app.log_level 为 "debug" 时置 1
+)
+
+// SetLogLevel 按 app.log_level 配置日志级别，目前只区分是否为 "debug"：
+// 高产出数据源逐条目级别的细节日志（如质量门槛丢弃了哪一条）只在 debug 级别
+// 下输出，非 debug 级别只保留每个数据源每轮一行的汇总日志，避免刷屏
+func SetLogLevel(level string) {
+	if level == "debug" {
+		atomic.StoreInt32(&debugLevel, 1)
+	} else {
+		atomic.StoreInt32(&debugLevel, 0)
+	}
+}
+
+// Debugf 仅在 app.log_level 为 "debug" 时输出，用法与 log.Printf 一致
+func Debugf(format string, args ...interface{}) {
+	if atomic.LoadInt32(&debugLevel) == 1 {
+		log.Printf(format, args...)
+	}
+}
+
+// SetDebugDump 配置是否输出原始负载（响应体、消息全文等）以及输出时的截断长度，
+// maxSize <= 0 时使用默认截断长度
+func SetDebugDump(enabled bool, maxSize int) {
+	if enabled {
+		atomic.StoreInt32(&dumpEnabled, 1)
+	} else {
+		atomic.StoreInt32(&dumpEnabled, 0)
+	}
+	if maxSize > 0 {
+		atomic.StoreInt32(&dumpMaxSize, int32(maxSize))
+	}
+}
+
+// Event 记录一次结构化事件：数据源/组件、所处阶段、条目ID、结果和耗时，
+// 用于替代此前分散在各处、格式不统一的自由文本日志
+func Event(source, stage, itemID, outcome string, dur time.Duration) {
+	log.Printf("[event] source=%s stage=%s item=%s outcome=%s duration=%v", source, stage, itemID, outcome, dur)
+}
+
+// Dump 输出原始负载用于调试，仅在 SetDebugDump 开启时生效，超过截断长度的内容会被截断
+func Dump(label, payload string) {
+	if atomic.LoadInt32(&dumpEnabled) == 0 {
+		return
+	}
+	max := int(atomic.LoadInt32(&dumpMaxSize))
+	if len(payload) > max {
+		payload = payload[:max] + "...(已截断)"
+	}
+	log.Printf("[dump] %s: %s", label, payload)
+}
@@ -0,0 +1,72 @@
+// Package extract 提供一个简化的可读性（readability）风格正文提取器：抓取文章
+// 链接页面，剔除脚本/样式/导航/页脚等噪音节点，启发式选出正文最完整的容器，
+// 返回其纯文本，用于弥补 RSS/API 摘要经常被截断、抓不到完整正文的问题
+package extract
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// noiseSelector 匹配页面里通常与正文无关、需要在打分和输出前剔除的节点
+const noiseSelector = "script, style, nav, header, footer, form, iframe, noscript"
+
+// candidateSelector 匹配常见的正文容器标签/class/id，按其中文本最长者作为提取结果
+const candidateSelector = "article, main, [role=main], .article, .article-content, .post-content, .entry-content, #content, #article"
+
+// Article 抓取 link 页面并提取正文纯文本，找不到明显正文容器时退化为整个 <body> 的文本
+func Article(ctx context.Context, httpClient *http.Client, link string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return "", fmt.Errorf("创建请求失败: %v", err)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("请求页面失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("响应状态码异常: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("读取响应失败: %v", err)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(body)))
+	if err != nil {
+		return "", fmt.Errorf("解析HTML失败: %v", err)
+	}
+	doc.Find(noiseSelector).Remove()
+
+	return mainText(doc), nil
+}
+
+// mainText 在正文候选容器里取文本最长者；没有命中任何候选容器时退化为整个
+// body 的文本，都没有取到时返回空字符串
+func mainText(doc *goquery.Document) string {
+	best := ""
+	doc.Find(candidateSelector).Each(func(_ int, s *goquery.Selection) {
+		text := collapseWhitespace(s.Text())
+		if len(text) > len(best) {
+			best = text
+		}
+	})
+	if best != "" {
+		return best
+	}
+	return collapseWhitespace(doc.Find("body").Text())
+}
+
+// collapseWhitespace 把提取出的文本里连续的空白（含换行、制表符）压缩为单个空格
+func collapseWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,57 @@
+package fetcher
+
+import (
+	"errors"
+	"net"
+	"net/http"
+)
+
+// permanentStatusCodes 标记重试无法解决的 HTTP 状态码：数据源地址失效、鉴权
+// 失败或请求本身不合法，重试只会得到相同的响应，白白消耗重试预算和熔断探测配额；
+// 408（请求超时）和 429（限流）虽然也是 4xx，但重试通常能恢复，不计入其中
+var permanentStatusCodes = map[int]bool{
+	http.StatusBadRequest:          true,
+	http.StatusUnauthorized:        true,
+	http.StatusForbidden:           true,
+	http.StatusNotFound:            true,
+	http.StatusGone:                true,
+	http.StatusMethodNotAllowed:    true,
+	http.StatusUnprocessableEntity: true,
+}
+
+// isPermanentStatus 判断某个 HTTP 状态码代表的失败是否为永久性的，不值得重试
+func isPermanentStatus(statusCode int) bool {
+	return permanentStatusCodes[statusCode]
+}
+
+// isPermanentErr 判断请求失败（未拿到 HTTP 响应）是否为永久性的：DNS 解析失败等
+// 不会随重试消失，而超时、连接被拒绝等网络抖动通常值得重试
+func isPermanentErr(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return !dnsErr.IsTimeout && !dnsErr.IsTemporary
+	}
+	return false
+}
+
+// classifiedError 包裹一个错误并标记它是否为永久性失败，供 Fetcher 决定是否
+// 继续重试、以及是否把它计入熔断器和数据源健康统计
+type classifiedError struct {
+	err       error
+	permanent bool
+}
+
+func (e *classifiedError) Error() string { return e.err.Error() }
+func (e *classifiedError) Unwrap() error { return e.err }
+
+// permanentError 把一个错误标记为永久性失败（重试无意义），如 404/401 等
+func permanentError(err error) error {
+	return &classifiedError{err: err, permanent: true}
+}
+
+// isPermanent 判断 source.Fetch 返回的错误是否被标记为永久性失败；
+// 未经过分类包裹的错误一律视为非永久性（保守起见按可重试处理）
+func isPermanent(err error) bool {
+	var ce *classifiedError
+	return errors.As(err, &ce) && ce.permanent
+}
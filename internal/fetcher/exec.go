@@ -0,0 +1,92 @@
+package fetcher
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/amylixing/news-fetcher/internal/config"
+	"github.com/amylixing/news-fetcher/internal/customparser"
+	"github.com/amylixing/news-fetcher/internal/models"
+)
+
+func init() {
+	Register("exec", NewExecSource)
+}
+
+// ExecSource 通过运行一个外部命令/脚本抓取新闻：命令本身负责完整的抓取过程（不像
+// customparser 那样只处理已经用 HTTP 抓回来的响应体），在标准输出逐行打印一个 JSON
+// 对象（JSON Lines），字段对齐 customparser.Item。用于免编译接入任意语言写的爬虫，
+// 在配置里通过 sources.custom 下 type: exec 使用，命令路径写在 url 字段，可选的
+// args 字段作为命令行参数传给它。WASM 插件走的是独立的 wasm 数据源类型（见
+// fetcher.WASMSource），不经过这里
+type ExecSource struct {
+	config  *config.SourceConfig
+	timeout time.Duration
+}
+
+// NewExecSource 创建一个 exec 数据源，timeout<=0 时默认 30 秒；实现 SourceConstructor
+// 签名供 Register 使用，第二个参数（HTTP 连接池配置）因为不发起 HTTP 请求而未使用
+func NewExecSource(cfg *config.SourceConfig, _ *config.HTTPClientConfig) (Source, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("exec 数据源必须在 url 字段配置可执行文件路径")
+	}
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &ExecSource{config: cfg, timeout: timeout}, nil
+}
+
+// Fetch 运行配置的命令（不写入标准输入），逐行解析标准输出的 JSON 对象为新闻条目；
+// 命令的标准错误输出会拼接进返回的错误信息，便于排查脚本本身的问题
+func (s *ExecSource) Fetch(ctx context.Context) ([]*models.News, error) {
+	runCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(runCtx, s.config.URL, s.config.Args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("执行 exec 数据源命令失败: %v: %s", err, stderr.String())
+	}
+
+	var newsList []*models.News
+	scanner := bufio.NewScanner(&stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var item customparser.Item
+		if err := json.Unmarshal([]byte(line), &item); err != nil {
+			return nil, fmt.Errorf("解析 exec 数据源输出失败: %v: %s", err, line)
+		}
+		createTime := time.Now()
+		if item.Time != "" {
+			if parsed, err := time.Parse(time.RFC3339, item.Time); err == nil {
+				createTime = parsed
+			}
+		}
+		newsList = append(newsList, &models.News{
+			ID:              item.ID,
+			OriginalTitle:   item.Title,
+			OriginalContent: item.Content,
+			Link:            item.Link,
+			Source:          s.config.URL,
+			CreateTime:      createTime,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取 exec 数据源输出失败: %v", err)
+	}
+	return newsList, nil
+}
@@ -1,26 +1,70 @@
 package fetcher
 
 import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/amylixing/news-fetcher/internal/breaker"
+	"github.com/amylixing/news-fetcher/internal/budget"
 	"github.com/amylixing/news-fetcher/internal/config"
+	"github.com/amylixing/news-fetcher/internal/contentcleanup"
+	"github.com/amylixing/news-fetcher/internal/cookiejar"
+	"github.com/amylixing/news-fetcher/internal/cursor"
+	"github.com/amylixing/news-fetcher/internal/eventhook"
+	"github.com/amylixing/news-fetcher/internal/eventlog"
+	"github.com/amylixing/news-fetcher/internal/extract"
+	"github.com/amylixing/news-fetcher/internal/health"
+	"github.com/amylixing/news-fetcher/internal/httpclient"
 	"github.com/amylixing/news-fetcher/internal/models"
+	"github.com/amylixing/news-fetcher/internal/opml"
+	"github.com/amylixing/news-fetcher/internal/proxypool"
+	"github.com/amylixing/news-fetcher/internal/ratelimit"
+	"github.com/amylixing/news-fetcher/internal/sourcestate"
+	"github.com/amylixing/news-fetcher/internal/urlnorm"
+	"github.com/amylixing/news-fetcher/internal/wasmplugin"
+	"github.com/amylixing/news-fetcher/internal/websub"
+	"github.com/google/uuid"
 	"github.com/mmcdole/gofeed"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/sync/errgroup"
 )
 
 // Fetcher 新闻抓取器
 type Fetcher struct {
-	apiSources []*APISource
-	rssSources []*RSSSource
-	client     *http.Client
+	mu            sync.RWMutex // 保护 rssSources，运行期可能通过 AddRSSSource/RemoveRSSSource 并发修改
+	apiSources    []*APISource
+	rssSources    []*RSSSource
+	mockSources   []*MockSource
+	hnSources     []*HNSource
+	customSources []*customSourceEntry // 通过 fetcher.Register 注册的第三方数据源类型，见 registry.go
+	client        *http.Client
+	poolCfg       *config.HTTPClientConfig
+	cursors       *cursor.Store           // 为空表示未开启抓取游标持久化
+	sourceState   *sourcestate.Store      // 为空表示未开启逐数据源状态持久化
+	cookieJar     *cookiejar.Store        // 为空表示未开启持久化 Cookie Jar
+	normalize     *config.NormalizeConfig // 为空或未开启时不做链接规范化
+	concurrency   int                     // 同时抓取的数据源数量上限，<=0 时使用 defaultFetchConcurrency
+	health        *health.Registry        // 按数据源 URL 跟踪成功率/延迟/最近成功时间，长期失效时自动禁用
+	webSubSrv     *http.Server            // WebSub 回调 HTTP 服务，未开启 websub 时为空
+	proxyPool     *config.ProxyPoolConfig // 全局代理池配置，未在具体数据源上单独配置时使用这一份
+	userAgent     string                  // 全局默认 User-Agent，未在具体数据源上单独配置时使用这一份
+	headerProfile string                  // 全局默认命名请求头集合，未在具体数据源上单独配置时使用这一份
 }
 
 // Source 数据源接口
@@ -28,116 +72,1252 @@ type Source interface {
 	Fetch(ctx context.Context) ([]*models.News, error)
 }
 
+// StatefulSource 是 Source 的可选扩展接口，供需要持久化自身抓取状态
+// （分页游标、鉴权令牌、ETag 等）的数据源实现，不必各自发明文件格式；
+// Fetcher 开启 sourceState 后会在创建每个数据源时自动注入
+type StatefulSource interface {
+	SetState(state *sourcestate.Namespace)
+}
+
+// CookieJarSource 是 Source 的可选扩展接口，供支持登录态会话的数据源实现，
+// Fetcher 开启 cookieJar 后会在创建每个数据源时自动注入；数据源自身决定是否
+// 真正启用（未在配置里开启 cookie_jar 的数据源可以忽略这次注入）
+type CookieJarSource interface {
+	SetCookieJar(jar http.CookieJar)
+}
+
 // APISource API数据源
 type APISource struct {
-	config     *config.SourceConfig
-	httpClient *http.Client
+	config       *config.SourceConfig
+	httpClient   *http.Client
+	pollInterval time.Duration // 0 表示跟随全局抓取间隔，每次调用 Fetch 都实际请求
+	lastFetch    time.Time
+	breaker      *breaker.Breaker // 为空表示未开启熔断
+	// effectiveInterval 是开启 AdaptivePolling 时实际生效的轮询间隔，随产出新
+	// 条目的频率在 [MinSeconds, MaxSeconds] 内自动伸缩；未开启时不使用该字段
+	effectiveInterval time.Duration
+	state             *sourcestate.Namespace // 为空表示未开启逐数据源状态持久化，不做响应体哈希去重
+	rateLimiter       *ratelimit.Limiter     // 为空表示未开启限速
+	budget            *budget.Tracker        // 每日抓取请求数配额，FetchDailyBudget<=0 时不限额
+	extraHeaders      map[string]string      // user_agent/header_profile 解析出的默认请求头，Headers 里显式配置的同名字段优先
+	loginOnce         sync.Once              // 保证配置了 Login 时只在第一次 Fetch 前执行一次登录
+	loginErr          error
+}
+
+// SetState 注入该数据源的持久化状态命名空间，实现 StatefulSource
+func (s *APISource) SetState(state *sourcestate.Namespace) {
+	s.state = state
+}
+
+// SetCookieJar 注入该数据源的持久化 Cookie Jar，实现 CookieJarSource；仅在
+// 配置里开启了 cookie_jar 的数据源才真正接受这次注入，否则忽略
+func (s *APISource) SetCookieJar(jar http.CookieJar) {
+	if s.config.CookieJar {
+		s.httpClient.Jar = jar
+	}
+}
+
+// ensureLoggedIn 在配置了 Login 时，于该数据源第一次实际发起请求前提交一次
+// 表单登录，产生的 Set-Cookie 由 httpClient.Jar（若已通过 SetCookieJar 注入）
+// 自动保存并在后续请求携带；未配置 Login 时什么也不做
+func (s *APISource) ensureLoggedIn(ctx context.Context) error {
+	if s.config.Login == nil {
+		return nil
+	}
+	s.loginOnce.Do(func() {
+		s.loginErr = performLogin(ctx, s.httpClient, s.config.Login)
+	})
+	return s.loginErr
+}
+
+// RSSSource RSS数据源
+type RSSSource struct {
+	config            *config.SourceConfig
+	proxyURL          string
+	httpClient        *http.Client
+	pollInterval      time.Duration
+	lastFetch         time.Time
+	breaker           *breaker.Breaker // 为空表示未开启熔断
+	effectiveInterval time.Duration
+	state             *sourcestate.Namespace // 为空表示未开启逐数据源状态持久化，不发送 ETag 条件请求
+	rateLimiter       *ratelimit.Limiter     // 为空表示未开启限速
+	budget            *budget.Tracker        // 每日抓取请求数配额，FetchDailyBudget<=0 时不限额
+	extraHeaders      map[string]string      // user_agent/header_profile 解析出的默认请求头，Headers 里显式配置的同名字段优先
+	loginOnce         sync.Once              // 保证配置了 Login 时只在第一次 Fetch 前执行一次登录
+	loginErr          error
+
+	webSubMu     sync.Mutex
+	pushedBody   []byte // WebSub 推送到达时暂存的原始 feed 内容，下次 Fetch 时优先消费并清空
+	webSubActive bool   // 订阅是否已成功建立，成立后放宽轮询间隔，推送为主、轮询兜底
+}
+
+// SetCookieJar 注入该数据源的持久化 Cookie Jar，实现 CookieJarSource；仅在
+// 配置里开启了 cookie_jar 的数据源才真正接受这次注入，否则忽略
+func (s *RSSSource) SetCookieJar(jar http.CookieJar) {
+	if s.config.CookieJar {
+		s.httpClient.Jar = jar
+	}
+}
+
+// ensureLoggedIn 在配置了 Login 时，于该数据源第一次实际发起请求前提交一次
+// 表单登录，产生的 Set-Cookie 由 httpClient.Jar（若已通过 SetCookieJar 注入）
+// 自动保存并在后续请求携带；未配置 Login 时什么也不做
+func (s *RSSSource) ensureLoggedIn(ctx context.Context) error {
+	if s.config.Login == nil {
+		return nil
+	}
+	s.loginOnce.Do(func() {
+		s.loginErr = performLogin(ctx, s.httpClient, s.config.Login)
+	})
+	return s.loginErr
+}
+
+// webSubFallbackPollMultiplier 是 WebSub 订阅生效后，用作安全网的轮询间隔相对
+// PollInterval 放大的倍数：更新正常情况下由 hub 推送，轮询只是兜底，避免推送
+// 丢失或 hub 静默失效时长期收不到更新
+const webSubFallbackPollMultiplier = 6
+
+// hasPushedBody 返回是否有尚未消费的 WebSub 推送内容
+func (s *RSSSource) hasPushedBody() bool {
+	s.webSubMu.Lock()
+	defer s.webSubMu.Unlock()
+	return s.pushedBody != nil
+}
+
+// takePushedBody 取出并清空暂存的 WebSub 推送内容，没有推送时返回 nil
+func (s *RSSSource) takePushedBody() []byte {
+	s.webSubMu.Lock()
+	defer s.webSubMu.Unlock()
+	body := s.pushedBody
+	s.pushedBody = nil
+	return body
+}
+
+// deliverWebSubPush 由 WebSub 回调 HTTP handler 调用，把推送内容暂存起来，
+// 下一次 Fetch 会优先消费它而不是重新发起 HTTP 请求
+func (s *RSSSource) deliverWebSubPush(body []byte) {
+	s.webSubMu.Lock()
+	s.pushedBody = body
+	s.webSubMu.Unlock()
+}
+
+// markWebSubActive 标记该数据源的 WebSub 订阅已成功建立，并把轮询间隔放宽为
+// 安全网间隔；订阅从未成功或后续失效时，effectiveInterval 不会被这里放宽，
+// 数据源按原本的 PollInterval 正常轮询
+func (s *RSSSource) markWebSubActive() {
+	s.webSubMu.Lock()
+	defer s.webSubMu.Unlock()
+	s.webSubActive = true
+	if s.pollInterval > 0 {
+		s.effectiveInterval = s.pollInterval * webSubFallbackPollMultiplier
+	}
+}
+
+// isWebSubActive 返回该数据源当前是否处于 WebSub 推送订阅生效状态
+func (s *RSSSource) isWebSubActive() bool {
+	s.webSubMu.Lock()
+	defer s.webSubMu.Unlock()
+	return s.webSubActive
+}
+
+// SetState 注入该数据源的持久化状态命名空间，实现 StatefulSource
+func (s *RSSSource) SetState(state *sourcestate.Namespace) {
+	s.state = state
+}
+
+// MockSource 从本地固定文件回放新闻，不发起任何网络请求，
+// 用于录制/回放式的离线联调（校验配置、过滤规则、消息模板）
+type MockSource struct {
+	config            *config.SourceConfig
+	pollInterval      time.Duration
+	lastFetch         time.Time
+	effectiveInterval time.Duration
+}
+
+// newRateLimiterFromConfig 按配置创建一个令牌桶限速器，未开启时返回 nil（表示不限速）
+func newRateLimiterFromConfig(cfg *config.SourceRateLimitConfig) *ratelimit.Limiter {
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+	return ratelimit.New(cfg.RequestsPerMinute, cfg.Burst)
+}
+
+// fetchBudgetKey 是每个数据源的 budget.Tracker 中用于计数抓取请求次数的键；
+// 每个数据源持有自己独立的 Tracker 实例，不需要用 URL 区分
+const fetchBudgetKey = "fetch"
+
+// NewFetcher 创建新闻抓取器，poolCfg 为空时使用共享的默认连接池参数
+func NewFetcher(cfg *config.SourcesConfig, poolCfg *config.HTTPClientConfig) (*Fetcher, error) {
+	// 创建HTTP客户端
+	client, err := httpclient.New(httpclient.Options{
+		Pool: httpclient.PoolFromConfig(poolCfg),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP客户端失败: %v", err)
+	}
+
+	// 初始化API数据源
+	var apiSources []*APISource
+	for _, apiCfg := range cfg.API {
+		source, err := NewAPISource(apiCfg, poolCfg, cfg.ProxyPool, cfg.UserAgent, cfg.HeaderProfile)
+		if err != nil {
+			return nil, fmt.Errorf("初始化API数据源失败: %v", err)
+		}
+		source.breaker = newBreakerFromConfig(cfg.Breaker)
+		source.rateLimiter = newRateLimiterFromConfig(cfg.RateLimit)
+		apiSources = append(apiSources, source)
+	}
+
+	// 初始化RSS数据源：手写的 RSS 配置和 OPML 文件里导入的 feed 合并成一份列表，
+	// 逐条走相同的初始化逻辑
+	rssCfgs := append([]*config.SourceConfig(nil), cfg.RSS...)
+	if cfg.RSSOPML != "" {
+		feedURLs, err := opml.LoadFeedURLs(cfg.RSSOPML)
+		if err != nil {
+			return nil, fmt.Errorf("加载OPML订阅列表失败: %v", err)
+		}
+		for _, feedURL := range feedURLs {
+			rssCfgs = append(rssCfgs, &config.SourceConfig{URL: feedURL})
+		}
+		log.Printf("从OPML订阅列表 %s 导入了 %d 个RSS源", cfg.RSSOPML, len(feedURLs))
+	}
+
+	var rssSources []*RSSSource
+	for _, rssCfg := range rssCfgs {
+		source, err := NewRSSSource(rssCfg, poolCfg, cfg.ProxyPool, cfg.UserAgent, cfg.HeaderProfile)
+		if err != nil {
+			return nil, fmt.Errorf("初始化RSS数据源失败: %v", err)
+		}
+		source.breaker = newBreakerFromConfig(cfg.Breaker)
+		source.rateLimiter = newRateLimiterFromConfig(cfg.RateLimit)
+		rssSources = append(rssSources, source)
+	}
+
+	// 初始化 mock 数据源
+	var mockSources []*MockSource
+	for _, mockCfg := range cfg.Mock {
+		mockSources = append(mockSources, NewMockSource(mockCfg))
+	}
+
+	// 初始化 Hacker News 数据源
+	var hnSources []*HNSource
+	for _, hnCfg := range cfg.HackerNews {
+		source, err := NewHNSource(hnCfg, poolCfg, cfg.ProxyPool, cfg.UserAgent, cfg.HeaderProfile)
+		if err != nil {
+			return nil, fmt.Errorf("初始化Hacker News数据源失败: %v", err)
+		}
+		source.breaker = newBreakerFromConfig(cfg.Breaker)
+		source.rateLimiter = newRateLimiterFromConfig(cfg.RateLimit)
+		hnSources = append(hnSources, source)
+	}
+
+	// 手写的 custom 配置和从插件目录发现的 WASM 数据源合并成一份列表，
+	// 后者以 wasm 类型的形式复用完全相同的注册/熔断/轮询初始化逻辑
+	customCfgs := append([]*config.SourceConfig(nil), cfg.Custom...)
+	wasmCfgs, err := wasmplugin.Discover(cfg.WASMPlugins)
+	if err != nil {
+		return nil, fmt.Errorf("发现 WASM 数据源插件失败: %v", err)
+	}
+	if len(wasmCfgs) > 0 {
+		log.Printf("从插件目录 %s 发现了 %d 个 WASM 数据源插件", cfg.WASMPlugins.Dir, len(wasmCfgs))
+		customCfgs = append(customCfgs, wasmCfgs...)
+	}
+
+	customSources, err := newCustomSources(customCfgs, poolCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Fetcher{
+		apiSources:    apiSources,
+		rssSources:    rssSources,
+		mockSources:   mockSources,
+		hnSources:     hnSources,
+		customSources: customSources,
+		client:        client,
+		poolCfg:       poolCfg,
+		normalize:     cfg.Normalize,
+		concurrency:   cfg.Concurrency,
+		health:        newHealthRegistryFromConfig(cfg.Health),
+		proxyPool:     cfg.ProxyPool,
+		userAgent:     cfg.UserAgent,
+		headerProfile: cfg.HeaderProfile,
+	}, nil
+}
+
+// newProxyPoolFromConfig 按数据源自身的代理池配置创建一个 *proxypool.Pool，
+// 未配置时退回全局配置，两者都未配置则返回 nil（表示不使用代理池，走
+// 原有的单一 proxy_url 或不代理逻辑）
+func newProxyPoolFromConfig(sourceCfg, globalCfg *config.ProxyPoolConfig) (*proxypool.Pool, error) {
+	cfg := sourceCfg
+	if cfg == nil {
+		cfg = globalCfg
+	}
+	if cfg == nil || len(cfg.List) == 0 {
+		return nil, nil
+	}
+	pool, err := proxypool.New(cfg.List, cfg.Strategy)
+	if err != nil {
+		return nil, fmt.Errorf("创建代理池失败: %v", err)
+	}
+	return pool, nil
+}
+
+// performLogin 向 cfg.URL 提交一次表单登录请求，用于需要先建立会话才能正常
+// 抓取正文的数据源；client 若已通过 SetCookieJar 注入了持久化 Cookie Jar，
+// 响应里的 Set-Cookie 会被自动保存并在该数据源后续请求中携带
+func performLogin(ctx context.Context, client *http.Client, cfg *config.LoginConfig) error {
+	method := cfg.Method
+	if method == "" {
+		method = "POST"
+	}
+
+	form := url.Values{}
+	for key, value := range cfg.Fields {
+		form.Set(key, value)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, cfg.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("创建登录请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送登录请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("登录请求响应状态码异常: %d", resp.StatusCode)
+	}
+	log.Printf("已完成数据源登录: %s", cfg.URL)
+	return nil
+}
+
+// newHealthRegistryFromConfig 按配置创建健康登记表，cfg 为空时使用默认值（不自动禁用）
+func newHealthRegistryFromConfig(cfg *config.HealthConfig) *health.Registry {
+	if cfg == nil {
+		return health.New(0)
+	}
+	return health.New(cfg.AutoDisableAfter)
+}
+
+// newBreakerFromConfig 按配置创建一个熔断器，未开启时返回 nil（表示不熔断）
+func newBreakerFromConfig(cfg *config.BreakerConfig) *breaker.Breaker {
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+	return breaker.New(cfg.FailureThreshold, time.Duration(cfg.CooldownSeconds)*time.Second, cfg.HalfOpenMaxProbes)
+}
+
+// recordBreakerSuccess 记录一次成功抓取，若此前处于 open/half-open 状态则说明
+// 数据源刚从熔断中恢复，记录一条恢复日志，方便运维确认某个上游何时恢复正常
+func recordBreakerSuccess(b *breaker.Breaker, url string) {
+	if b == nil {
+		return
+	}
+	wasRecovering := b.Snapshot().State != breaker.StateClosed.String()
+	b.RecordSuccess()
+	if wasRecovering {
+		log.Printf("数据源 %s 已从熔断中恢复", url)
+		eventhook.Emit("source_recovered", url, "")
+	}
+}
+
+// recordBreakerFailure 是 recordBreakerSuccess 的失败版本，抽出来供 API/RSS/HN
+// 三种内置数据源共用；熔断器由非 open 转为 open 时通过 event_hook 推送 source_failed
+func recordBreakerFailure(b *breaker.Breaker, url string, err error) {
+	if b == nil {
+		return
+	}
+	wasOpen := b.Snapshot().State == breaker.StateOpen.String()
+	if isPermanent(err) {
+		b.RecordPermanentFailure()
+	} else {
+		b.RecordFailure()
+	}
+	if !wasOpen && b.Snapshot().State == breaker.StateOpen.String() {
+		eventhook.Emit("source_failed", url, err.Error())
+	}
+}
+
+// NewAPISource 创建API数据源，poolCfg 为空时使用共享的默认连接池参数，
+// globalProxyPool 是 sources.proxy_pool 全局代理池配置，数据源自身未配置
+// proxy_pool 时使用这一份；globalUserAgent/globalHeaderProfile 同理对应
+// sources.user_agent/sources.header_profile
+func NewAPISource(cfg *config.SourceConfig, poolCfg *config.HTTPClientConfig, globalProxyPool *config.ProxyPoolConfig, globalUserAgent, globalHeaderProfile string) (*APISource, error) {
+	proxyPool, err := newProxyPoolFromConfig(cfg.ProxyPool, globalProxyPool)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := httpclient.New(httpclient.Options{
+		ProxyURL:  cfg.ProxyURL,
+		ProxyPool: proxyPool,
+		Timeout:   time.Duration(cfg.Timeout) * time.Second,
+		Pool:      httpclient.PoolFromConfig(poolCfg),
+		TLS:       cfg.TLS,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP客户端失败: %v", err)
+	}
+
+	if cfg.Auth != nil {
+		client = newOAuth2Client(cfg.Auth, client)
+	}
+
+	return &APISource{
+		config:            cfg,
+		httpClient:        client,
+		pollInterval:      time.Duration(cfg.PollInterval) * time.Second,
+		effectiveInterval: time.Duration(cfg.PollInterval) * time.Second,
+		budget:            budget.New(),
+		extraHeaders:      resolveHeaders(cfg, globalUserAgent, globalHeaderProfile),
+	}, nil
+}
+
+// NewRSSSource 创建RSS数据源，poolCfg 为空时使用共享的默认连接池参数，
+// globalProxyPool 是 sources.proxy_pool 全局代理池配置，数据源自身未配置
+// proxy_pool 时使用这一份；globalUserAgent/globalHeaderProfile 同理对应
+// sources.user_agent/sources.header_profile
+func NewRSSSource(cfg *config.SourceConfig, poolCfg *config.HTTPClientConfig, globalProxyPool *config.ProxyPoolConfig, globalUserAgent, globalHeaderProfile string) (*RSSSource, error) {
+	if cfg.ProxyURL != "" {
+		log.Printf("已配置代理: %s", cfg.ProxyURL)
+	}
+
+	proxyPool, err := newProxyPoolFromConfig(cfg.ProxyPool, globalProxyPool)
+	if err != nil {
+		return nil, err
+	}
+	if proxyPool != nil {
+		log.Printf("已配置代理池，共 %d 个代理", len(proxyPool.Snapshots()))
+	}
+
+	client, err := httpclient.New(httpclient.Options{
+		ProxyURL:  cfg.ProxyURL,
+		ProxyPool: proxyPool,
+		Timeout:   time.Duration(cfg.Timeout) * time.Second,
+		Pool:      httpclient.PoolFromConfig(poolCfg),
+		TLS:       cfg.TLS,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP客户端失败: %v", err)
+	}
+
+	return &RSSSource{
+		config:            cfg,
+		proxyURL:          cfg.ProxyURL,
+		httpClient:        client,
+		pollInterval:      time.Duration(cfg.PollInterval) * time.Second,
+		effectiveInterval: time.Duration(cfg.PollInterval) * time.Second,
+		budget:            budget.New(),
+		extraHeaders:      resolveHeaders(cfg, globalUserAgent, globalHeaderProfile),
+	}, nil
+}
+
+// NewMockSource 创建一个 mock 数据源，cfg.URL 为固定文件路径
+func NewMockSource(cfg *config.SourceConfig) *MockSource {
+	return &MockSource{
+		config:            cfg,
+		pollInterval:      time.Duration(cfg.PollInterval) * time.Second,
+		effectiveInterval: time.Duration(cfg.PollInterval) * time.Second,
+	}
+}
+
+// Fetch 从固定文件中读取新闻列表，不发起任何网络请求
+func (s *MockSource) Fetch(ctx context.Context) ([]*models.News, error) {
+	data, err := os.ReadFile(s.config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("读取mock固定文件失败: %v", err)
+	}
+
+	var newsList []*models.News
+	if err := json.Unmarshal(data, &newsList); err != nil {
+		return nil, fmt.Errorf("解析mock固定文件失败: %v", err)
+	}
+
+	var minCreateTime time.Time
+	if s.config.MaxAge > 0 {
+		minCreateTime = time.Now().Add(-time.Duration(s.config.MaxAge) * time.Second)
+	}
+
+	var filtered []*models.News
+	for _, news := range newsList {
+		if !minCreateTime.IsZero() && news.CreateTime.Before(minCreateTime) {
+			continue
+		}
+		filtered = append(filtered, news)
+		if s.config.MaxItems > 0 && len(filtered) >= s.config.MaxItems {
+			break
+		}
+	}
+
+	log.Printf("从mock固定文件 %s 读取到 %d 条新闻", s.config.URL, len(filtered))
+	return filtered, nil
+}
+
+// DumpFixtures 把一批新闻按数据源分组写入 dir 目录下的固定文件，供 mock 数据源回放，
+// 用于把一次真实抓取的结果录制下来，实现离线联调
+func DumpFixtures(newsList []*models.News, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("创建固定文件目录失败: %v", err)
+	}
+
+	bySource := make(map[string][]*models.News)
+	for _, news := range newsList {
+		bySource[news.Source] = append(bySource[news.Source], news)
+	}
+
+	for source, items := range bySource {
+		data, err := json.MarshalIndent(items, "", "  ")
+		if err != nil {
+			return fmt.Errorf("序列化数据源 %s 的固定文件失败: %v", source, err)
+		}
+		path := filepath.Join(dir, fixtureFileName(source))
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return fmt.Errorf("写入固定文件 %s 失败: %v", path, err)
+		}
+		log.Printf("已录制 %d 条新闻到 %s", len(items), path)
+	}
+	return nil
+}
+
+// fixtureFileName 把数据源标识（通常是 URL）转成安全的文件名
+func fixtureFileName(source string) string {
+	replacer := strings.NewReplacer("://", "_", "/", "_", ":", "_", "?", "_", "&", "_")
+	return replacer.Replace(source) + ".json"
+}
+
+// EnableCursor 开启逐数据源的抓取游标持久化，path 为存储文件路径；已抓取过的
+// 数据源无需重新调用，多次调用会重新加载并覆盖之前的游标存储
+func (f *Fetcher) EnableCursor(path string) error {
+	store, err := cursor.NewStore(path)
+	if err != nil {
+		return fmt.Errorf("初始化抓取游标失败: %v", err)
+	}
+	f.cursors = store
+	return nil
+}
+
+// EnableSourceState 开启逐数据源的状态持久化（分页游标、鉴权令牌、ETag 等），
+// path 为存储文件路径，会立即注入给已创建的全部数据源，之后新增的数据源
+// （如 AddRSSSource）在创建时也会自动注入
+func (f *Fetcher) EnableSourceState(path string) error {
+	store, err := sourcestate.NewStore(path)
+	if err != nil {
+		return fmt.Errorf("初始化数据源状态存储失败: %v", err)
+	}
+	f.sourceState = store
+
+	for _, source := range f.apiSources {
+		f.injectState(source, source.config.URL)
+	}
+	f.mu.RLock()
+	rssSources := append([]*RSSSource(nil), f.rssSources...)
+	f.mu.RUnlock()
+	for _, source := range rssSources {
+		f.injectState(source, source.config.URL)
+	}
+	for _, source := range f.mockSources {
+		f.injectState(source, source.config.URL)
+	}
+	for _, entry := range f.customSources {
+		f.injectState(entry.source, entry.config.URL)
+	}
+	return nil
+}
+
+// EnableCookieJar 开启逐数据源的持久化 Cookie Jar，path 为存储文件路径，会立即
+// 注入给已创建的全部 API/RSS 数据源，之后新增的数据源（如 AddRSSSource）在创建时
+// 也会自动注入；只有配置了 cookie_jar: true 的数据源才会真正使用注入的 Jar
+func (f *Fetcher) EnableCookieJar(path string) error {
+	store, err := cookiejar.NewStore(path)
+	if err != nil {
+		return fmt.Errorf("初始化 Cookie 存储失败: %v", err)
+	}
+	f.cookieJar = store
+
+	for _, source := range f.apiSources {
+		f.injectCookieJar(source, source.config.URL)
+	}
+	f.mu.RLock()
+	rssSources := append([]*RSSSource(nil), f.rssSources...)
+	f.mu.RUnlock()
+	for _, source := range rssSources {
+		f.injectCookieJar(source, source.config.URL)
+	}
+	for _, entry := range f.customSources {
+		f.injectCookieJar(entry.source, entry.config.URL)
+	}
+	return nil
+}
+
+// EnableWebSub 开启 RSS 数据源的 WebSub 推送订阅：启动本地回调 HTTP 服务，
+// 为配置了 websub: true 的每个 RSS 源逐一尝试发现 hub 并发起订阅；发现不了
+// hub 或订阅请求失败的数据源保持原有轮询节奏，不影响其余数据源
+func (f *Fetcher) EnableWebSub(cfg *config.WebSubConfig) error {
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+	if cfg.CallbackBaseURL == "" || cfg.ListenAddr == "" {
+		return fmt.Errorf("开启 websub 需要同时配置 callback_base_url 和 listen_addr")
+	}
+
+	handler := websub.New()
+	server := &http.Server{Addr: cfg.ListenAddr, Handler: handler}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("WebSub 回调服务退出: %v", err)
+		}
+	}()
+	f.webSubSrv = server
+
+	f.mu.RLock()
+	rssSources := append([]*RSSSource(nil), f.rssSources...)
+	f.mu.RUnlock()
+
+	for i, source := range rssSources {
+		if !source.config.WebSub {
+			continue
+		}
+		path := fmt.Sprintf("/websub/%d", i)
+		callbackURL := strings.TrimRight(cfg.CallbackBaseURL, "/") + path
+		handler.Register(path, source.deliverWebSubPush)
+		f.startWebSubSubscription(source, callbackURL, cfg.LeaseSeconds)
+	}
+	return nil
+}
+
+// startWebSubSubscription 异步发现数据源 feed 声明的 hub 并发起订阅，不阻塞
+// EnableWebSub 的调用方；发现不了 hub 或订阅失败时只记录日志，该数据源继续
+// 按原有 PollInterval 轮询，不作为致命错误处理
+func (f *Fetcher) startWebSubSubscription(source *RSSSource, callbackURL string, leaseSeconds int) {
+	go func() {
+		req, err := http.NewRequest(http.MethodGet, source.config.URL, nil)
+		if err != nil {
+			log.Printf("WebSub 发现请求构造失败，数据源 %s 继续轮询: %v", source.config.URL, err)
+			return
+		}
+		resp, err := source.httpClient.Do(req)
+		if err != nil {
+			log.Printf("WebSub 发现请求失败，数据源 %s 继续轮询: %v", source.config.URL, err)
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			log.Printf("WebSub 发现读取响应失败，数据源 %s 继续轮询: %v", source.config.URL, err)
+			return
+		}
+
+		hub, self := websub.DiscoverHub(body)
+		if hub == "" {
+			log.Printf("数据源 %s 未声明 WebSub hub，继续轮询", source.config.URL)
+			return
+		}
+		topic := self
+		if topic == "" {
+			topic = source.config.URL
+		}
+		if err := websub.Subscribe(source.httpClient, hub, topic, callbackURL, leaseSeconds); err != nil {
+			log.Printf("数据源 %s 订阅 WebSub hub %s 失败，继续轮询: %v", source.config.URL, hub, err)
+			return
+		}
+		source.markWebSubActive()
+		log.Printf("数据源 %s 已通过 WebSub 订阅 hub %s，回调地址 %s", source.config.URL, hub, callbackURL)
+	}()
+}
+
+// injectState 在数据源实现了 StatefulSource 时为其注入绑定到 sourceKey 的状态命名空间；
+// 未开启 sourceState 或数据源未实现该接口时什么也不做
+func (f *Fetcher) injectState(source Source, sourceKey string) {
+	if f.sourceState == nil {
+		return
+	}
+	if stateful, ok := source.(StatefulSource); ok {
+		stateful.SetState(f.sourceState.For(sourceKey))
+	}
+}
+
+// injectCookieJar 在数据源实现了 CookieJarSource 时为其注入绑定到 sourceKey 的
+// 持久化 Cookie Jar；未开启 cookieJar 或数据源未实现该接口时什么也不做
+func (f *Fetcher) injectCookieJar(source Source, sourceKey string) {
+	if f.cookieJar == nil {
+		return
+	}
+	if jarred, ok := source.(CookieJarSource); ok {
+		jarred.SetCookieJar(f.cookieJar.For(sourceKey))
+	}
+}
+
+// applyCursor 过滤掉某个数据源中不晚于已记录游标的条目，并把本批次中最新的
+// 条目写回游标存储；未开启游标持久化时原样返回。当数据源尚无游标记录（即接入后
+// 第一次抓取）且配置了 backfill 时，按时间升序分批放行历史条目而不是一次性全量
+// 推送，游标随每轮放行的最后一条推进，中断或重启后下一轮自然从该位置继续补发
+func (f *Fetcher) applyCursor(sourceURL string, items []*models.News, backfill *config.BackfillConfig) []*models.News {
+	if f.cursors == nil {
+		return items
+	}
+
+	last, hasLast := f.cursors.Get(sourceURL)
+	newest := last
+
+	backfilling := !hasLast && backfill != nil && backfill.Enabled
+	if backfilling {
+		sort.Slice(items, func(i, j int) bool { return items[i].CreateTime.Before(items[j].CreateTime) })
+	}
+
+	// SkipSend 时本轮只用来建立游标基线，不投递任何历史条目：把游标推进到本次
+	// 抓取到的（按 MaxAge 过滤后）最新一条，此后的抓取从这个基线之后开始正常投递，
+	// 避免接入存量内容较多的数据源时第一轮就把历史内容全部刷屏推送出去
+	if backfilling && backfill.SkipSend {
+		seeded := 0
+		for _, item := range items {
+			if backfill.MaxAge > 0 && time.Since(item.CreateTime) > time.Duration(backfill.MaxAge)*time.Second {
+				continue
+			}
+			seeded++
+			if item.CreateTime.After(newest.Time) {
+				newest = cursor.Entry{ID: item.ID, Time: item.CreateTime}
+			}
+		}
+		if newest.Time.After(last.Time) {
+			if err := f.cursors.Update(sourceURL, newest); err != nil {
+				log.Printf("持久化抓取游标失败: %v", err)
+			}
+		}
+		log.Printf("数据源 %s 首次接入，已将 %d 条历史内容标记为已读但不投递", sourceURL, seeded)
+		return nil
+	}
+
+	kept := make([]*models.News, 0, len(items))
+	for _, item := range items {
+		if hasLast && (item.CreateTime.Before(last.Time) || (item.CreateTime.Equal(last.Time) && item.ID == last.ID)) {
+			continue
+		}
+		if backfilling {
+			if backfill.MaxAge > 0 && time.Since(item.CreateTime) > time.Duration(backfill.MaxAge)*time.Second {
+				continue
+			}
+			if backfill.MaxItems > 0 && len(kept) >= backfill.MaxItems {
+				break // 剩余的历史条目留到下一轮补发，本轮先推进游标到已放行的部分
+			}
+		}
+		kept = append(kept, item)
+		if item.CreateTime.After(newest.Time) {
+			newest = cursor.Entry{ID: item.ID, Time: item.CreateTime}
+		}
+	}
+
+	if newest.Time.After(last.Time) {
+		if err := f.cursors.Update(sourceURL, newest); err != nil {
+			log.Printf("持久化抓取游标失败: %v", err)
+		}
+	}
+	return kept
+}
+
+// canonicalizeLinks 去除条目链接中的追踪参数（可选跟随重定向解析出最终地址），
+// 避免同一篇文章因 utm_* 等参数不同而在链接式 ID 下被反复当作新条目；
+// 只有当 ID 本身就是由 Link 派生（没有独立 GUID）时才会同步更新 ID
+func (f *Fetcher) canonicalizeLinks(ctx context.Context, client *http.Client, items []*models.News) {
+	if f.normalize == nil || !f.normalize.Enabled {
+		return
+	}
+	for _, item := range items {
+		idWasLink := item.ID == item.Link
+
+		canonical := urlnorm.StripTrackingParams(item.Link, f.normalize.StripParams)
+		if f.normalize.ResolveRedirects {
+			if resolved, err := urlnorm.ResolveRedirect(ctx, client, canonical, f.normalize.MaxRedirectHops); err == nil {
+				canonical = urlnorm.StripTrackingParams(resolved, f.normalize.StripParams)
+			} else {
+				log.Printf("解析重定向失败，使用去参数后的地址: %s: %v", canonical, err)
+			}
+		}
+
+		item.Link = canonical
+		if idWasLink {
+			item.ID = canonical
+		}
+	}
+}
+
+// FastestInterval 返回所有数据源中最短的实际抓取间隔（秒），defaultInterval 是
+// 未单独配置 poll_interval 的数据源使用的全局抓取间隔。主循环应以该值作为定时器周期，
+// 这样 priority: high 的数据源才能按自己更短的 poll_interval 被及时轮询到
+func (f *Fetcher) FastestInterval(defaultInterval int) int {
+	fastest := defaultInterval
+	consider := func(cfg *config.SourceConfig) {
+		if cfg.Priority == "high" && cfg.PollInterval > 0 && cfg.PollInterval < fastest {
+			fastest = cfg.PollInterval
+		}
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	for _, source := range f.apiSources {
+		consider(source.config)
+	}
+	for _, source := range f.rssSources {
+		consider(source.config)
+	}
+	for _, source := range f.mockSources {
+		consider(source.config)
+	}
+	for _, entry := range f.customSources {
+		consider(entry.config)
+	}
+	return fastest
+}
+
+// Fetch 从所有数据源抓取新闻
+// scheduleWindowWeekdays 将配置中的星期缩写映射到 time.Weekday
+var scheduleWindowWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// inScheduleWindow 判断当前时间是否落在数据源配置的轮询窗口内，win 必须非空
+func inScheduleWindow(win *config.ScheduleWindowConfig) bool {
+	loc := time.Local
+	if win.Timezone != "" {
+		if l, err := time.LoadLocation(win.Timezone); err == nil {
+			loc = l
+		} else {
+			log.Printf("加载时区 %s 失败，按本地时区判断轮询窗口: %v", win.Timezone, err)
+		}
+	}
+	now := time.Now().In(loc)
+
+	if len(win.Days) > 0 {
+		allowed := false
+		for _, d := range win.Days {
+			if wd, ok := scheduleWindowWeekdays[strings.ToLower(d)]; ok && wd == now.Weekday() {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+
+	start := win.StartHour*60 + win.StartMin
+	end := win.EndHour*60 + win.EndMin
+	if start == end {
+		// 未配置起止时间，仅按 Days（如有）过滤
+		return true
+	}
+	cur := now.Hour()*60 + now.Minute()
+	if start < end {
+		return cur >= start && cur < end
+	}
+	// 跨天窗口，如 22:00-06:00
+	return cur >= start || cur < end
+}
+
+// adaptPollInterval 根据本轮是否产出了新条目，在 [MinSeconds, MaxSeconds] 范围内
+// 调整下一轮生效的轮询间隔：没有新条目就拉长，出新条目就收紧，current 为 0 时
+// 以 MinSeconds（仍为 0 则以 1 分钟）作为起点
+func adaptPollInterval(cfg *config.AdaptivePollingConfig, current time.Duration, gotNew bool) time.Duration {
+	factor := cfg.StepFactor
+	if factor <= 1 {
+		factor = 1.5
+	}
+	if current <= 0 {
+		current = time.Duration(cfg.MinSeconds) * time.Second
+		if current <= 0 {
+			current = time.Minute
+		}
+	}
+	if gotNew {
+		current = time.Duration(float64(current) / factor)
+	} else {
+		current = time.Duration(float64(current) * factor)
+	}
+	if min := time.Duration(cfg.MinSeconds) * time.Second; min > 0 && current < min {
+		current = min
+	}
+	if max := time.Duration(cfg.MaxSeconds) * time.Second; max > 0 && current > max {
+		current = max
+	}
+	return current
+}
+
+// defaultFetchConcurrency 未配置 sources.concurrency 时同时抓取的数据源数量上限
+const defaultFetchConcurrency = 8
+
+// fetchOneAPISource 处理单个 API 数据源本轮是否需要抓取、抓取、以及抓取结果的
+// 熔断/自适应轮询记账，抽成独立方法以便在 Fetch 里被并发调用
+func (f *Fetcher) fetchOneAPISource(ctx context.Context, source *APISource) []*models.News {
+	interval := source.pollInterval
+	adaptive := source.config.AdaptivePolling
+	if adaptive != nil && adaptive.Enabled {
+		interval = source.effectiveInterval
+	}
+	if interval > 0 && time.Since(source.lastFetch) < interval {
+		return nil
+	}
+	if source.config.ScheduleWindow != nil && !inScheduleWindow(source.config.ScheduleWindow) {
+		return nil
+	}
+	if f.health.Disabled(source.config.URL) {
+		log.Printf("数据源 %s 已因长期失效被自动禁用，本轮跳过", source.config.URL)
+		return nil
+	}
+	if source.breaker != nil && !source.breaker.Allow() {
+		log.Printf("数据源 %s 已熔断，本轮跳过", source.config.URL)
+		return nil
+	}
+	if source.rateLimiter != nil && !source.rateLimiter.Allow() {
+		log.Printf("数据源 %s 已达到限速上限，本轮跳过", source.config.URL)
+		return nil
+	}
+	if !source.budget.TryConsume(fetchBudgetKey, source.config.FetchDailyBudget) {
+		log.Printf("数据源 %s 已达到每日抓取额度上限（%d 次），本轮跳过", source.config.URL, source.config.FetchDailyBudget)
+		eventhook.Emit("budget_exhausted", source.config.URL, fmt.Sprintf("每日抓取额度已用尽（%d 次）", source.config.FetchDailyBudget))
+		return nil
+	}
+	log.Printf("开始从 %s 获取新闻...", source.config.URL)
+	fetchStart := time.Now()
+	news, err := source.Fetch(ctx)
+	source.lastFetch = time.Now()
+	if err != nil {
+		log.Printf("从 %s 获取新闻失败: %v", source.config.URL, err)
+		recordBreakerFailure(source.breaker, source.config.URL, err)
+		f.health.RecordFailure(source.config.URL)
+		return nil
+	}
+	f.health.RecordSuccess(source.config.URL, time.Since(fetchStart))
+	recordBreakerSuccess(source.breaker, source.config.URL)
+	assignTraceIDs(news)
+	applyFullContentExtraction(ctx, source.httpClient, source.config, news)
+	applyContentCleanup(source.config.ContentCleanup, news)
+	news = applyQualityGate(source.config.QualityGate, news)
+	if adaptive != nil && adaptive.Enabled {
+		source.effectiveInterval = adaptPollInterval(adaptive, source.effectiveInterval, len(news) > 0)
+		log.Printf("数据源 %s 自适应轮询间隔调整为 %s", source.config.URL, source.effectiveInterval)
+	}
+	logFetchSummary(source.config.URL, news)
+	return news
+}
+
+// fetchOneRSSSource 是 fetchOneAPISource 的 RSS 版本，额外负责链接规范化和游标过滤
+func (f *Fetcher) fetchOneRSSSource(ctx context.Context, source *RSSSource) []*models.News {
+	pushPending := source.hasPushedBody()
+	interval := source.pollInterval
+	adaptive := source.config.AdaptivePolling
+	if adaptive != nil && adaptive.Enabled {
+		interval = source.effectiveInterval
+	} else if source.isWebSubActive() {
+		interval = source.effectiveInterval
+	}
+	// 已有 WebSub 推送到达时不受轮询间隔限制，尽快处理，避免排队等到下一个轮询窗口
+	if !pushPending && interval > 0 && time.Since(source.lastFetch) < interval {
+		return nil
+	}
+	if source.config.ScheduleWindow != nil && !inScheduleWindow(source.config.ScheduleWindow) {
+		return nil
+	}
+	if f.health.Disabled(source.config.URL) {
+		log.Printf("数据源 %s 已因长期失效被自动禁用，本轮跳过", source.config.URL)
+		return nil
+	}
+	if source.breaker != nil && !source.breaker.Allow() {
+		log.Printf("数据源 %s 已熔断，本轮跳过", source.config.URL)
+		return nil
+	}
+	if source.rateLimiter != nil && !source.rateLimiter.Allow() {
+		log.Printf("数据源 %s 已达到限速上限，本轮跳过", source.config.URL)
+		return nil
+	}
+	if !source.budget.TryConsume(fetchBudgetKey, source.config.FetchDailyBudget) {
+		log.Printf("数据源 %s 已达到每日抓取额度上限（%d 次），本轮跳过", source.config.URL, source.config.FetchDailyBudget)
+		eventhook.Emit("budget_exhausted", source.config.URL, fmt.Sprintf("每日抓取额度已用尽（%d 次）", source.config.FetchDailyBudget))
+		return nil
+	}
+	fetchStart := time.Now()
+	news, err := source.Fetch(ctx)
+	source.lastFetch = time.Now()
+	if err != nil {
+		log.Printf("从RSS源抓取新闻失败: %v", err)
+		recordBreakerFailure(source.breaker, source.config.URL, err)
+		f.health.RecordFailure(source.config.URL)
+		return nil
+	}
+	f.health.RecordSuccess(source.config.URL, time.Since(fetchStart))
+	recordBreakerSuccess(source.breaker, source.config.URL)
+	assignTraceIDs(news)
+	applyFullContentExtraction(ctx, source.httpClient, source.config, news)
+	applyContentCleanup(source.config.ContentCleanup, news)
+	news = applyQualityGate(source.config.QualityGate, news)
+	f.canonicalizeLinks(ctx, source.httpClient, news)
+	news = f.applyCursor(source.config.URL, news, source.config.Backfill)
+	if adaptive != nil && adaptive.Enabled {
+		source.effectiveInterval = adaptPollInterval(adaptive, source.effectiveInterval, len(news) > 0)
+		log.Printf("数据源 %s 自适应轮询间隔调整为 %s", source.config.URL, source.effectiveInterval)
+	}
+	logFetchSummary(source.config.URL, news)
+	return news
 }
 
-// RSSSource RSS数据源
-type RSSSource struct {
-	config     *config.SourceConfig
-	proxyURL   string
-	httpClient *http.Client
+// fetchOneHNSource 是 fetchOneAPISource 的 Hacker News 版本，抓取/熔断/自适应轮询
+// 的记账逻辑与 API 数据源完全一致
+func (f *Fetcher) fetchOneHNSource(ctx context.Context, source *HNSource) []*models.News {
+	interval := source.pollInterval
+	adaptive := source.config.AdaptivePolling
+	if adaptive != nil && adaptive.Enabled {
+		interval = source.effectiveInterval
+	}
+	if interval > 0 && time.Since(source.lastFetch) < interval {
+		return nil
+	}
+	if source.config.ScheduleWindow != nil && !inScheduleWindow(source.config.ScheduleWindow) {
+		return nil
+	}
+	if f.health.Disabled(source.config.URL) {
+		log.Printf("数据源 %s 已因长期失效被自动禁用，本轮跳过", source.config.URL)
+		return nil
+	}
+	if source.breaker != nil && !source.breaker.Allow() {
+		log.Printf("数据源 %s 已熔断，本轮跳过", source.config.URL)
+		return nil
+	}
+	if source.rateLimiter != nil && !source.rateLimiter.Allow() {
+		log.Printf("数据源 %s 已达到限速上限，本轮跳过", source.config.URL)
+		return nil
+	}
+	if !source.budget.TryConsume(fetchBudgetKey, source.config.FetchDailyBudget) {
+		log.Printf("数据源 %s 已达到每日抓取额度上限（%d 次），本轮跳过", source.config.URL, source.config.FetchDailyBudget)
+		eventhook.Emit("budget_exhausted", source.config.URL, fmt.Sprintf("每日抓取额度已用尽（%d 次）", source.config.FetchDailyBudget))
+		return nil
+	}
+	fetchStart := time.Now()
+	news, err := source.Fetch(ctx)
+	source.lastFetch = time.Now()
+	if err != nil {
+		log.Printf("从 %s 获取新闻失败: %v", source.config.URL, err)
+		recordBreakerFailure(source.breaker, source.config.URL, err)
+		f.health.RecordFailure(source.config.URL)
+		return nil
+	}
+	f.health.RecordSuccess(source.config.URL, time.Since(fetchStart))
+	recordBreakerSuccess(source.breaker, source.config.URL)
+	assignTraceIDs(news)
+	applyFullContentExtraction(ctx, source.httpClient, source.config, news)
+	applyContentCleanup(source.config.ContentCleanup, news)
+	news = applyQualityGate(source.config.QualityGate, news)
+	if adaptive != nil && adaptive.Enabled {
+		source.effectiveInterval = adaptPollInterval(adaptive, source.effectiveInterval, len(news) > 0)
+		log.Printf("数据源 %s 自适应轮询间隔调整为 %s", source.config.URL, source.effectiveInterval)
+	}
+	logFetchSummary(source.config.URL, news)
+	return news
 }
 
-// NewFetcher 创建新闻抓取器
-func NewFetcher(cfg *config.SourcesConfig) (*Fetcher, error) {
-	// 创建HTTP客户端
-	client := &http.Client{
-		Transport: &http.Transport{
-			MaxIdleConns:        100,
-			MaxIdleConnsPerHost: 20,
-			IdleConnTimeout:     30 * time.Second,
-		},
+// fetchOneMockSource 是 fetchOneAPISource 的 mock 版本，不涉及熔断和自适应轮询
+func (f *Fetcher) fetchOneMockSource(ctx context.Context, source *MockSource) []*models.News {
+	if source.pollInterval > 0 && time.Since(source.lastFetch) < source.pollInterval {
+		return nil
 	}
-
-	// 初始化API数据源
-	var apiSources []*APISource
-	for _, apiCfg := range cfg.API {
-		source := NewAPISource(apiCfg, client)
-		apiSources = append(apiSources, source)
+	if source.config.ScheduleWindow != nil && !inScheduleWindow(source.config.ScheduleWindow) {
+		return nil
 	}
-
-	// 初始化RSS数据源
-	var rssSources []*RSSSource
-	for _, rssCfg := range cfg.RSS {
-		source := NewRSSSource(rssCfg)
-		rssSources = append(rssSources, source)
+	news, err := source.Fetch(ctx)
+	source.lastFetch = time.Now()
+	if err != nil {
+		log.Printf("从mock数据源读取新闻失败: %v", err)
+		return nil
 	}
-
-	return &Fetcher{
-		apiSources: apiSources,
-		rssSources: rssSources,
-		client:     client,
-	}, nil
+	assignTraceIDs(news)
+	applyContentCleanup(source.config.ContentCleanup, news)
+	news = applyQualityGate(source.config.QualityGate, news)
+	logFetchSummary(source.config.URL, news)
+	return news
 }
 
-// NewAPISource 创建API数据源
-func NewAPISource(cfg *config.SourceConfig, client *http.Client) *APISource {
-	return &APISource{
-		config:     cfg,
-		httpClient: client,
+// fetchOneCustomSource 是 fetchOneMockSource 的第三方数据源版本：只负责通用的
+// 轮询节流/时间窗口/质量门槛处理，具体抓取逻辑完全交给 Register 注册的构造函数
+// 返回的 Source 实现，不涉及内置数据源专属的熔断、自适应轮询
+func (f *Fetcher) fetchOneCustomSource(ctx context.Context, entry *customSourceEntry) []*models.News {
+	if entry.pollInterval > 0 && time.Since(entry.lastFetch) < entry.pollInterval {
+		return nil
+	}
+	if entry.config.ScheduleWindow != nil && !inScheduleWindow(entry.config.ScheduleWindow) {
+		return nil
+	}
+	news, err := entry.source.Fetch(ctx)
+	entry.lastFetch = time.Now()
+	if err != nil {
+		log.Printf("从自定义数据源抓取失败（type=%s）: %s: %v", entry.config.Type, entry.config.URL, err)
+		return nil
 	}
+	assignTraceIDs(news)
+	applyContentCleanup(entry.config.ContentCleanup, news)
+	news = applyQualityGate(entry.config.QualityGate, news)
+	news = applyMaxAgeAndItems(entry.config, news)
+	logFetchSummary(entry.config.URL, news)
+	return news
 }
 
-// NewRSSSource 创建RSS数据源
-func NewRSSSource(cfg *config.SourceConfig) *RSSSource {
-	// 创建 HTTP 客户端
-	transport := &http.Transport{
-		MaxIdleConns:        100,
-		MaxIdleConnsPerHost: 20,
-		IdleConnTimeout:     30 * time.Second,
+// applyContentCleanup 按数据源各自配置的清洗规则处理正文，用于在翻译/AI分析之前
+// 去除转载版权声明、固定尾巴等噪声；未开启清洗时原样返回，规则编译失败时记录日志
+// 并跳过本轮清洗，不影响新闻本身的抓取
+func applyContentCleanup(cfg *config.ContentCleanupConfig, news []*models.News) {
+	cleaner, err := contentcleanup.New(cfg)
+	if err != nil {
+		log.Printf("初始化正文清洗规则失败，本轮跳过清洗: %v", err)
+		return
+	}
+	if cleaner == nil {
+		return
 	}
+	for _, n := range news {
+		n.OriginalContent = cleaner.Clean(n.OriginalContent)
+	}
+}
 
-	// 配置代理
-	if cfg.ProxyURL != "" {
-		if proxyURL, err := url.Parse(cfg.ProxyURL); err == nil {
-			transport.Proxy = http.ProxyURL(proxyURL)
-			log.Printf("已配置代理: %s", cfg.ProxyURL)
-		} else {
-			log.Printf("解析代理 URL 失败: %v", err)
+// applyQualityGate 按数据源各自配置的质量门槛丢弃正文过短或缺少链接的占位条目，
+// 避免它们进入后续的翻译/AI分析环节浪费额度、甚至污染频道；未开启时原样返回
+func applyQualityGate(cfg *config.QualityGateConfig, news []*models.News) []*models.News {
+	if cfg == nil || !cfg.Enabled {
+		return news
+	}
+	kept := make([]*models.News, 0, len(news))
+	for _, n := range news {
+		if cfg.RequireLink && n.Link == "" {
+			eventlog.Debugf("[质量门槛] 丢弃缺少链接的条目: %s", n.OriginalTitle)
+			continue
 		}
+		if cfg.MinContentLength > 0 && len([]rune(strings.TrimSpace(n.OriginalContent))) < cfg.MinContentLength {
+			eventlog.Debugf("[质量门槛] 丢弃正文过短的条目（%s）", n.OriginalTitle)
+			continue
+		}
+		kept = append(kept, n)
 	}
+	return kept
+}
 
-	client := &http.Client{
-		Transport: transport,
-		Timeout:   time.Duration(cfg.Timeout) * time.Second,
+// assignTraceIDs 为本轮抓取到的每条新闻生成一个全局唯一的 TraceID，贯穿后续
+// 翻译、AI分析、发送等各阶段的日志、归档记录和 Webhook 负载，用于跨阶段
+// 重建单条新闻的完整处理链路；已经带有 TraceID 的条目（如 WebSub 推送复用
+// 了同一个 News 对象）不会被覆盖
+func assignTraceIDs(news []*models.News) {
+	for _, n := range news {
+		if n.TraceID == "" {
+			n.TraceID = uuid.NewString()
+		}
 	}
+}
 
-	return &RSSSource{
-		config:     cfg,
-		proxyURL:   cfg.ProxyURL,
-		httpClient: client,
+// logFetchSummary 输出该数据源本轮抓取（经质量门槛/游标过滤后）的汇总日志：
+// 数量以及首尾条目标题，取代逐条目打印详情的方式，避免高产出数据源一轮刷出
+// 成百上千行日志；需要逐条目细节时把 app.log_level 配置为 "debug"
+func logFetchSummary(sourceURL string, news []*models.News) {
+	switch len(news) {
+	case 0:
+		log.Printf("数据源 %s 本轮未获取到新闻", sourceURL)
+	case 1:
+		log.Printf("数据源 %s 本轮获取到 1 条新闻: %s", sourceURL, news[0].OriginalTitle)
+	default:
+		log.Printf("数据源 %s 本轮获取到 %d 条新闻，首条: %s，末条: %s",
+			sourceURL, len(news), news[0].OriginalTitle, news[len(news)-1].OriginalTitle)
 	}
 }
 
-// Fetch 从所有数据源抓取新闻
-func (f *Fetcher) Fetch(ctx context.Context) ([]*models.News, error) {
-	var allNews []*models.News
-
-	// 从API源抓取
-	for _, source := range f.apiSources {
-		log.Printf("开始从 %s 获取新闻...", source.config.URL)
-		news, err := source.Fetch(ctx)
+// applyFullContentExtraction 对开启了 FetchFullContent 的数据源，额外请求每条新闻
+// 的 Link 页面并用 extract.Article 提取正文覆盖 OriginalContent，弥补 RSS/API
+// 摘要经常被截断的问题；单条提取失败只记录日志、保留原有摘要，不影响其余条目
+func applyFullContentExtraction(ctx context.Context, httpClient *http.Client, cfg *config.SourceConfig, news []*models.News) {
+	if cfg == nil || !cfg.FetchFullContent {
+		return
+	}
+	for _, n := range news {
+		if n.Link == "" {
+			continue
+		}
+		content, err := extract.Article(ctx, httpClient, n.Link)
 		if err != nil {
-			log.Printf("从 %s 获取新闻失败: %v", source.config.URL, err)
+			log.Printf("提取正文失败 [%s]: %v", n.Link, err)
 			continue
 		}
-		log.Printf("从 %s 获取到 %d 条新闻", source.config.URL, len(news))
-		allNews = append(allNews, news...)
+		if content != "" {
+			n.OriginalContent = content
+		}
 	}
+}
 
-	// 从RSS源抓取
-	for _, source := range f.rssSources {
-		news, err := source.Fetch(ctx)
-		if err != nil {
-			log.Printf("从RSS源抓取新闻失败: %v", err)
-			continue
+// Fetch 并发地从所有数据源抓取新闻：每个数据源各自的轮询间隔/时间窗口/熔断判断
+// 与实际抓取都在各自的 goroutine 里完成，一个慢源不会拖慢其余数据源，
+// 并发数由 sources.concurrency 配置，未配置时使用 defaultFetchConcurrency
+func (f *Fetcher) Fetch(ctx context.Context) ([]*models.News, error) {
+	limit := f.concurrency
+	if limit <= 0 {
+		limit = defaultFetchConcurrency
+	}
+
+	// rssSources 可能被 /subscribe /unsubscribe 并发修改，先取一份快照
+	f.mu.RLock()
+	rssSources := append([]*RSSSource(nil), f.rssSources...)
+	f.mu.RUnlock()
+
+	var mu sync.Mutex
+	var allNews []*models.News
+	collect := func(news []*models.News) {
+		if len(news) == 0 {
+			return
 		}
+		mu.Lock()
 		allNews = append(allNews, news...)
+		mu.Unlock()
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(limit)
+
+	for _, source := range f.apiSources {
+		source := source
+		g.Go(func() error {
+			collect(f.fetchOneAPISource(gctx, source))
+			return nil
+		})
+	}
+	for _, source := range rssSources {
+		source := source
+		g.Go(func() error {
+			collect(f.fetchOneRSSSource(gctx, source))
+			return nil
+		})
+	}
+	for _, source := range f.mockSources {
+		source := source
+		g.Go(func() error {
+			collect(f.fetchOneMockSource(gctx, source))
+			return nil
+		})
+	}
+	for _, source := range f.hnSources {
+		source := source
+		g.Go(func() error {
+			collect(f.fetchOneHNSource(gctx, source))
+			return nil
+		})
+	}
+	for _, entry := range f.customSources {
+		entry := entry
+		g.Go(func() error {
+			collect(f.fetchOneCustomSource(gctx, entry))
+			return nil
+		})
 	}
 
+	// 单个数据源的错误已经在各自的 fetchOne* 方法里记账和跳过，g.Wait() 这里
+	// 不会因为某个数据源失败而返回错误，只用于等待全部 goroutine 结束
+	_ = g.Wait()
+
 	if len(allNews) == 0 {
 		log.Println("未获取到任何新闻")
 		return nil, nil
@@ -147,8 +1327,140 @@ func (f *Fetcher) Fetch(ctx context.Context) ([]*models.News, error) {
 	return allNews, nil
 }
 
+// AddRSSSource 在运行期添加一个 RSS 数据源，添加前会先尝试抓取并解析一次以校验 URL 有效，
+// 供 /subscribe 命令等运行期扩展场景使用
+func (f *Fetcher) AddRSSSource(ctx context.Context, cfg *config.SourceConfig) error {
+	if cfg.Retry == nil {
+		cfg.Retry = &config.RetryConfig{Count: 1, Interval: 2}
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 15
+	}
+
+	source, err := NewRSSSource(cfg, f.poolCfg, f.proxyPool, f.userAgent, f.headerProfile)
+	if err != nil {
+		return fmt.Errorf("创建RSS数据源失败: %v", err)
+	}
+	f.injectState(source, cfg.URL)
+	f.injectCookieJar(source, cfg.URL)
+
+	if _, err := source.Fetch(ctx); err != nil {
+		return fmt.Errorf("校验RSS源失败: %v", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, existing := range f.rssSources {
+		if existing.config.URL == cfg.URL {
+			return fmt.Errorf("该RSS源已订阅: %s", cfg.URL)
+		}
+	}
+	f.rssSources = append(f.rssSources, source)
+	return nil
+}
+
+// RemoveRSSSource 移除一个运行期添加的 RSS 数据源，未找到时返回 false
+func (f *Fetcher) RemoveRSSSource(url string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, source := range f.rssSources {
+		if source.config.URL == url {
+			f.rssSources = append(f.rssSources[:i], f.rssSources[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// RSSSourceURLs 返回当前全部 RSS 数据源的 URL 列表
+func (f *Fetcher) RSSSourceURLs() []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	urls := make([]string, len(f.rssSources))
+	for i, source := range f.rssSources {
+		urls[i] = source.config.URL
+	}
+	return urls
+}
+
+// SourceCount 返回当前已启用的数据源总数（API+RSS+mock+Hacker News+自定义），
+// 供启动通知一类的场景展示概况
+func (f *Fetcher) SourceCount() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return len(f.apiSources) + len(f.rssSources) + len(f.mockSources) + len(f.hnSources) + len(f.customSources)
+}
+
+// BreakerStatus 返回每个开启了熔断的数据源（以 URL 为键）当前的熔断状态快照，
+// 供 /status 一类的只读查询使用；未开启熔断的数据源不出现在返回结果中
+func (f *Fetcher) BreakerStatus() map[string]breaker.Snapshot {
+	status := make(map[string]breaker.Snapshot)
+
+	f.mu.RLock()
+	rssSources := append([]*RSSSource(nil), f.rssSources...)
+	f.mu.RUnlock()
+
+	for _, source := range f.apiSources {
+		if source.breaker != nil {
+			status[source.config.URL] = source.breaker.Snapshot()
+		}
+	}
+	for _, source := range rssSources {
+		if source.breaker != nil {
+			status[source.config.URL] = source.breaker.Snapshot()
+		}
+	}
+	for _, source := range f.hnSources {
+		if source.breaker != nil {
+			status[source.config.URL] = source.breaker.Snapshot()
+		}
+	}
+	return status
+}
+
+// HealthStatus 返回每个已尝试过抓取的数据源（以 URL 为键）当前的健康状况快照
+// （成功率、平均延迟、最近一次成功时间、是否已因长期失效被自动禁用），供
+// /status 一类的只读查询和日志排查使用
+func (f *Fetcher) HealthStatus() map[string]health.Snapshot {
+	return f.health.Snapshot()
+}
+
+// FetchBudgetStatus 返回每个配置了 FetchDailyBudget 的数据源（以 URL 为键）当天
+// 已消耗的抓取请求次数及上限，供 /budget 一类的只读查询使用；未配置额度的数据源
+// 不出现在返回结果中
+func (f *Fetcher) FetchBudgetStatus() map[string]budget.Snapshot {
+	status := make(map[string]budget.Snapshot)
+
+	f.mu.RLock()
+	rssSources := append([]*RSSSource(nil), f.rssSources...)
+	f.mu.RUnlock()
+
+	collect := func(url string, limit int, b *budget.Tracker) {
+		if limit <= 0 {
+			return
+		}
+		status[url] = b.Usage()[fetchBudgetKey]
+	}
+
+	for _, source := range f.apiSources {
+		collect(source.config.URL, source.config.FetchDailyBudget, source.budget)
+	}
+	for _, source := range rssSources {
+		collect(source.config.URL, source.config.FetchDailyBudget, source.budget)
+	}
+	for _, source := range f.hnSources {
+		collect(source.config.URL, source.config.FetchDailyBudget, source.budget)
+	}
+	return status
+}
+
 // Fetch 从API数据源抓取新闻
 func (s *APISource) Fetch(ctx context.Context) ([]*models.News, error) {
+	if err := s.ensureLoggedIn(ctx); err != nil {
+		return nil, fmt.Errorf("数据源登录失败: %v", err)
+	}
+
+	start := time.Now()
 	log.Printf("开始请求API: %s", s.config.URL)
 
 	// 添加请求参数
@@ -166,26 +1478,81 @@ func (s *APISource) Fetch(ctx context.Context) ([]*models.News, error) {
 		log.Printf("请求URL（带参数）: %s", reqURL)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	method := s.config.Method
+	if method == "" {
+		method = "GET"
+	}
+
+	var reqBody io.Reader
+	if method == "POST" && s.config.Body != "" {
+		reqBody = strings.NewReader(s.config.Body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("创建请求失败: %v", err)
 	}
 
-	// 添加请求头
+	if reqBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// 套用 user_agent/header_profile 解析出的默认请求头，放在 Content-Type 之后、
+	// 显式配置的 Headers 之前，让 Headers 里的同名字段始终能覆盖这里的默认值
+	for key, value := range s.extraHeaders {
+		req.Header.Set(key, value)
+	}
+
+	// 添加请求头，放在默认 Content-Type 之后，允许显式配置覆盖
 	for key, value := range s.config.Headers {
-		req.Header.Add(key, value)
+		req.Header.Set(key, value)
 		log.Printf("添加请求头: %s = %s", key, value)
 	}
 
+	// 开启了逐数据源状态持久化时附带上次记录的 ETag/Last-Modified，命中 304
+	// 可以让服务端跳过内容传输
+	if s.state != nil {
+		if etag, ok := s.state.Get("etag"); ok && etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified, ok := s.state.Get("last_modified"); ok && lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
 	// 发送请求
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
+		eventlog.Event(s.config.URL, "fetch_api", "", "error", time.Since(start))
 		return nil, fmt.Errorf("发送请求失败: %v", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified {
+		log.Printf("API 响应未发生变化（304），本轮跳过: %s", s.config.URL)
+		return nil, nil
+	}
+
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API响应状态码异常: %d", resp.StatusCode)
+		eventlog.Event(s.config.URL, "fetch_api", "", "error", time.Since(start))
+		err := fmt.Errorf("API响应状态码异常: %d", resp.StatusCode)
+		if isPermanentStatus(resp.StatusCode) {
+			return nil, permanentError(err)
+		}
+		return nil, err
+	}
+
+	if s.state != nil {
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			if err := s.state.Set("etag", etag); err != nil {
+				log.Printf("持久化 ETag 失败: %v", err)
+			}
+		}
+		if lastModified := resp.Header.Get("Last-Modified"); lastModified != "" {
+			if err := s.state.Set("last_modified", lastModified); err != nil {
+				log.Printf("持久化 Last-Modified 失败: %v", err)
+			}
+		}
 	}
 
 	log.Printf("API请求成功")
@@ -193,19 +1560,68 @@ func (s *APISource) Fetch(ctx context.Context) ([]*models.News, error) {
 	// 读取响应内容
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
+		eventlog.Event(s.config.URL, "fetch_api", "", "error", time.Since(start))
 		return nil, fmt.Errorf("读取响应失败: %v", err)
 	}
 
-	// 打印响应内容
-	log.Printf("API响应内容: %s", string(body))
+	// 原始响应体默认不打日志，仅在开启 debug_dump 时输出（并做截断），避免正文泄露
+	eventlog.Dump("api_response:"+s.config.URL, string(body))
+
+	// 开启了逐数据源状态持久化时，对响应体哈希后与上一轮比对：完全没有更新的接口
+	// 直接跳过后续的 JSON 解析和条目处理，省下空转的 CPU 和下游重复判重开销
+	if s.state != nil {
+		hash := fmt.Sprintf("%x", sha256.Sum256(body))
+		if prev, ok := s.state.Get("body_hash"); ok && prev == hash {
+			log.Printf("API 响应内容与上一轮完全一致，本轮跳过解析: %s", s.config.URL)
+			return nil, nil
+		}
+		if err := s.state.Set("body_hash", hash); err != nil {
+			log.Printf("持久化响应体哈希失败: %v", err)
+		}
+	}
+
+	// 配置了外部自定义解析命令时优先级最高，忽略 mapping 和内置默认解析逻辑，
+	// 把原始响应体交给用户自己的脚本/可执行文件处理，用于内置字段映射仍无法
+	// 覆盖的畸形/私有格式数据源
+	if s.config.ParserCommand != "" {
+		newsList, err := parseWithCustomCommand(ctx, s.config, body)
+		if err != nil {
+			eventlog.Event(s.config.URL, "fetch_api", "", "error", time.Since(start))
+			return nil, err
+		}
+		newsList = applyMaxAgeAndItems(s.config, newsList)
+		for _, news := range newsList {
+			eventlog.Event(s.config.URL, "fetch_api", fmt.Sprintf("%v", news.ID), "success", time.Since(start))
+		}
+		log.Printf("成功解析 %d 条新闻", len(newsList))
+		return newsList, nil
+	}
+
+	// 配置了 mapping 时按自定义字段路径解析任意形状的 JSON 响应；未配置时沿用
+	// 内置的 {status, data.list[].{id,title,content}} 默认解析逻辑，完全兼容原有行为
+	if s.config.Mapping != nil {
+		newsList, err := parseMappedAPIResponse(body, s.config.Mapping, s.config.URL)
+		if err != nil {
+			eventlog.Event(s.config.URL, "fetch_api", "", "error", time.Since(start))
+			return nil, err
+		}
+		newsList = applyMaxAgeAndItems(s.config, newsList)
+		for _, news := range newsList {
+			eventlog.Event(s.config.URL, "fetch_api", fmt.Sprintf("%v", news.ID), "success", time.Since(start))
+		}
+		log.Printf("成功解析 %d 条新闻", len(newsList))
+		return newsList, nil
+	}
 
 	// 解析响应
 	var response models.APIResponse
 	if err := json.Unmarshal(body, &response); err != nil {
+		eventlog.Event(s.config.URL, "fetch_api", "", "error", time.Since(start))
 		return nil, fmt.Errorf("解析响应失败: %v", err)
 	}
 
 	if response.Status != 0 {
+		eventlog.Event(s.config.URL, "fetch_api", "", "error", time.Since(start))
 		return nil, fmt.Errorf("API返回错误: %s", response.Message)
 	}
 
@@ -220,15 +1636,62 @@ func (s *APISource) Fetch(ctx context.Context) ([]*models.News, error) {
 			CreateTime:      time.Now(),
 		}
 		newsList = append(newsList, news)
-		log.Printf("解析新闻: %s (ID: %v)", news.OriginalTitle, news.ID)
+	}
+	newsList = applyMaxAgeAndItems(s.config, newsList)
+	for _, news := range newsList {
+		eventlog.Event(s.config.URL, "fetch_api", fmt.Sprintf("%v", news.ID), "success", time.Since(start))
 	}
 
 	log.Printf("成功解析 %d 条新闻", len(newsList))
 	return newsList, nil
 }
 
+// applyMaxAgeAndItems 按数据源配置的 max_age/max_items 丢弃过旧条目、截断超出
+// 上限的部分，RSS（newsFromFeed）和 mock 固定文件已经各自实现了同样的过滤，
+// 这里补齐 API 数据源三条解析路径（自定义命令、mapping、内置默认格式）欠缺的部分，
+// 避免历史记录很长的接口把过期或超量的条目一次性灌进后续流水线
+func applyMaxAgeAndItems(cfg *config.SourceConfig, newsList []*models.News) []*models.News {
+	var minCreateTime time.Time
+	if cfg.MaxAge > 0 {
+		minCreateTime = time.Now().Add(-time.Duration(cfg.MaxAge) * time.Second)
+	}
+
+	var filtered []*models.News
+	for _, news := range newsList {
+		if !minCreateTime.IsZero() && news.CreateTime.Before(minCreateTime) {
+			continue
+		}
+		filtered = append(filtered, news)
+		if cfg.MaxItems > 0 && len(filtered) >= cfg.MaxItems {
+			break
+		}
+	}
+	return filtered
+}
+
 // Fetch 从RSS数据源抓取新闻
 func (s *RSSSource) Fetch(ctx context.Context) ([]*models.News, error) {
+	if err := s.ensureLoggedIn(ctx); err != nil {
+		return nil, fmt.Errorf("数据源登录失败: %v", err)
+	}
+
+	// 有尚未消费的 WebSub 推送内容时优先解析它，不发起 HTTP 请求
+	if pushed := s.takePushedBody(); pushed != nil {
+		log.Printf("使用 WebSub 推送内容代替轮询: %s", s.config.URL)
+		// 推送通道不带 HTTP 响应头，无法判断 Content-Encoding，这里只做 charset 转换
+		pushed = decodeFeedBody(pushed, "", "")
+		feed, err := gofeed.NewParser().Parse(bytes.NewReader(pushed))
+		if err != nil && s.config.Lenient {
+			feed, err = s.parseLenient(pushed, err)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解析 WebSub 推送内容失败: %v", err)
+		}
+		newsList := s.newsFromFeed(feed)
+		log.Printf("从 WebSub 推送解析出 %d 条新闻", len(newsList))
+		return newsList, nil
+	}
+
 	log.Printf("开始抓取 RSS 源: %s", s.config.URL)
 
 	var feed *gofeed.Feed
@@ -243,8 +1706,40 @@ func (s *RSSSource) Fetch(ctx context.Context) ([]*models.News, error) {
 			return nil, fmt.Errorf("创建请求失败: %v", err)
 		}
 
+		// 套用 user_agent/header_profile 解析出的默认请求头，放在显式配置的
+		// Headers 之前，让 Headers 里的同名字段始终能覆盖这里的默认值
+		for key, value := range s.extraHeaders {
+			req.Header.Set(key, value)
+		}
+
+		// 附加认证信息，支持需要 Basic Auth、Cookie 或自定义请求头才能访问的付费 RSS 源
+		for key, value := range s.config.Headers {
+			req.Header.Add(key, value)
+		}
+		if s.config.BasicAuth != nil {
+			req.SetBasicAuth(s.config.BasicAuth.Username, s.config.BasicAuth.Password)
+		}
+		for name, value := range s.config.Cookies {
+			req.AddCookie(&http.Cookie{Name: name, Value: value})
+		}
+
+		// 开启了逐数据源状态持久化时附带上次记录的 ETag/Last-Modified，命中 304
+		// 可以让服务端跳过内容传输，减少无更新时的带宽消耗和被限流风险
+		if s.state != nil {
+			if etag, ok := s.state.Get("etag"); ok && etag != "" {
+				req.Header.Set("If-None-Match", etag)
+			}
+			if lastModified, ok := s.state.Get("last_modified"); ok && lastModified != "" {
+				req.Header.Set("If-Modified-Since", lastModified)
+			}
+		}
+
 		resp, err := s.httpClient.Do(req)
 		if err != nil {
+			if isPermanentErr(err) {
+				log.Printf("请求失败且判定为永久性错误，不再重试: %v", err)
+				return nil, permanentError(fmt.Errorf("发送请求失败: %v", err))
+			}
 			retryCount++
 			if retryCount <= maxRetries {
 				log.Printf("发送请求失败，正在重试 (%d/%d): %v", retryCount, maxRetries, err)
@@ -256,7 +1751,16 @@ func (s *RSSSource) Fetch(ctx context.Context) ([]*models.News, error) {
 		}
 		defer resp.Body.Close()
 
+		if resp.StatusCode == http.StatusNotModified {
+			log.Printf("RSS 源未发生变化（304），本轮跳过: %s", s.config.URL)
+			return nil, nil
+		}
+
 		if resp.StatusCode != http.StatusOK {
+			if isPermanentStatus(resp.StatusCode) {
+				log.Printf("请求失败，状态码: %d，判定为永久性错误，不再重试", resp.StatusCode)
+				return nil, permanentError(fmt.Errorf("请求失败，状态码: %d", resp.StatusCode))
+			}
 			retryCount++
 			if retryCount <= maxRetries {
 				log.Printf("请求失败，状态码: %d，正在重试 (%d/%d)", resp.StatusCode, retryCount, maxRetries)
@@ -267,9 +1771,42 @@ func (s *RSSSource) Fetch(ctx context.Context) ([]*models.News, error) {
 			return nil, fmt.Errorf("请求失败，状态码: %d", resp.StatusCode)
 		}
 
+		if s.state != nil {
+			if etag := resp.Header.Get("ETag"); etag != "" {
+				if err := s.state.Set("etag", etag); err != nil {
+					log.Printf("持久化 ETag 失败: %v", err)
+				}
+			}
+			if lastModified := resp.Header.Get("Last-Modified"); lastModified != "" {
+				if err := s.state.Set("last_modified", lastModified); err != nil {
+					log.Printf("持久化 Last-Modified 失败: %v", err)
+				}
+			}
+		}
+
+		// 缓冲响应体，以便解析失败时可以在 lenient 模式下对同一份原始数据
+		// 尝试修复实体后重新解析，而不必重新发起一次请求
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			retryCount++
+			if retryCount <= maxRetries {
+				log.Printf("读取响应体失败，正在重试 (%d/%d): %v", retryCount, maxRetries, err)
+				time.Sleep(time.Duration(s.config.Retry.Interval) * time.Second)
+				continue
+			}
+			log.Printf("读取响应体失败，已达到最大重试次数: %v", err)
+			return nil, fmt.Errorf("读取响应体失败: %v", err)
+		}
+
+		// 透明解压 gzip/deflate 并将非 UTF-8 编码转换为 UTF-8，避免 gofeed 解析失败
+		body = decodeFeedBody(body, resp.Header.Get("Content-Encoding"), resp.Header.Get("Content-Type"))
+
 		// 解析 RSS
 		fp := gofeed.NewParser()
-		feed, err = fp.Parse(resp.Body)
+		feed, err = fp.Parse(bytes.NewReader(body))
+		if err != nil && s.config.Lenient {
+			feed, err = s.parseLenient(body, err)
+		}
 		if err != nil {
 			retryCount++
 			if retryCount <= maxRetries {
@@ -288,33 +1825,185 @@ func (s *RSSSource) Fetch(ctx context.Context) ([]*models.News, error) {
 		return nil, fmt.Errorf("获取 RSS 源失败")
 	}
 
-	// 转换新闻
+	newsList := s.newsFromFeed(feed)
+	log.Printf("成功抓取 %d 条新闻", len(newsList))
+	return newsList, nil
+}
+
+// decodeFeedBody 在交给 gofeed 解析之前做两件事：按 Content-Encoding 透明解压
+// （标准库 http.Transport 只在自己加上 Accept-Encoding: gzip 时才会自动解压，
+// 遇到不管客户端是否请求都强行返回 gzip/deflate 的不规范服务端会漏掉这一步），
+// 再按 Content-Type 里的 charset 或内容自身的编码声明把 GBK/ISO-8859-1 等非 UTF-8
+// 编码转换成 UTF-8，避免 gofeed 把非 UTF-8 字节流当乱码解析或直接报错。
+// 暂不支持 Brotli（本仓库依赖里没有可离线安装的 brotli 解码库），遇到时仅记录
+// 日志并原样透传，交由后续解析环节按 lenient 规则兜底
+func decodeFeedBody(body []byte, contentEncoding, contentType string) []byte {
+	switch strings.ToLower(strings.TrimSpace(contentEncoding)) {
+	case "gzip", "x-gzip":
+		if gr, err := gzip.NewReader(bytes.NewReader(body)); err == nil {
+			if decoded, err := io.ReadAll(gr); err == nil {
+				body = decoded
+			} else {
+				log.Printf("gzip 解压响应体失败，按原始内容继续: %v", err)
+			}
+		} else {
+			log.Printf("创建 gzip reader 失败，按原始内容继续: %v", err)
+		}
+	case "deflate":
+		fr := flate.NewReader(bytes.NewReader(body))
+		if decoded, err := io.ReadAll(fr); err == nil {
+			body = decoded
+		} else {
+			log.Printf("deflate 解压响应体失败，按原始内容继续: %v", err)
+		}
+	case "br":
+		log.Printf("响应声明 Content-Encoding: br，但当前未启用 brotli 解码，按原始内容继续")
+	}
+
+	reader, err := charset.NewReader(bytes.NewReader(body), contentType)
+	if err != nil {
+		log.Printf("charset 检测/转换失败，按原始内容继续: %v", err)
+		return body
+	}
+	converted, err := io.ReadAll(reader)
+	if err != nil {
+		log.Printf("charset 转换读取失败，按原始内容继续: %v", err)
+		return body
+	}
+	return converted
+}
+
+// newsFromFeed 以流的方式把解析好的 feed 转换成新闻条目，达到 max_items/max_age
+// 截断条件时提前停止，避免大型聚合源把所有条目一次性物化到内存并逐条打日志；
+// 正常轮询和消费 WebSub 推送内容两条路径共用这份转换逻辑
+func (s *RSSSource) newsFromFeed(feed *gofeed.Feed) []*models.News {
 	var newsList []*models.News
-	for _, item := range feed.Items {
-		// 如果没有 GUID，使用 Link 作为 ID
-		id := item.GUID
-		if id == "" {
-			id = item.Link
+	var minCreateTime time.Time
+	if s.config.MaxAge > 0 {
+		minCreateTime = time.Now().Add(-time.Duration(s.config.MaxAge) * time.Second)
+	}
+
+	for news := range s.streamItems(feed) {
+		if !minCreateTime.IsZero() && news.CreateTime.Before(minCreateTime) {
+			continue
+		}
+		newsList = append(newsList, news)
+		if s.config.MaxItems > 0 && len(newsList) >= s.config.MaxItems {
+			log.Printf("已达到 max_items=%d，提前停止处理源: %s", s.config.MaxItems, s.config.URL)
+			break
 		}
+	}
+	return newsList
+}
+
+// bareAmpersandPattern 匹配没有构成合法 XML 实体的裸 "&"，用于容错修复
+var bareAmpersandPattern = regexp.MustCompile(`&(?:amp|lt|gt|quot|apos|#[0-9]+|#x[0-9a-fA-F]+);|&`)
+
+// rssItemPattern、rssTitlePattern、rssLinkPattern 用于在 gofeed 彻底无法解析时，
+// 从原始 XML 中粗略提取 <item> 块及其标题、链接，作为最后的兜底手段
+var (
+	rssItemPattern  = regexp.MustCompile(`(?is)<item[ >].*?</item>`)
+	rssTitlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	rssLinkPattern  = regexp.MustCompile(`(?is)<link[^>]*>(.*?)</link>`)
+)
 
-		// 如果发布时间为空，使用当前时间
-		createTime := time.Now()
-		if item.PublishedParsed != nil {
-			createTime = *item.PublishedParsed
+// parseLenient 是 gofeed 直接解析失败后的容错兜底：先尝试修复未转义的裸 "&"
+// 重新解析一次，若仍然失败则退化为正则提取 <item> 块中的标题和链接，拼出一份
+// 只有标题和链接的最小 feed，让个别不规范字符不至于让整个源本轮完全抓不到条目。
+// 两步都失败时原样返回 gofeed 最初报出的错误
+func (s *RSSSource) parseLenient(body []byte, origErr error) (*gofeed.Feed, error) {
+	repaired := bareAmpersandPattern.ReplaceAllFunc(body, func(match []byte) []byte {
+		if string(match) == "&" {
+			return []byte("&amp;")
 		}
+		return match
+	})
+	if feed, err := gofeed.NewParser().Parse(bytes.NewReader(repaired)); err == nil {
+		log.Printf("lenient 模式：修复未转义的 & 后重新解析成功: %s", s.config.URL)
+		return feed, nil
+	}
 
-		news := &models.News{
-			ID:              id,
-			OriginalTitle:   item.Title,
-			OriginalContent: item.Description,
-			Link:            item.Link,
-			Source:          s.config.URL,
-			CreateTime:      createTime,
+	items := s.extractItemsByRegex(body)
+	if len(items) == 0 {
+		return nil, origErr
+	}
+	log.Printf("lenient 模式：正则兜底提取到 %d 条目: %s", len(items), s.config.URL)
+	return &gofeed.Feed{Items: items}, nil
+}
+
+// extractItemsByRegex 从原始 RSS 字节中提取 <item> 块的标题和链接，仅在标题、
+// 链接均非空时才作为一条最小条目返回，不产出正文或发布时间
+func (s *RSSSource) extractItemsByRegex(body []byte) []*gofeed.Item {
+	var items []*gofeed.Item
+	for _, block := range rssItemPattern.FindAll(body, -1) {
+		titleMatch := rssTitlePattern.FindSubmatch(block)
+		linkMatch := rssLinkPattern.FindSubmatch(block)
+		if titleMatch == nil || linkMatch == nil {
+			continue
 		}
-		newsList = append(newsList, news)
-		log.Printf("解析RSS新闻: %s (ID: %v)", news.OriginalTitle, news.ID)
+		title := stripCDATA(string(titleMatch[1]))
+		link := stripCDATA(string(linkMatch[1]))
+		if title == "" || link == "" {
+			continue
+		}
+		items = append(items, &gofeed.Item{Title: title, Link: link, GUID: link})
 	}
+	return items
+}
 
-	log.Printf("成功抓取 %d 条新闻", len(newsList))
-	return newsList, nil
+// stripCDATA 去掉正则提取结果中可能残留的 CDATA 包裹和首尾空白
+func stripCDATA(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "<![CDATA[")
+	s = strings.TrimSuffix(s, "]]>")
+	return strings.TrimSpace(s)
+}
+
+// streamItems 将 feed 条目转换为 News 的只读通道，供调用方按需消费并提前停止
+func (s *RSSSource) streamItems(feed *gofeed.Feed) <-chan *models.News {
+	// 缓冲区大小等于条目数，保证消费者提前 break 时生产者协程不会被永久阻塞
+	out := make(chan *models.News, len(feed.Items))
+
+	go func() {
+		defer close(out)
+		for _, item := range feed.Items {
+			// 如果没有 GUID，使用 Link 作为 ID
+			id := item.GUID
+			if id == "" {
+				id = item.Link
+			}
+
+			// 如果发布时间为空，使用当前时间
+			createTime := time.Now()
+			if item.PublishedParsed != nil {
+				createTime = *item.PublishedParsed
+			}
+
+			// 优先使用条目自带的图片，其次退化到图片类型的附件，供正文为空时 OCR 补全
+			imageURL := ""
+			if item.Image != nil {
+				imageURL = item.Image.URL
+			}
+			if imageURL == "" {
+				for _, enclosure := range item.Enclosures {
+					if strings.HasPrefix(enclosure.Type, "image/") {
+						imageURL = enclosure.URL
+						break
+					}
+				}
+			}
+
+			out <- &models.News{
+				ID:              id,
+				OriginalTitle:   item.Title,
+				OriginalContent: item.Description,
+				ImageURL:        imageURL,
+				Link:            item.Link,
+				Source:          s.config.URL,
+				CreateTime:      createTime,
+			}
+		}
+	}()
+
+	return out
 }
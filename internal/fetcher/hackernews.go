@@ -0,0 +1,206 @@
+package fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/amylixing/news-fetcher/internal/breaker"
+	"github.com/amylixing/news-fetcher/internal/budget"
+	"github.com/amylixing/news-fetcher/internal/config"
+	"github.com/amylixing/news-fetcher/internal/eventlog"
+	"github.com/amylixing/news-fetcher/internal/httpclient"
+	"github.com/amylixing/news-fetcher/internal/models"
+	"github.com/amylixing/news-fetcher/internal/ratelimit"
+)
+
+// hnBaseURL 是 Hacker News 官方 Firebase API 的基地址，故事列表和条目详情均由此拉取，
+// 无需鉴权
+const hnBaseURL = "https://hacker-news.firebaseio.com/v0"
+
+// defaultHNMaxItems 是 hackernews 数据源未配置 MaxItems 时，单轮最多拉取详情的故事数，
+// 避免故事列表接口一次返回的几百个 ID 都逐个请求详情
+const defaultHNMaxItems = 30
+
+// HNSource 从 Hacker News 官方 Firebase API 拉取 top/new/best 故事列表，
+// 按 StoryType 选择列表、按 MinScore 过滤低分故事
+type HNSource struct {
+	config            *config.SourceConfig
+	httpClient        *http.Client
+	pollInterval      time.Duration
+	lastFetch         time.Time
+	breaker           *breaker.Breaker // 为空表示未开启熔断
+	effectiveInterval time.Duration
+	rateLimiter       *ratelimit.Limiter // 为空表示未开启限速
+	budget            *budget.Tracker    // 每日抓取请求数配额，FetchDailyBudget<=0 时不限额
+	extraHeaders      map[string]string  // user_agent/header_profile 解析出的默认请求头，Headers 里显式配置的同名字段优先
+}
+
+// hnItem 对应 Firebase API 单条故事详情中我们关心的字段，其余字段（kids、
+// descendants 等）不需要因此不声明
+type hnItem struct {
+	ID      int    `json:"id"`
+	Type    string `json:"type"`
+	Deleted bool   `json:"deleted"`
+	Dead    bool   `json:"dead"`
+	By      string `json:"by"`
+	Title   string `json:"title"`
+	Text    string `json:"text"`
+	URL     string `json:"url"`
+	Score   int    `json:"score"`
+	Time    int64  `json:"time"`
+}
+
+// NewHNSource 创建 Hacker News 数据源，poolCfg 为空时使用共享的默认连接池参数，
+// globalProxyPool 是 sources.proxy_pool 全局代理池配置，数据源自身未配置
+// proxy_pool 时使用这一份；globalUserAgent/globalHeaderProfile 同理对应
+// sources.user_agent/sources.header_profile
+func NewHNSource(cfg *config.SourceConfig, poolCfg *config.HTTPClientConfig, globalProxyPool *config.ProxyPoolConfig, globalUserAgent, globalHeaderProfile string) (*HNSource, error) {
+	proxyPool, err := newProxyPoolFromConfig(cfg.ProxyPool, globalProxyPool)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := httpclient.New(httpclient.Options{
+		ProxyURL:  cfg.ProxyURL,
+		ProxyPool: proxyPool,
+		Timeout:   time.Duration(cfg.Timeout) * time.Second,
+		Pool:      httpclient.PoolFromConfig(poolCfg),
+		TLS:       cfg.TLS,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP客户端失败: %v", err)
+	}
+
+	if cfg.URL == "" {
+		// hackernews 数据源不需要用户配置 URL，这里合成一个标识，供日志、熔断状态、
+		// mock 固定文件命名等复用现有的"以 URL 为数据源标识"的约定
+		cfg.URL = "hackernews://" + hnStoryType(cfg.StoryType)
+	}
+
+	return &HNSource{
+		config:            cfg,
+		httpClient:        client,
+		pollInterval:      time.Duration(cfg.PollInterval) * time.Second,
+		effectiveInterval: time.Duration(cfg.PollInterval) * time.Second,
+		budget:            budget.New(),
+		extraHeaders:      resolveHeaders(cfg, globalUserAgent, globalHeaderProfile),
+	}, nil
+}
+
+// hnStoryType 校验并返回生效的故事类型，未配置或配置了未知值时默认 "top"
+func hnStoryType(storyType string) string {
+	switch storyType {
+	case "top", "new", "best":
+		return storyType
+	default:
+		return "top"
+	}
+}
+
+// Fetch 先拉取故事 ID 列表，再逐个请求详情，按 MinScore 过滤后映射为 models.News
+func (s *HNSource) Fetch(ctx context.Context) ([]*models.News, error) {
+	storyType := hnStoryType(s.config.StoryType)
+	listURL := fmt.Sprintf("%s/%sstories.json", hnBaseURL, storyType)
+
+	ids, err := s.fetchJSON(ctx, listURL, &[]int{})
+	if err != nil {
+		return nil, fmt.Errorf("获取Hacker News故事列表失败: %v", err)
+	}
+	idList := *ids.(*[]int)
+
+	maxItems := s.config.MaxItems
+	if maxItems <= 0 {
+		maxItems = defaultHNMaxItems
+	}
+	if len(idList) > maxItems {
+		idList = idList[:maxItems]
+	}
+
+	var minCreateTime time.Time
+	if s.config.MaxAge > 0 {
+		minCreateTime = time.Now().Add(-time.Duration(s.config.MaxAge) * time.Second)
+	}
+
+	var newsList []*models.News
+	for _, id := range idList {
+		itemURL := fmt.Sprintf("%s/item/%d.json", hnBaseURL, id)
+		item, err := s.fetchJSON(ctx, itemURL, &hnItem{})
+		if err != nil {
+			log.Printf("获取Hacker News故事 %d 详情失败: %v", id, err)
+			continue
+		}
+		story := item.(*hnItem)
+
+		if story.Type != "story" || story.Deleted || story.Dead {
+			continue
+		}
+		if story.Score < s.config.MinScore {
+			continue
+		}
+		createTime := time.Unix(story.Time, 0)
+		if !minCreateTime.IsZero() && createTime.Before(minCreateTime) {
+			continue
+		}
+
+		link := story.URL
+		if link == "" {
+			// Ask HN / Show HN 等没有外链的故事，落到 HN 自己的讨论页
+			link = fmt.Sprintf("https://news.ycombinator.com/item?id=%d", story.ID)
+		}
+
+		newsList = append(newsList, &models.News{
+			ID:              strconv.Itoa(story.ID),
+			OriginalTitle:   story.Title,
+			OriginalContent: story.Text,
+			Link:            link,
+			CreateTime:      createTime,
+			Source:          s.config.URL,
+		})
+	}
+
+	log.Printf("从Hacker News（%s）获取到 %d 条新闻", storyType, len(newsList))
+	return newsList, nil
+}
+
+// fetchJSON 请求 url 并把响应体解析进 out（须为指针），返回 out 本身以便调用方断言类型
+func (s *HNSource) fetchJSON(ctx context.Context, url string, out interface{}) (interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+	for key, value := range s.extraHeaders {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		eventlog.Event(url, "fetch_hackernews", "", "error", 0)
+		return nil, fmt.Errorf("发送请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		eventlog.Event(url, "fetch_hackernews", "", "error", 0)
+		err := fmt.Errorf("响应状态码异常: %d", resp.StatusCode)
+		if isPermanentStatus(resp.StatusCode) {
+			return nil, permanentError(err)
+		}
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %v", err)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %v", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,50 @@
+package fetcher
+
+import "github.com/amylixing/news-fetcher/internal/config"
+
+// headerProfiles 是内置的浏览器请求头集合，供屏蔽默认 Go HTTP 客户端 User-Agent
+// 的数据源引用；键即 header_profile 配置值。只覆盖常见的几种桌面浏览器，
+// 够用即可，不需要按版本号频繁更新
+var headerProfiles = map[string]map[string]string{
+	"chrome": {
+		"User-Agent":      "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+		"Accept":          "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8",
+		"Accept-Language": "en-US,en;q=0.9",
+	},
+	"firefox": {
+		"User-Agent":      "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:125.0) Gecko/20100101 Firefox/125.0",
+		"Accept":          "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+		"Accept-Language": "en-US,en;q=0.9",
+	},
+	"safari": {
+		"User-Agent":      "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Safari/605.1.15",
+		"Accept":          "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8",
+		"Accept-Language": "en-US,en;q=0.9",
+	},
+}
+
+// resolveHeaders 按数据源自身配置优先、否则回退全局配置（globalUserAgent/
+// globalHeaderProfile，即 sources.user_agent/sources.header_profile）的顺序，
+// 解析出该数据源每次请求都应附加的请求头：先套用命名的 header_profile（未命中
+// 已知名称则跳过），再用 user_agent 覆盖其中的 User-Agent。调用方需要在应用
+// SourceConfig.Headers 之前把这里返回的结果 Set 到请求上，让用户显式配置的
+// Headers 始终能覆盖这里的默认值
+func resolveHeaders(cfg *config.SourceConfig, globalUserAgent, globalHeaderProfile string) map[string]string {
+	profile := cfg.HeaderProfile
+	if profile == "" {
+		profile = globalHeaderProfile
+	}
+	userAgent := cfg.UserAgent
+	if userAgent == "" {
+		userAgent = globalUserAgent
+	}
+
+	headers := make(map[string]string)
+	for k, v := range headerProfiles[profile] {
+		headers[k] = v
+	}
+	if userAgent != "" {
+		headers["User-Agent"] = userAgent
+	}
+	return headers
+}
@@ -0,0 +1,168 @@
+package fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/amylixing/news-fetcher/internal/config"
+	"github.com/amylixing/news-fetcher/internal/customparser"
+	"github.com/amylixing/news-fetcher/internal/models"
+)
+
+// defaultJSONMapping 是未配置 mapping 时沿用的内置解析形状：
+// {status, data.list[].{id,title,content}}
+var defaultJSONMapping = config.JSONMappingConfig{
+	ListPath:    "data.list",
+	IDPath:      "id",
+	TitlePath:   "title",
+	ContentPath: "content",
+	LinkPath:    "link",
+}
+
+// resolveJSONPath 按点号分隔的路径依次做 map 取值，路径为空时返回 v 本身，
+// 任意一段查找失败都返回 (nil, false)
+func resolveJSONPath(v interface{}, path string) (interface{}, bool) {
+	if path == "" {
+		return v, true
+	}
+
+	cur := v
+	for _, segment := range strings.Split(path, ".") {
+		obj, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		cur, ok = obj[segment]
+		if !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// jsonPathString 取路径对应的值并转换为字符串，取不到或值本身不是字符串/数字时返回空串
+func jsonPathString(v interface{}, path string) string {
+	val, ok := resolveJSONPath(v, path)
+	if !ok || val == nil {
+		return ""
+	}
+	switch s := val.(type) {
+	case string:
+		return s
+	case float64:
+		return fmt.Sprintf("%v", s)
+	default:
+		return ""
+	}
+}
+
+// jsonPathTime 取路径对应的值并解析为时间，取不到或无法解析时返回零值时间，
+// 由调用方决定回退为抓取时刻
+func jsonPathTime(v interface{}, path string) (time.Time, bool) {
+	if path == "" {
+		return time.Time{}, false
+	}
+	val, ok := resolveJSONPath(v, path)
+	if !ok || val == nil {
+		return time.Time{}, false
+	}
+	switch t := val.(type) {
+	case string:
+		parsed, err := time.Parse(time.RFC3339, t)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return parsed, true
+	case float64:
+		return time.Unix(int64(t), 0), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// parseMappedAPIResponse 按 mapping 配置从任意形状的 JSON 响应体中取出条目列表，
+// 用于 APISource.Fetch 支持自定义 API 形状而不必为每个接口新增专门的解析代码
+func parseMappedAPIResponse(body []byte, mapping *config.JSONMappingConfig, source string) ([]*models.News, error) {
+	m := defaultJSONMapping
+	if mapping != nil {
+		if mapping.ListPath != "" {
+			m.ListPath = mapping.ListPath
+		}
+		if mapping.IDPath != "" {
+			m.IDPath = mapping.IDPath
+		}
+		if mapping.TitlePath != "" {
+			m.TitlePath = mapping.TitlePath
+		}
+		if mapping.ContentPath != "" {
+			m.ContentPath = mapping.ContentPath
+		}
+		if mapping.LinkPath != "" {
+			m.LinkPath = mapping.LinkPath
+		}
+		m.TimePath = mapping.TimePath
+	}
+
+	var root interface{}
+	if err := json.Unmarshal(body, &root); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %v", err)
+	}
+
+	rawList, ok := resolveJSONPath(root, m.ListPath)
+	if !ok {
+		return nil, fmt.Errorf("按路径 %q 未找到条目列表", m.ListPath)
+	}
+	list, ok := rawList.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("路径 %q 对应的值不是数组", m.ListPath)
+	}
+
+	newsList := make([]*models.News, 0, len(list))
+	for _, item := range list {
+		createTime := time.Now()
+		if t, ok := jsonPathTime(item, m.TimePath); ok {
+			createTime = t
+		}
+		newsList = append(newsList, &models.News{
+			ID:              jsonPathString(item, m.IDPath),
+			OriginalTitle:   jsonPathString(item, m.TitlePath),
+			OriginalContent: jsonPathString(item, m.ContentPath),
+			Link:            jsonPathString(item, m.LinkPath),
+			Source:          source,
+			CreateTime:      createTime,
+		})
+	}
+	return newsList, nil
+}
+
+// parseWithCustomCommand 把原始响应体交给 cfg.ParserCommand 指定的外部命令解析，
+// 用于内置的 mapping 字段映射仍无法覆盖的畸形/私有格式数据源
+func parseWithCustomCommand(ctx context.Context, cfg *config.SourceConfig, body []byte) ([]*models.News, error) {
+	parser := customparser.New(cfg.ParserCommand, time.Duration(cfg.ParserTimeout)*time.Second)
+	items, err := parser.Parse(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+
+	newsList := make([]*models.News, 0, len(items))
+	for _, item := range items {
+		createTime := time.Now()
+		if item.Time != "" {
+			if parsed, err := time.Parse(time.RFC3339, item.Time); err == nil {
+				createTime = parsed
+			}
+		}
+		newsList = append(newsList, &models.News{
+			ID:              item.ID,
+			OriginalTitle:   item.Title,
+			OriginalContent: item.Content,
+			Link:            item.Link,
+			Source:          cfg.URL,
+			CreateTime:      createTime,
+		})
+	}
+	return newsList, nil
+}
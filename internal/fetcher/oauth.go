@@ -0,0 +1,25 @@
+package fetcher
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/amylixing/news-fetcher/internal/config"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// newOAuth2Client 用已经配置好代理/TLS/超时的 base 客户端包一层 OAuth2 客户端凭证认证：
+// 返回的客户端会在令牌过期前用 base 自动发起刷新请求，并给每次业务请求自动附加
+// Authorization: Bearer <token>，调用方无需感知刷新时机，用于对接 Reddit、部分
+// Twitter 兼容 API 等要求 OAuth2 客户端凭证认证的数据源
+func newOAuth2Client(cfg *config.SourceAuthConfig, base *http.Client) *http.Client {
+	ccCfg := &clientcredentials.Config{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		TokenURL:     cfg.TokenURL,
+		Scopes:       cfg.Scopes,
+	}
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, base)
+	return ccCfg.Client(ctx)
+}
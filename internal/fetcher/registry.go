@@ -0,0 +1,68 @@
+package fetcher
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/amylixing/news-fetcher/internal/config"
+)
+
+// SourceConstructor 按数据源配置和全局 HTTP 连接池配置构造一个 Source 实现，
+// 供 Register 注册的第三方数据源类型使用；需要熔断、限速、状态持久化等能力
+// 时自行在返回的实现里实现对应的可选接口（如 StatefulSource），Fetcher 在
+// 创建时会像对待内置数据源一样自动注入
+type SourceConstructor func(cfg *config.SourceConfig, poolCfg *config.HTTPClientConfig) (Source, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]SourceConstructor{}
+)
+
+// Register 注册一个具名的数据源类型构造函数，供 sources.custom 里同名的 type
+// 字段引用。通常在数据源类型自己的 init() 里调用；重复注册同一个名字会覆盖
+// 之前的构造函数，最后一次 import 生效
+func Register(name string, constructor SourceConstructor) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = constructor
+}
+
+// lookupConstructor 返回 name 对应已注册的构造函数，未注册时 ok 为 false
+func lookupConstructor(name string) (constructor SourceConstructor, ok bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	constructor, ok = registry[name]
+	return constructor, ok
+}
+
+// customSourceEntry 包装一个通过 Register 注册的第三方数据源实例，补上内置
+// 数据源都有的轮询间隔/时间窗口节流，第三方数据源本身只需要实现 Source 接口
+type customSourceEntry struct {
+	config       *config.SourceConfig
+	source       Source
+	pollInterval time.Duration
+	lastFetch    time.Time
+}
+
+// newCustomSources 按 cfg.Custom 逐条查找 Type 对应的注册构造函数并创建数据源，
+// 引用了未注册类型的条目直接报错，避免配置写错 type 却在运行期悄悄被忽略
+func newCustomSources(cfgs []*config.SourceConfig, poolCfg *config.HTTPClientConfig) ([]*customSourceEntry, error) {
+	var entries []*customSourceEntry
+	for _, cfg := range cfgs {
+		constructor, ok := lookupConstructor(cfg.Type)
+		if !ok {
+			return nil, fmt.Errorf("未找到数据源类型 %q 对应的注册构造函数，请确认已通过 fetcher.Register 注册", cfg.Type)
+		}
+		source, err := constructor(cfg, poolCfg)
+		if err != nil {
+			return nil, fmt.Errorf("初始化自定义数据源（type=%s）失败: %v", cfg.Type, err)
+		}
+		entries = append(entries, &customSourceEntry{
+			config:       cfg,
+			source:       source,
+			pollInterval: time.Duration(cfg.PollInterval) * time.Second,
+		})
+	}
+	return entries, nil
+}
@@ -0,0 +1,229 @@
+package fetcher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+
+	"github.com/amylixing/news-fetcher/internal/config"
+	"github.com/amylixing/news-fetcher/internal/customparser"
+	"github.com/amylixing/news-fetcher/internal/httpclient"
+	"github.com/amylixing/news-fetcher/internal/models"
+)
+
+func init() {
+	Register("wasm", NewWASMSource)
+}
+
+var wasmMagic = []byte{0x00, 0x61, 0x73, 0x6d}
+
+// WASMSource 通过在进程内嵌入的 wazero 运行时执行一个 .wasm 数据源插件抓取新闻。
+// 模块本身跑在沙箱里，既不能直接发起网络请求也没有标准输出可写，必须通过本文件
+// 在 "env" 模块下导出的宿主函数完成整个抓取过程：
+//
+//	http_get(url_ptr, url_len) -> handle_and_len (uint64)
+//	    对 [url_ptr, url_ptr+url_len) 处的 URL 发起一次 GET 请求，响应体缓存在
+//	    宿主侧，返回值高 32 位为 handle、低 32 位为响应体字节数；返回 0 表示请求
+//	    失败。分两步是因为宿主没法预知模块会为响应体分配多大的缓冲区
+//	http_read(handle, dest_ptr, dest_len) -> 实际写入字节数 (uint32)
+//	    把 handle 对应的响应体拷贝进 [dest_ptr, dest_ptr+dest_len)，读取后立即
+//	    释放该 handle
+//	emit_news(ptr, len)
+//	    模块在 [ptr, ptr+len) 处写入一条 JSON 新闻条目（字段对齐
+//	    customparser.Item，与 exec 数据源约定一致），每识别出一条就调用一次
+//	log(ptr, len)
+//	    把 [ptr, ptr+len) 处的调试信息打印到宿主日志
+//
+// 插件本身按 WASI 的 _start 约定编译（如用 TinyGo 编译一个普通的 main 函数），
+// 运行结束即代表一轮抓取完成
+type WASMSource struct {
+	config     *config.SourceConfig
+	httpClient *http.Client
+	timeout    time.Duration
+	wasmBytes  []byte
+}
+
+// NewWASMSource 创建一个 wasm 数据源，url 字段填 .wasm 插件文件路径，
+// timeout<=0 时默认 30 秒；实现 SourceConstructor 签名供 Register 使用。
+// 插件文件在这里一次性读入并校验 WASM 魔数，配置错了在启动阶段（构造
+// 数据源时）就会失败，而不是要等到某一轮抓取才暴露成一个含糊的运行时错误
+func NewWASMSource(cfg *config.SourceConfig, poolCfg *config.HTTPClientConfig) (Source, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("wasm 数据源必须在 url 字段配置 .wasm 插件文件路径")
+	}
+	wasmBytes, err := os.ReadFile(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("读取 WASM 插件文件失败: %v", err)
+	}
+	if len(wasmBytes) < 4 || !bytes.Equal(wasmBytes[:4], wasmMagic) {
+		return nil, fmt.Errorf("%s 不是合法的 WASM 模块（缺少魔数）", cfg.URL)
+	}
+
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	client, err := httpclient.New(httpclient.Options{
+		Timeout: timeout,
+		Pool:    httpclient.PoolFromConfig(poolCfg),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP客户端失败: %v", err)
+	}
+
+	return &WASMSource{config: cfg, httpClient: client, timeout: timeout, wasmBytes: wasmBytes}, nil
+}
+
+// Fetch 为本次抓取新建一个独立的 wazero 运行时实例并执行插件，插件退出后
+// 收集期间通过 emit_news 交回的新闻列表；运行时实例不跨轮次复用，与 exec
+// 数据源每轮重新拉起一个子进程的模型保持一致
+func (s *WASMSource) Fetch(ctx context.Context) ([]*models.News, error) {
+	runCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	// 必须显式开启 WithCloseOnContextDone：wazero 默认不会因为 ctx 取消/超时中断
+	// 正在执行的 wasm 函数调用，一个死循环且从不调用宿主函数的恶意/有缺陷插件会
+	// 让 InstantiateModule 永远阻塞，s.timeout 形同虚设
+	r := wazero.NewRuntimeWithConfig(runCtx, wazero.NewRuntimeConfig().WithCloseOnContextDone(true))
+	defer r.Close(runCtx)
+
+	wasi_snapshot_preview1.MustInstantiate(runCtx, r)
+
+	host := &wasmHost{httpClient: s.httpClient, source: s.config.URL, buffers: make(map[uint32][]byte)}
+	if _, err := r.NewHostModuleBuilder("env").
+		NewFunctionBuilder().WithFunc(host.httpGet).Export("http_get").
+		NewFunctionBuilder().WithFunc(host.httpRead).Export("http_read").
+		NewFunctionBuilder().WithFunc(host.emitNews).Export("emit_news").
+		NewFunctionBuilder().WithFunc(host.hostLog).Export("log").
+		Instantiate(runCtx); err != nil {
+		return nil, fmt.Errorf("注册 WASM 宿主函数失败: %v", err)
+	}
+
+	compiled, err := r.CompileModule(runCtx, s.wasmBytes)
+	if err != nil {
+		return nil, fmt.Errorf("编译 WASM 插件失败: %v", err)
+	}
+
+	if _, err := r.InstantiateModule(runCtx, compiled, wazero.NewModuleConfig()); err != nil {
+		return nil, fmt.Errorf("运行 WASM 插件失败: %v", err)
+	}
+	if host.emitErr != nil {
+		return nil, host.emitErr
+	}
+	return host.news, nil
+}
+
+// wasmHost 持有一次 Fetch 调用期间宿主函数需要共享的状态：HTTP 客户端、
+// 待读取的响应体缓冲区、以及插件推送过来的新闻列表
+type wasmHost struct {
+	httpClient *http.Client
+	source     string
+
+	mu         sync.Mutex
+	nextHandle uint32
+	buffers    map[uint32][]byte
+
+	news    []*models.News
+	emitErr error
+}
+
+// httpGet 见 WASMSource doc comment 中的 ABI 说明
+func (h *wasmHost) httpGet(ctx context.Context, m api.Module, urlPtr, urlLen uint32) uint64 {
+	urlBytes, ok := m.Memory().Read(urlPtr, urlLen)
+	if !ok {
+		return 0
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, string(urlBytes), nil)
+	if err != nil {
+		return 0
+	}
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return 0
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0
+	}
+
+	h.mu.Lock()
+	h.nextHandle++
+	handle := h.nextHandle
+	h.buffers[handle] = body
+	h.mu.Unlock()
+
+	return uint64(handle)<<32 | uint64(uint32(len(body)))
+}
+
+// httpRead 见 WASMSource doc comment 中的 ABI 说明
+func (h *wasmHost) httpRead(ctx context.Context, m api.Module, handle, destPtr, destLen uint32) uint32 {
+	h.mu.Lock()
+	body, ok := h.buffers[handle]
+	delete(h.buffers, handle)
+	h.mu.Unlock()
+	if !ok {
+		return 0
+	}
+	if uint32(len(body)) > destLen {
+		body = body[:destLen]
+	}
+	if !m.Memory().Write(destPtr, body) {
+		return 0
+	}
+	return uint32(len(body))
+}
+
+// emitNews 见 WASMSource doc comment 中的 ABI 说明；解析失败时记录第一个错误，
+// 使 Fetch 整体失败，与 exec 数据源遇到一行解析不了就整体报错的行为一致
+func (h *wasmHost) emitNews(ctx context.Context, m api.Module, ptr, length uint32) uint32 {
+	if h.emitErr != nil {
+		return 1
+	}
+	data, ok := m.Memory().Read(ptr, length)
+	if !ok {
+		h.emitErr = fmt.Errorf("emit_news 读取模块内存失败（ptr=%d, length=%d）", ptr, length)
+		return 1
+	}
+	var item customparser.Item
+	if err := json.Unmarshal(data, &item); err != nil {
+		h.emitErr = fmt.Errorf("解析 WASM 插件推送的新闻条目失败: %v: %s", err, string(data))
+		return 1
+	}
+	createTime := time.Now()
+	if item.Time != "" {
+		if parsed, err := time.Parse(time.RFC3339, item.Time); err == nil {
+			createTime = parsed
+		}
+	}
+	h.news = append(h.news, &models.News{
+		ID:              item.ID,
+		OriginalTitle:   item.Title,
+		OriginalContent: item.Content,
+		Link:            item.Link,
+		Source:          h.source,
+		CreateTime:      createTime,
+	})
+	return 0
+}
+
+// hostLog 供插件把调试信息打印到宿主日志，因为插件自己没有可写的标准输出
+func (h *wasmHost) hostLog(ctx context.Context, m api.Module, ptr, length uint32) {
+	data, ok := m.Memory().Read(ptr, length)
+	if !ok {
+		return
+	}
+	log.Printf("[wasm:%s] %s", h.source, string(data))
+}
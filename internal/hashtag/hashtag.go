@@ -0,0 +1,50 @@
+// Package hashtag 从命中的关注名单实体和数据源名称生成消息末尾附加的话题标签，
+// 便于 Telegram 频道订阅者按话题原生筛选和搜索
+package hashtag
+
+import (
+	"regexp"
+	"strings"
+)
+
+// sanitizePattern 匹配话题标签里不允许出现的字符，非法字符直接去掉而不是替换成
+// 下划线，避免大量特殊字符被替换成一串下划线导致标签失去辨识度
+var sanitizePattern = regexp.MustCompile(`[^\p{L}\p{N}_]`)
+
+// Generate 从关注名单命中的实体和数据源名称生成去重后的话题标签列表（不含 # 前缀），
+// 每个来源片段各自清洗成合法的标签字符，为空或清洗后为空的片段会被跳过，
+// maxTags <= 0 时不限制数量
+func Generate(entities []string, source string, maxTags int) []string {
+	seen := make(map[string]bool)
+	var tags []string
+	add := func(raw string) {
+		tag := sanitizePattern.ReplaceAllString(raw, "")
+		if tag == "" || seen[strings.ToLower(tag)] {
+			return
+		}
+		seen[strings.ToLower(tag)] = true
+		tags = append(tags, tag)
+	}
+
+	for _, e := range entities {
+		add(e)
+	}
+	add(source)
+
+	if maxTags > 0 && len(tags) > maxTags {
+		tags = tags[:maxTags]
+	}
+	return tags
+}
+
+// Format 把标签列表拼成 "#Tag1 #Tag2" 形式的字符串；标签列表为空时返回空字符串
+func Format(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	parts := make([]string, len(tags))
+	for i, t := range tags {
+		parts[i] = "#" + t
+	}
+	return strings.Join(parts, " ")
+}
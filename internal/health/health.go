@@ -0,0 +1,108 @@
+// Package health 按数据源（以 URL 为键）记录抓取成功率、平均延迟和最近一次成功
+// 时间，供 /status 一类命令展示；数据源连续失败达到阈值时自动禁用，避免长期失效
+// 的上游继续白白消耗抓取轮次和重试预算
+package health
+
+import (
+	"sync"
+	"time"
+)
+
+// Snapshot 是某个数据源当前健康状况的一份只读快照
+type Snapshot struct {
+	Attempts    int           `json:"attempts"`
+	Successes   int           `json:"successes"`
+	LastSuccess time.Time     `json:"last_success"`
+	AvgLatency  time.Duration `json:"avg_latency"`
+	Disabled    bool          `json:"disabled"`
+}
+
+// SuccessRate 返回成功率，尚未记录过任何一次抓取时返回 1（未知不算异常）
+func (s Snapshot) SuccessRate() float64 {
+	if s.Attempts == 0 {
+		return 1
+	}
+	return float64(s.Successes) / float64(s.Attempts)
+}
+
+// Registry 按数据源跟踪健康状况并在长期失效时自动禁用
+type Registry struct {
+	autoDisableAfter int // 连续失败多少次后自动禁用，<=0 表示不自动禁用
+
+	mu      sync.Mutex
+	entries map[string]*entry
+}
+
+type entry struct {
+	attempts, successes int
+	consecutiveFailures int
+	lastSuccess         time.Time
+	totalLatency        time.Duration
+	disabled            bool
+}
+
+// New 创建一个健康登记表，autoDisableAfter<=0 时不自动禁用任何数据源
+func New(autoDisableAfter int) *Registry {
+	return &Registry{autoDisableAfter: autoDisableAfter, entries: make(map[string]*entry)}
+}
+
+// RecordSuccess 记录一次成功抓取及其耗时，并清零连续失败计数
+func (r *Registry) RecordSuccess(source string, latency time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e := r.entryFor(source)
+	e.attempts++
+	e.successes++
+	e.consecutiveFailures = 0
+	e.lastSuccess = time.Now()
+	e.totalLatency += latency
+}
+
+// RecordFailure 记录一次失败抓取，连续失败次数达到 autoDisableAfter 时自动禁用该数据源
+func (r *Registry) RecordFailure(source string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e := r.entryFor(source)
+	e.attempts++
+	e.consecutiveFailures++
+	if r.autoDisableAfter > 0 && e.consecutiveFailures >= r.autoDisableAfter {
+		e.disabled = true
+	}
+}
+
+func (r *Registry) entryFor(source string) *entry {
+	e, ok := r.entries[source]
+	if !ok {
+		e = &entry{}
+		r.entries[source] = e
+	}
+	return e
+}
+
+// Disabled 判断该数据源是否因长期失效已被自动禁用
+func (r *Registry) Disabled(source string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.entries[source]
+	return ok && e.disabled
+}
+
+// Snapshot 返回全部已记录数据源当前的健康状况快照
+func (r *Registry) Snapshot() map[string]Snapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make(map[string]Snapshot, len(r.entries))
+	for source, e := range r.entries {
+		snap := Snapshot{
+			Attempts:    e.attempts,
+			Successes:   e.successes,
+			LastSuccess: e.lastSuccess,
+			Disabled:    e.disabled,
+		}
+		if e.successes > 0 {
+			snap.AvgLatency = e.totalLatency / time.Duration(e.successes)
+		}
+		out[source] = snap
+	}
+	return out
+}
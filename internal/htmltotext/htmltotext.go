@@ -0,0 +1,195 @@
+// Package htmltotext 把新闻正文里的 HTML 转换成 Telegram 消息可以直接展示的纯文本/
+// 精简 HTML：保留 Telegram 支持的少数标签（加粗、斜体、代码等），列表转换成带
+// 项目符号的多行文本，链接按配置的方式处理，而不是像原来的字符串替换那样
+// 遇到不认识的标签就把尖括号原样漏出去
+package htmltotext
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// LinkMode 控制正文中的 <a> 标签转换成文本时如何处理链接地址
+type LinkMode string
+
+const (
+	// LinkModeInline 在链接文字后面用括号附上地址，如 "示例文字 (https://...)"
+	LinkModeInline LinkMode = "inline"
+	// LinkModeFootnote 保留链接文字，把地址收集到正文末尾编号列出，如 "示例文字[1]"
+	LinkModeFootnote LinkMode = "footnote"
+	// LinkModeStrip 只保留链接文字，丢弃地址
+	LinkModeStrip LinkMode = "strip"
+)
+
+// Options 控制转换行为，Telegram 消息里 ParseMode 固定为 HTML，
+// 因此保留的标签必须是 Telegram 支持的那一小部分
+type Options struct {
+	LinkMode LinkMode // 为空时按 LinkModeInline 处理
+}
+
+// telegramTags 是 Telegram HTML 消息格式支持的标签，其余标签一律转换/剥离成纯文本
+var telegramTags = map[atom.Atom]bool{
+	atom.B:      true,
+	atom.Strong: true,
+	atom.I:      true,
+	atom.Em:     true,
+	atom.U:      true,
+	atom.S:      true,
+	atom.Code:   true,
+	atom.Pre:    true,
+}
+
+// Convert 把一段 HTML 正文转换成适合直接放进 Telegram HTML 消息的文本
+func Convert(rawHTML string, opts Options) string {
+	if strings.TrimSpace(rawHTML) == "" {
+		return rawHTML
+	}
+	if opts.LinkMode == "" {
+		opts.LinkMode = LinkModeInline
+	}
+
+	nodes, err := html.ParseFragment(strings.NewReader(rawHTML), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		// 无法解析时退化为原文，避免把一整条新闻的正文丢掉
+		return rawHTML
+	}
+
+	c := &converter{opts: opts}
+	for _, n := range nodes {
+		c.walk(n)
+	}
+	text := c.buf.String()
+
+	if len(c.footnotes) > 0 {
+		text += "\n"
+		for i, link := range c.footnotes {
+			text += "\n[" + strconv.Itoa(i+1) + "] " + link
+		}
+	}
+
+	return strings.TrimSpace(collapseBlankLines(text))
+}
+
+type converter struct {
+	opts      Options
+	buf       strings.Builder
+	footnotes []string
+}
+
+func (c *converter) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		c.buf.WriteString(n.Data)
+		return
+	case html.ElementNode:
+		switch n.DataAtom {
+		case atom.Br:
+			c.buf.WriteString("\n")
+			return
+		case atom.P, atom.Div:
+			c.walkChildren(n)
+			c.buf.WriteString("\n\n")
+			return
+		case atom.Li:
+			c.buf.WriteString("• ")
+			c.walkChildren(n)
+			c.buf.WriteString("\n")
+			return
+		case atom.Ul, atom.Ol:
+			c.walkChildren(n)
+			c.buf.WriteString("\n")
+			return
+		case atom.Img:
+			c.buf.WriteString("[图片]")
+			return
+		case atom.A:
+			c.writeLink(n)
+			return
+		case atom.Script, atom.Style:
+			return
+		}
+
+		if telegramTags[n.DataAtom] {
+			tag := n.Data
+			c.buf.WriteString("<" + tag + ">")
+			c.walkChildren(n)
+			c.buf.WriteString("</" + tag + ">")
+			return
+		}
+	}
+
+	c.walkChildren(n)
+}
+
+func (c *converter) walkChildren(n *html.Node) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		c.walk(child)
+	}
+}
+
+func (c *converter) writeLink(n *html.Node) {
+	href := attr(n, "href")
+	text := strings.TrimSpace(textContent(n))
+	if text == "" {
+		text = href
+	}
+	if href == "" {
+		c.buf.WriteString(text)
+		return
+	}
+
+	switch c.opts.LinkMode {
+	case LinkModeStrip:
+		c.buf.WriteString(text)
+	case LinkModeFootnote:
+		c.footnotes = append(c.footnotes, href)
+		c.buf.WriteString(text + "[" + strconv.Itoa(len(c.footnotes)) + "]")
+	default: // LinkModeInline
+		if text == href {
+			c.buf.WriteString(href)
+		} else {
+			c.buf.WriteString(text + " (" + href + ")")
+		}
+	}
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			return
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+// collapseBlankLines 把连续三行以上的空行折叠成两行，避免大量 <p>/<div> 嵌套
+// 转换后正文里出现一长串空白
+func collapseBlankLines(s string) string {
+	for strings.Contains(s, "\n\n\n") {
+		s = strings.ReplaceAll(s, "\n\n\n", "\n\n")
+	}
+	return s
+}
@@ -0,0 +1,89 @@
+package httpclient
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+// dnsCacheEntry 缓存一次域名解析的结果
+type dnsCacheEntry struct {
+	addrs    []string
+	expireAt time.Time
+}
+
+// dnsCache 简单的进程内 DNS 缓存，支持自定义 DNS 服务器
+type dnsCache struct {
+	mu       sync.RWMutex
+	entries  map[string]dnsCacheEntry
+	ttl      time.Duration
+	resolver *net.Resolver
+}
+
+// newDNSCache 根据配置创建 DNS 缓存；servers 为空时使用系统默认解析器
+func newDNSCache(ttl time.Duration, servers []string) *dnsCache {
+	resolver := net.DefaultResolver
+	if len(servers) > 0 {
+		server := servers[0]
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				d := net.Dialer{Timeout: 5 * time.Second}
+				return d.DialContext(ctx, network, server)
+			},
+		}
+	}
+
+	return &dnsCache{
+		entries:  make(map[string]dnsCacheEntry),
+		ttl:      ttl,
+		resolver: resolver,
+	}
+}
+
+// lookup 返回域名对应的 IP 列表，命中缓存且未过期时直接返回
+func (c *dnsCache) lookup(ctx context.Context, host string) ([]string, error) {
+	if c.ttl > 0 {
+		c.mu.RLock()
+		entry, ok := c.entries[host]
+		c.mu.RUnlock()
+		if ok && time.Now().Before(entry.expireAt) {
+			return entry.addrs, nil
+		}
+	}
+
+	addrs, err := c.resolver.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.ttl > 0 {
+		c.mu.Lock()
+		c.entries[host] = dnsCacheEntry{addrs: addrs, expireAt: time.Now().Add(c.ttl)}
+		c.mu.Unlock()
+	}
+
+	return addrs, nil
+}
+
+// dialContext 返回一个 DialContext，先通过缓存解析主机名，再用解析出的 IP 建立连接
+func (c *dnsCache) dialContext(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return dialer.DialContext(ctx, network, addr)
+		}
+
+		if net.ParseIP(host) != nil {
+			return dialer.DialContext(ctx, network, addr)
+		}
+
+		addrs, err := c.lookup(ctx, host)
+		if err != nil || len(addrs) == 0 {
+			return dialer.DialContext(ctx, network, addr)
+		}
+
+		return dialer.DialContext(ctx, network, net.JoinHostPort(addrs[0], port))
+	}
+}
@@ -0,0 +1,135 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/amylixing/news-fetcher/internal/config"
+	"github.com/amylixing/news-fetcher/internal/proxypool"
+	"golang.org/x/net/proxy"
+)
+
+// PoolConfig 连接池相关参数，各组件共享同一套默认值，也可以按需覆盖
+type PoolConfig struct {
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	DisableKeepAlives   bool
+	DisableHTTP2        bool
+	DNSCacheTTL         time.Duration // 0 表示不启用 DNS 缓存
+	DNSServers          []string      // 自定义 DNS 服务器，为空则使用系统默认解析器
+}
+
+// DefaultPoolConfig 返回项目中此前各处复制粘贴的默认连接池参数
+func DefaultPoolConfig() PoolConfig {
+	return PoolConfig{
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 20,
+		IdleConnTimeout:     30 * time.Second,
+	}
+}
+
+// PoolFromConfig 将全局配置中的连接池参数转换为 PoolConfig，cfg 为空时返回 nil（使用默认值）
+func PoolFromConfig(cfg *config.HTTPClientConfig) *PoolConfig {
+	if cfg == nil {
+		return nil
+	}
+	pool := &PoolConfig{
+		MaxIdleConns:        cfg.MaxIdleConns,
+		MaxIdleConnsPerHost: cfg.MaxIdleConnsPerHost,
+		IdleConnTimeout:     time.Duration(cfg.IdleConnTimeout) * time.Second,
+		DisableKeepAlives:   cfg.DisableKeepAlives,
+		DisableHTTP2:        cfg.DisableHTTP2,
+	}
+	if cfg.DNS != nil {
+		pool.DNSCacheTTL = time.Duration(cfg.DNS.CacheTTL) * time.Second
+		pool.DNSServers = cfg.DNS.Servers
+	}
+	return pool
+}
+
+// Options 创建 HTTP 客户端所需的参数
+type Options struct {
+	ProxyURL string
+	// ProxyPool 非空时优先于 ProxyURL：每次请求从池中选一个代理，连续失败的
+	// 代理会被自动摘除，冷却后恢复
+	ProxyPool   *proxypool.Pool
+	Timeout     time.Duration
+	Pool        *PoolConfig       // 为空时使用 DefaultPoolConfig
+	TLS         *config.TLSConfig // 为空时使用 Go 默认 TLS 设置
+	Middlewares []Middleware      // 按顺序应用的请求/响应中间件链
+}
+
+// New 创建共享风格的 HTTP 客户端，集中管理连接池、超时和代理设置，
+// 取代此前 RSSSource、Sender、Analyzer、Translator 各自复制的 Transport 配置
+func New(opts Options) (*http.Client, error) {
+	pool := DefaultPoolConfig()
+	if opts.Pool != nil {
+		pool = *opts.Pool
+	}
+
+	transport := &http.Transport{
+		MaxIdleConns:        pool.MaxIdleConns,
+		MaxIdleConnsPerHost: pool.MaxIdleConnsPerHost,
+		IdleConnTimeout:     pool.IdleConnTimeout,
+		DisableKeepAlives:   pool.DisableKeepAlives,
+	}
+	if pool.DisableHTTP2 {
+		transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	}
+
+	tlsConfig, err := BuildTLSConfig(opts.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("构建 TLS 配置失败: %v", err)
+	}
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	if pool.DNSCacheTTL > 0 || len(pool.DNSServers) > 0 {
+		cache := newDNSCache(pool.DNSCacheTTL, pool.DNSServers)
+		dialer := &net.Dialer{Timeout: 30 * time.Second}
+		transport.DialContext = cache.dialContext(dialer)
+	}
+
+	middlewares := opts.Middlewares
+	if opts.ProxyPool != nil {
+		// 代理池场景下每次请求可能被路由到不同代理，SOCKS5 那种连接期固定拨号器
+		// 的写法不适用，统一走 Transport.Proxy（对 HTTP/HTTPS 代理逐请求生效），
+		// 由 Middleware 在请求上下文里传达本次选中的代理
+		transport.Proxy = proxypool.ProxyFunc
+		middlewares = append([]Middleware{proxypool.Middleware(opts.ProxyPool)}, middlewares...)
+	} else if opts.ProxyURL != "" {
+		proxyURL, err := url.Parse(opts.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("解析代理URL失败: %v", err)
+		}
+
+		if proxyURL.Scheme == "socks5" || proxyURL.Scheme == "socks5h" {
+			dialer, err := proxy.FromURL(proxyURL, proxy.Direct)
+			if err != nil {
+				return nil, fmt.Errorf("创建 SOCKS5 代理拨号器失败: %v", err)
+			}
+			transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+				return dialer.Dial(network, addr)
+			}
+		} else {
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
+	}
+
+	var rt http.RoundTripper = transport
+	if len(middlewares) > 0 {
+		rt = Chain(transport, middlewares...)
+	}
+
+	return &http.Client{
+		Transport: rt,
+		Timeout:   opts.Timeout,
+	}, nil
+}
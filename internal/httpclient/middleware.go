@@ -0,0 +1,41 @@
+package httpclient
+
+import (
+	"log"
+	"net/http"
+	"time"
+)
+
+// Middleware 包装一个 http.RoundTripper，用于在请求/响应链路上插入自定义行为
+// （日志、重试、限速、签名、指标等），forks 可以在不修改各数据源代码的情况下扩展行为
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// Chain 按顺序把多个中间件应用到 base 上，靠前的中间件最先处理请求
+func Chain(base http.RoundTripper, middlewares ...Middleware) http.RoundTripper {
+	rt := base
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		rt = middlewares[i](rt)
+	}
+	return rt
+}
+
+// roundTripperFunc 让普通函数满足 http.RoundTripper 接口
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// LoggingMiddleware 记录每次请求的方法、URL、耗时和状态码
+func LoggingMiddleware(next http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		start := time.Now()
+		resp, err := next.RoundTrip(req)
+		if err != nil {
+			log.Printf("[http] %s %s 失败，耗时 %v: %v", req.Method, req.URL, time.Since(start), err)
+			return nil, err
+		}
+		log.Printf("[http] %s %s -> %d，耗时 %v", req.Method, req.URL, resp.StatusCode, time.Since(start))
+		return resp, nil
+	})
+}
@@ -0,0 +1,118 @@
+// Package journal 记录每条新闻在本轮处理流水线中已经走到哪一步（已抓取、
+// 已完成 AI 分析、已投递到哪些聊天、已写入缓存），并在每次状态变化后立即
+// 落盘。进程崩溃或被杀后重启，处理循环可以据此跳过已经投递过的聊天，
+// 既不会把同一条消息重复发送一遍，也不会因为整批状态没有落盘而误以为
+// 这批新闻完全没处理过、白白丢掉已经完成的进度
+package journal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Record 记录一条新闻在流水线中的进度
+type Record struct {
+	Stage     string    `json:"stage"`                // fetched / analyzed / sent / cached
+	SentChats []string  `json:"sent_chats,omitempty"` // 已成功投递的聊天ID，用于崩溃重启后跳过已发送的目标
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Store 把每条新闻（以 "source:id" 为键）的流水线进度保存到一个 JSON 文件
+type Store struct {
+	mu      sync.Mutex
+	path    string
+	records map[string]Record
+}
+
+// NewStore 创建一个流水线进度存储，文件不存在时视为空
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path, records: make(map[string]Record)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取处理进度文件失败: %v", err)
+	}
+	if err := json.Unmarshal(data, &s.records); err != nil {
+		return nil, fmt.Errorf("解析处理进度文件失败: %v", err)
+	}
+	return s, nil
+}
+
+// Key 生成一条新闻的进度键
+func Key(source, id string) string {
+	return source + ":" + id
+}
+
+// Get 返回某条新闻当前的流水线进度，不存在时返回零值和 false
+func (s *Store) Get(key string) (Record, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.records[key]
+	return r, ok
+}
+
+// SetStage 把某条新闻的进度更新到指定阶段并立即持久化
+func (s *Store) SetStage(key, stage string) error {
+	s.mu.Lock()
+	r := s.records[key]
+	r.Stage = stage
+	r.UpdatedAt = time.Now()
+	s.records[key] = r
+	err := s.save()
+	s.mu.Unlock()
+	return err
+}
+
+// MarkChatsSent 把 chatIDs 追加到某条新闻已投递的聊天列表（去重）并立即持久化，
+// 供崩溃重启后跳过这些聊天，避免重复投递
+func (s *Store) MarkChatsSent(key string, chatIDs []string) error {
+	if len(chatIDs) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	r := s.records[key]
+	r.Stage = "sent"
+	r.UpdatedAt = time.Now()
+	seen := make(map[string]bool, len(r.SentChats))
+	for _, c := range r.SentChats {
+		seen[c] = true
+	}
+	for _, c := range chatIDs {
+		if !seen[c] {
+			r.SentChats = append(r.SentChats, c)
+			seen[c] = true
+		}
+	}
+	s.records[key] = r
+	err := s.save()
+	s.mu.Unlock()
+	return err
+}
+
+// Clear 从进度存储里移除一条记录并立即持久化，用于整条流水线走完（已写入缓存）
+// 之后清理，避免文件无限增长
+func (s *Store) Clear(key string) error {
+	s.mu.Lock()
+	delete(s.records, key)
+	err := s.save()
+	s.mu.Unlock()
+	return err
+}
+
+// save 假定调用方已持有锁
+func (s *Store) save() error {
+	data, err := json.MarshalIndent(s.records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化处理进度失败: %v", err)
+	}
+	if err := os.WriteFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("写入处理进度文件失败: %v", err)
+	}
+	return nil
+}
@@ -12,10 +12,17 @@ type News struct {
 	OriginalContent   string    `json:"original_content"`
 	TranslatedTitle   string    `json:"translated_title"`
 	TranslatedContent string    `json:"translated_content"`
-	Analysis          string    `json:"analysis"` // AI分析结果
+	Summary           string    `json:"summary"`         // AI 生成的一句话摘要，供仅需摘要的频道展示
+	Analysis          string    `json:"analysis"`        // AI分析结果
+	SentimentScore    float64   `json:"sentiment_score"` // AI 给出的情绪分数，范围 [-1, 1]，0 表示未评分
+	Tags              []string  `json:"tags"`            // 附加标签，如命中关注名单的 "watchlist:<entity>"
+	ImageURL          string    `json:"image_url"`       // 条目附带的图片地址，正文为空时可用于 OCR 补全
 	Link              string    `json:"link"`
 	CreateTime        time.Time `json:"create_time"`
 	Source            string    `json:"source"`
+	// TraceID 在抓取时生成一次，贯穿翻译/分析/发送各阶段的日志、指标和归档记录，
+	// 用于排查某一条具体新闻从抓取到发送经过了哪些阶段、耗时如何
+	TraceID string `json:"trace_id"`
 }
 
 // APIResponse API响应
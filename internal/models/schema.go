@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+// CurrentSchemaVersion 是对外集成通道（webhook/Kafka/导出等）JSON 负载的当前
+// schema 版本号；News 结构新增/删除/重命名会影响外部消费方解析的字段时递增，
+// 旧版本号继续由对应的兼容负载类型（如 NewsPayloadV1）承载，不随之变更
+const CurrentSchemaVersion = 3
+
+// NewsPayload 是 schema v3（当前版本）的对外负载：直接对应 News 的全部字段，
+// 额外携带 SchemaVersion 供消费方在反序列化前判断字段集是否与自己预期一致，
+// 避免 News 内部新增字段时消费方在毫无察觉的情况下把新字段当噪声丢弃或解析出错
+type NewsPayload struct {
+	SchemaVersion     int       `json:"schema_version"`
+	ID                string    `json:"id"`
+	OriginalTitle     string    `json:"original_title"`
+	OriginalContent   string    `json:"original_content"`
+	TranslatedTitle   string    `json:"translated_title"`
+	TranslatedContent string    `json:"translated_content"`
+	Summary           string    `json:"summary"`
+	Analysis          string    `json:"analysis"`
+	SentimentScore    float64   `json:"sentiment_score"`
+	Tags              []string  `json:"tags"`
+	ImageURL          string    `json:"image_url"`
+	Link              string    `json:"link"`
+	CreateTime        time.Time `json:"create_time"`
+	Source            string    `json:"source"`
+	// TraceID 是该条新闻抓取时生成的追踪ID，贯穿抓取/翻译/分析/发送各阶段的日志，
+	// 消费方可用它把这条负载和 news-fetcher 自身日志里的处理记录关联起来
+	TraceID string `json:"trace_id"`
+}
+
+// NewsPayloadV1 是 schema v1（Tags/ImageURL/SentimentScore 引入之前）的兼容负载，
+// 供尚未升级、仍按最初字段集解析的老消费方使用；字段集和顺序此后永久冻结，
+// 新增字段一律加进 NewsPayload 并递增 CurrentSchemaVersion，不回填到这里
+type NewsPayloadV1 struct {
+	ID                string    `json:"id"`
+	OriginalTitle     string    `json:"original_title"`
+	OriginalContent   string    `json:"original_content"`
+	TranslatedTitle   string    `json:"translated_title"`
+	TranslatedContent string    `json:"translated_content"`
+	Analysis          string    `json:"analysis"`
+	Link              string    `json:"link"`
+	CreateTime        time.Time `json:"create_time"`
+	Source            string    `json:"source"`
+}
+
+// ToPayload 把 News 转换成当前版本的对外负载
+func (n *News) ToPayload() NewsPayload {
+	return NewsPayload{
+		SchemaVersion:     CurrentSchemaVersion,
+		ID:                n.ID,
+		OriginalTitle:     n.OriginalTitle,
+		OriginalContent:   n.OriginalContent,
+		TranslatedTitle:   n.TranslatedTitle,
+		TranslatedContent: n.TranslatedContent,
+		Summary:           n.Summary,
+		Analysis:          n.Analysis,
+		SentimentScore:    n.SentimentScore,
+		Tags:              n.Tags,
+		ImageURL:          n.ImageURL,
+		Link:              n.Link,
+		CreateTime:        n.CreateTime,
+		Source:            n.Source,
+		TraceID:           n.TraceID,
+	}
+}
+
+// ToV1 返回该负载降级到 schema v1 字段集的兼容视图，丢弃 v1 之后新增的
+// Tags/ImageURL/SentimentScore 字段
+func (p NewsPayload) ToV1() NewsPayloadV1 {
+	return NewsPayloadV1{
+		ID:                p.ID,
+		OriginalTitle:     p.OriginalTitle,
+		OriginalContent:   p.OriginalContent,
+		TranslatedTitle:   p.TranslatedTitle,
+		TranslatedContent: p.TranslatedContent,
+		Analysis:          p.Analysis,
+		Link:              p.Link,
+		CreateTime:        p.CreateTime,
+		Source:            p.Source,
+	}
+}
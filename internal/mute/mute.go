@@ -0,0 +1,68 @@
+// Package mute 支持通过聊天命令临时静音某个数据源：静音期间该源的条目仍然
+// 被抓取、去重归档，只是不再对外投递，到期后自动恢复
+package mute
+
+import (
+	"sync"
+	"time"
+)
+
+// Muter 记录每个数据源的静音到期时间
+type Muter struct {
+	mu    sync.Mutex
+	until map[string]time.Time
+}
+
+// NewMuter 创建一个空的静音状态记录器
+func NewMuter() *Muter {
+	return &Muter{until: make(map[string]time.Time)}
+}
+
+// Mute 静音一个数据源，持续 duration 时长，重复调用会覆盖之前的到期时间
+func (m *Muter) Mute(source string, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.until[source] = time.Now().Add(duration)
+}
+
+// Unmute 提前解除一个数据源的静音，未处于静音状态时返回 false
+func (m *Muter) Unmute(source string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.until[source]; !ok {
+		return false
+	}
+	delete(m.until, source)
+	return true
+}
+
+// IsMuted 判断数据源当前是否处于静音状态，到期后惰性清理并返回 false
+func (m *Muter) IsMuted(source string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	until, ok := m.until[source]
+	if !ok {
+		return false
+	}
+	if time.Now().After(until) {
+		delete(m.until, source)
+		return false
+	}
+	return true
+}
+
+// List 返回当前仍处于静音状态的数据源及其到期时间，到期的会被惰性清理
+func (m *Muter) List() map[string]time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	result := make(map[string]time.Time)
+	for source, until := range m.until {
+		if now.After(until) {
+			delete(m.until, source)
+			continue
+		}
+		result[source] = until
+	}
+	return result
+}
@@ -0,0 +1,77 @@
+// Package ocr 为纯图片、没有文字正文的公告（如交易所/Twitter 截图）提取文字，
+// 使翻译和 AI 分析仍然有内容可用。仓库里没有现成的 OCR/视觉模型依赖，这里选择
+// 直接调用本机已安装的 tesseract 命令行工具，而不是引入新的第三方 SDK
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Extractor 通过外部 OCR 命令从图片中提取文字
+type Extractor struct {
+	command    string
+	httpClient *http.Client
+	timeout    time.Duration
+}
+
+// NewExtractor 创建一个 OCR 提取器，command 为可执行文件名或路径，默认为 "tesseract"，
+// timeout 为下载图片和执行 OCR 命令的总超时时间
+func NewExtractor(command string, timeout time.Duration) *Extractor {
+	if command == "" {
+		command = "tesseract"
+	}
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &Extractor{
+		command:    command,
+		httpClient: &http.Client{Timeout: timeout},
+		timeout:    timeout,
+	}
+}
+
+// Extract 下载 imageURL 指向的图片并运行 OCR，返回识别出的文字（已去除首尾空白）
+func (e *Extractor) Extract(ctx context.Context, imageURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", imageURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("创建图片请求失败: %v", err)
+	}
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("下载图片失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("下载图片失败，状态码: %d", resp.StatusCode)
+	}
+
+	var imageData bytes.Buffer
+	if _, err := imageData.ReadFrom(resp.Body); err != nil {
+		return "", fmt.Errorf("读取图片内容失败: %v", err)
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	// tesseract 支持 "stdin"/"stdout" 作为输入输出占位符，避免落地临时文件
+	cmd := exec.CommandContext(runCtx, e.command, "stdin", "stdout")
+	cmd.Stdin = bytes.NewReader(imageData.Bytes())
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("执行OCR命令失败: %v: %s", err, stderr.String())
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
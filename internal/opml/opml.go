@@ -0,0 +1,53 @@
+// Package opml 解析 OPML 订阅列表文件（主流 RSS 阅读器导出格式），供批量导入
+// 大量 RSS 源使用，不必逐条手写 YAML 配置
+package opml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+)
+
+// document 只关心 outline 节点的嵌套结构和 xmlUrl 属性，其余字段
+// （title、htmlUrl、type 等）用不到，不声明
+type document struct {
+	Body struct {
+		Outlines []outline `xml:"outline"`
+	} `xml:"body"`
+}
+
+type outline struct {
+	XMLURL   string    `xml:"xmlUrl,attr"`
+	Outlines []outline `xml:"outline"`
+}
+
+// LoadFeedURLs 解析 path 指向的 OPML 文件，返回其中全部 feed 的 xmlUrl，
+// 按文件里出现的顺序去重；OPML 允许用 outline 分组（如按分类文件夹）嵌套多层，
+// 这里递归展开所有层级
+func LoadFeedURLs(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取OPML文件失败: %v", err)
+	}
+
+	var doc document
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("解析OPML文件失败: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	var urls []string
+	collect(doc.Body.Outlines, seen, &urls)
+	return urls, nil
+}
+
+// collect 递归遍历 outline 节点，把带 xmlUrl 的节点收集进 urls，去重
+func collect(outlines []outline, seen map[string]bool, urls *[]string) {
+	for _, o := range outlines {
+		if o.XMLURL != "" && !seen[o.XMLURL] {
+			seen[o.XMLURL] = true
+			*urls = append(*urls, o.XMLURL)
+		}
+		collect(o.Outlines, seen, urls)
+	}
+}
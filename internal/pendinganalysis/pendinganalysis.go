@@ -0,0 +1,73 @@
+// Package pendinganalysis 记录 AI 分析失败的条目，供后续抓取周期重新尝试分析，
+// 超过配置的最大重试时长后放弃，避免长期挂起的失败条目无限期占用重试预算
+package pendinganalysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Store 把分析失败的条目（以 "source:id" 为键）首次失败的时间保存到一个 JSON 文件
+type Store struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]time.Time
+}
+
+// NewStore 创建一个待重试分析存储，文件不存在时视为空
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path, entries: make(map[string]time.Time)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取待重试分析文件失败: %v", err)
+	}
+	if err := json.Unmarshal(data, &s.entries); err != nil {
+		return nil, fmt.Errorf("解析待重试分析文件失败: %v", err)
+	}
+	return s, nil
+}
+
+// Get 返回某条条目首次分析失败的时间，不存在时返回零值和 false
+func (s *Store) Get(key string) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.entries[key]
+	return t, ok
+}
+
+// MarkFailed 记录一条条目分析失败，已存在时保留首次失败的时间不变
+func (s *Store) MarkFailed(key string) error {
+	s.mu.Lock()
+	if _, ok := s.entries[key]; !ok {
+		s.entries[key] = time.Now()
+	}
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("序列化待重试分析记录失败: %v", err)
+	}
+	return os.WriteFile(s.path, data, 0644)
+}
+
+// Clear 分析成功或放弃重试后移除一条条目的记录并持久化
+func (s *Store) Clear(key string) error {
+	s.mu.Lock()
+	if _, ok := s.entries[key]; !ok {
+		s.mu.Unlock()
+		return nil
+	}
+	delete(s.entries, key)
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("序列化待重试分析记录失败: %v", err)
+	}
+	return os.WriteFile(s.path, data, 0644)
+}
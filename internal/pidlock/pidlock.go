@@ -0,0 +1,58 @@
+// Package pidlock 用一个记录了当前进程 PID 的锁文件防止同一份数据目录被
+// 多个实例同时使用：重复启动会互相踩到彼此的缓存、游标等持久化文件，
+// 悄悄产生重复推送或数据错乱，比直接崩溃更难排查
+package pidlock
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Lock 代表一份已持有的锁，持有期间对应的锁文件里记录着当前进程的 PID
+type Lock struct {
+	path string
+	file *os.File
+}
+
+// Acquire 尝试在 path 处获取锁。若锁文件已存在且其中记录的 PID 仍是一个存活的
+// 进程，则拒绝启动并返回错误；若该 PID 已不存在（进程崩溃或被杀死后未清理），
+// 视为失效锁并接管
+func Acquire(path string) (*Lock, error) {
+	if data, err := os.ReadFile(path); err == nil {
+		if pid, perr := strconv.Atoi(strings.TrimSpace(string(data))); perr == nil && processAlive(pid) {
+			return nil, fmt.Errorf("另一个实例（PID %d）正在使用同一份数据目录，锁文件: %s", pid, path)
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("创建锁文件失败: %v", err)
+	}
+	if _, err := file.WriteString(strconv.Itoa(os.Getpid())); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("写入锁文件失败: %v", err)
+	}
+	return &Lock{path: path, file: file}, nil
+}
+
+// Release 释放锁并删除锁文件，供进程正常退出前调用
+func (l *Lock) Release() error {
+	l.file.Close()
+	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除锁文件失败: %v", err)
+	}
+	return nil
+}
+
+// processAlive 通过发送信号 0（不实际发送，仅做存在性检查）判断 pid 对应的
+// 进程是否仍然存活
+func processAlive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return process.Signal(syscall.Signal(0)) == nil
+}
@@ -0,0 +1,106 @@
+// Package priorityqueue 在 AI 分析和发送阶段之间提供一个按重要性排序的发送队列：
+// 每轮把新条目按 |SentimentScore| 打分后入队，只弹出本轮预算（MaxPerCycle）内的
+// 条目交给发送阶段，其余条目留到下一轮；每留一轮，有效优先级按配置的 AgingBoost
+// 提高一次，避免持续涌入的高重要性条目让低重要性条目永远排不上号（starvation）
+package priorityqueue
+
+import (
+	"math"
+	"sort"
+
+	"github.com/amylixing/news-fetcher/internal/archive"
+	"github.com/amylixing/news-fetcher/internal/models"
+)
+
+// entry 包装一条排队中的新闻及其已等待的轮数
+type entry struct {
+	news       *models.News
+	waitCycles int
+}
+
+// Queue 是一个按有效优先级排序、支持老化防止饥饿的发送队列；调用方 App.processNews
+// 本身单轮串行执行，不需要额外加锁
+type Queue struct {
+	maxPerCycle int
+	agingBoost  float64
+	pending     map[string]*entry // key 为 archive.Key(source, id)
+	order       []string          // 维持入队顺序，供同等有效优先级时稳定排序
+}
+
+// New 创建一个优先级队列，maxPerCycle<=0 表示不限（Pop 一次性弹出全部），
+// agingBoost<=0 时使用默认值 0.1
+func New(maxPerCycle int, agingBoost float64) *Queue {
+	if agingBoost <= 0 {
+		agingBoost = 0.1
+	}
+	return &Queue{
+		maxPerCycle: maxPerCycle,
+		agingBoost:  agingBoost,
+		pending:     make(map[string]*entry),
+	}
+}
+
+// Push 把本轮新条目加入队列；已经在队列中等待的同一条目（相同来源+ID）不重复
+// 入队、不重置其等待轮数——避免因为上一轮未被弹出、这一轮又被抓取判定为"新"
+// 条目而重新排队，丢失已经累积的老化优先级
+func (q *Queue) Push(newsList []*models.News) {
+	for _, news := range newsList {
+		key := archive.Key(news.Source, news.ID)
+		if _, exists := q.pending[key]; exists {
+			continue
+		}
+		q.pending[key] = &entry{news: news}
+		q.order = append(q.order, key)
+	}
+}
+
+// Pop 按有效优先级（|SentimentScore| + 等待轮数*AgingBoost）从高到低弹出最多
+// MaxPerCycle 条条目交给发送阶段，未弹出的条目留在队列中且等待轮数加一
+func (q *Queue) Pop() []*models.News {
+	keys := append([]string(nil), q.order...)
+	sort.SliceStable(keys, func(i, j int) bool {
+		return q.effectivePriority(q.pending[keys[i]]) > q.effectivePriority(q.pending[keys[j]])
+	})
+
+	n := len(keys)
+	if q.maxPerCycle > 0 && q.maxPerCycle < n {
+		n = q.maxPerCycle
+	}
+
+	popped := make([]*models.News, 0, n)
+	poppedKeys := make(map[string]bool, n)
+	for _, key := range keys[:n] {
+		popped = append(popped, q.pending[key].news)
+		poppedKeys[key] = true
+		delete(q.pending, key)
+	}
+
+	remaining := q.order[:0]
+	for _, key := range q.order {
+		if poppedKeys[key] {
+			continue
+		}
+		q.pending[key].waitCycles++
+		remaining = append(remaining, key)
+	}
+	q.order = remaining
+
+	return popped
+}
+
+func (q *Queue) effectivePriority(e *entry) float64 {
+	return math.Abs(e.news.SentimentScore) + float64(e.waitCycles)*q.agingBoost
+}
+
+// Pending 返回当前仍在队列中等待发送的条目数，供 /status 一类的只读查询使用
+func (q *Queue) Pending() int {
+	return len(q.pending)
+}
+
+// Contains 返回 source+id 对应的条目当前是否仍在队列中排队等待发送；上一轮
+// 未能进入本轮预算而留下的条目会被重新抓取到，调用方应据此跳过重复的 AI
+// 分析等前置处理，而不是走完整个流水线后指望 Push 的去重逻辑兜底
+func (q *Queue) Contains(source, id string) bool {
+	_, ok := q.pending[archive.Key(source, id)]
+	return ok
+}
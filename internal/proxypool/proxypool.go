@@ -0,0 +1,177 @@
+// Package proxypool 管理一组出口代理，取代单一 proxy_url 配置：按轮询或
+// 最小失败数策略选择代理，每个代理各自拥有独立的熔断器，连续失败达到阈值后
+// 临时从轮转中摘除，冷却时间结束后自动恢复，不需要人工介入
+package proxypool
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/amylixing/news-fetcher/internal/breaker"
+)
+
+// Strategy 描述从池中选择代理的策略
+type Strategy string
+
+const (
+	// RoundRobin 依次轮转，是未显式配置策略时的默认值
+	RoundRobin Strategy = "round_robin"
+	// LeastFailure 优先选择当前失败计数最少的代理
+	LeastFailure Strategy = "least_failure"
+)
+
+// 代理健康探测的默认熔断参数：连续失败 3 次即摘除，冷却 2 分钟后放行一次探测，
+// 摘除节奏比数据源熔断器（默认阈值 5）更激进，因为切换到池中另一个代理的
+// 代价远小于让某个数据源整体短路
+const (
+	defaultFailureThreshold = 3
+	defaultCooldown         = 2 * time.Minute
+	defaultHalfOpenProbes   = 1
+)
+
+// entry 是池中的一个代理及其独立的健康状态
+type entry struct {
+	url     *url.URL
+	breaker *breaker.Breaker
+}
+
+// Pool 管理一组代理的轮转与健康状态
+type Pool struct {
+	mu       sync.Mutex
+	entries  []*entry
+	strategy Strategy
+	rrNext   int
+}
+
+// New 按配置的地址列表和策略创建一个代理池，strategy 为空时使用 round_robin
+func New(rawURLs []string, strategy string) (*Pool, error) {
+	if len(rawURLs) == 0 {
+		return nil, fmt.Errorf("代理池地址列表不能为空")
+	}
+
+	p := &Pool{strategy: Strategy(strategy)}
+	if p.strategy == "" {
+		p.strategy = RoundRobin
+	}
+	for _, raw := range rawURLs {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("解析代理地址失败: %s: %v", raw, err)
+		}
+		p.entries = append(p.entries, &entry{
+			url:     u,
+			breaker: breaker.New(defaultFailureThreshold, defaultCooldown, defaultHalfOpenProbes),
+		})
+	}
+	return p, nil
+}
+
+// Next 按配置的策略选出一个当前健康（未被熔断）的代理，池内所有代理都被
+// 熔断时返回错误，调用方应把它当作本次请求失败处理
+func (p *Pool) Next() (*url.URL, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.strategy == LeastFailure {
+		var best *entry
+		bestFailures := -1
+		for _, e := range p.entries {
+			if !e.breaker.Allow() {
+				continue
+			}
+			failures := e.breaker.Snapshot().Failures
+			if bestFailures == -1 || failures < bestFailures {
+				best, bestFailures = e, failures
+			}
+		}
+		if best == nil {
+			return nil, fmt.Errorf("代理池内所有代理都不可用")
+		}
+		return best.url, nil
+	}
+
+	n := len(p.entries)
+	for i := 0; i < n; i++ {
+		idx := (p.rrNext + i) % n
+		if p.entries[idx].breaker.Allow() {
+			p.rrNext = (idx + 1) % n
+			return p.entries[idx].url, nil
+		}
+	}
+	return nil, fmt.Errorf("代理池内所有代理都不可用")
+}
+
+// RecordResult 记录某个代理本次请求的成败，供其熔断器判断是否需要临时摘除
+func (p *Pool) RecordResult(proxyURL *url.URL, success bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, e := range p.entries {
+		if e.url.String() == proxyURL.String() {
+			if success {
+				e.breaker.RecordSuccess()
+			} else {
+				e.breaker.RecordFailure()
+			}
+			return
+		}
+	}
+}
+
+// Snapshot 描述代理池中一个代理当前的健康状态，供 /status 一类的只读查询使用
+type Snapshot struct {
+	URL   string           `json:"url"`
+	State breaker.Snapshot `json:"state"`
+}
+
+// Snapshots 返回代理池中每个代理当前的健康状态快照
+func (p *Pool) Snapshots() []Snapshot {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]Snapshot, 0, len(p.entries))
+	for _, e := range p.entries {
+		out = append(out, Snapshot{URL: e.url.String(), State: e.breaker.Snapshot()})
+	}
+	return out
+}
+
+// proxyCtxKey 是请求上下文中暂存本次选中代理的键类型
+type proxyCtxKey struct{}
+
+// roundTripperFunc 让普通函数满足 http.RoundTripper 接口
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware 返回一个 http.RoundTripper 中间件：每次请求前从池中选一个代理，
+// 通过请求上下文传给 ProxyFunc 供底层 Transport.Proxy 使用，请求结束后按成败
+// 记录到对应代理的熔断器
+func Middleware(pool *Pool) func(next http.RoundTripper) http.RoundTripper {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			proxyURL, err := pool.Next()
+			if err != nil {
+				return nil, err
+			}
+			req = req.WithContext(context.WithValue(req.Context(), proxyCtxKey{}, proxyURL))
+
+			resp, err := next.RoundTrip(req)
+			pool.RecordResult(proxyURL, err == nil && resp != nil && resp.StatusCode < http.StatusInternalServerError)
+			return resp, err
+		})
+	}
+}
+
+// ProxyFunc 供 http.Transport.Proxy 使用，从请求上下文中取出 Middleware
+// 已经为本次请求选好的代理
+func ProxyFunc(req *http.Request) (*url.URL, error) {
+	if u, ok := req.Context().Value(proxyCtxKey{}).(*url.URL); ok {
+		return u, nil
+	}
+	return nil, nil
+}
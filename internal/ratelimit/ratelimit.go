@@ -0,0 +1,57 @@
+// Package ratelimit 为每个数据源提供一个独立的令牌桶限速器，避免过于激进的
+// 轮询触发上游的封禁策略；未开启时不限速
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter 是一个简单的令牌桶限速器：每秒按 ratePerSecond 匀速补充令牌，
+// 令牌数不超过 burst，Allow 每次消耗一枚令牌
+type Limiter struct {
+	ratePerSecond float64
+	burst         float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// New 创建一个限速器，requestsPerMinute<=0 时使用默认值 60，
+// burst<=0 时默认等于 requestsPerMinute
+func New(requestsPerMinute int, burst int) *Limiter {
+	if requestsPerMinute <= 0 {
+		requestsPerMinute = 60
+	}
+	if burst <= 0 {
+		burst = requestsPerMinute
+	}
+	return &Limiter{
+		ratePerSecond: float64(requestsPerMinute) / 60,
+		burst:         float64(burst),
+		tokens:        float64(burst),
+		last:          time.Now(),
+	}
+}
+
+// Allow 判断本次调用是否应该放行，令牌不足时返回 false 且不消耗令牌
+func (l *Limiter) Allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	elapsed := now.Sub(l.last).Seconds()
+	l.last = now
+
+	l.tokens += elapsed * l.ratePerSecond
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
@@ -0,0 +1,114 @@
+// Package readstate 记录每个私信用户对条目的已读/未读状态：每条私信给用户
+// 的条目分配一个短序号（用于内联按钮的 callback_data，Telegram 限制其长度不
+// 超过 64 字节，放不下完整的 source:id），用户点按钮确认已读后从未读列表中
+// 移除，供 /unread 命令展示用户尚未处理的条目
+package readstate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// maxEntriesPerUser 单用户最多保留的未读条目数，超出时丢弃最旧的，避免长期
+// 挂机不点按钮导致存储无限增长
+const maxEntriesPerUser = 200
+
+// Entry 是一条待确认已读的条目
+type Entry struct {
+	Token int    `json:"token"`
+	Title string `json:"title"`
+}
+
+// Store 持久化每个用户的未读条目
+type Store struct {
+	mu      sync.Mutex
+	path    string
+	nextTok map[int64]int
+	entries map[int64][]Entry
+}
+
+// NewStore 创建一个已读状态存储，path 不存在时视为空存储
+func NewStore(path string) (*Store, error) {
+	s := &Store{
+		path:    path,
+		nextTok: make(map[int64]int),
+		entries: make(map[int64][]Entry),
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取已读状态文件失败: %v", err)
+	}
+	if err := json.Unmarshal(data, &s.entries); err != nil {
+		return nil, fmt.Errorf("解析已读状态文件失败: %v", err)
+	}
+	for userID, list := range s.entries {
+		max := 0
+		for _, e := range list {
+			if e.Token > max {
+				max = e.Token
+			}
+		}
+		s.nextTok[userID] = max + 1
+	}
+	return s, nil
+}
+
+// Record 为用户新增一条待确认已读的条目，返回分配给它的短序号
+func (s *Store) Record(userID int64, title string) (int, error) {
+	s.mu.Lock()
+	token := s.nextTok[userID]
+	s.nextTok[userID] = token + 1
+	s.entries[userID] = append(s.entries[userID], Entry{Token: token, Title: title})
+	if len(s.entries[userID]) > maxEntriesPerUser {
+		s.entries[userID] = s.entries[userID][len(s.entries[userID])-maxEntriesPerUser:]
+	}
+	s.mu.Unlock()
+
+	return token, s.save()
+}
+
+// Ack 把用户的一条条目标记为已读（从未读列表中移除），未找到时返回 false
+func (s *Store) Ack(userID int64, token int) (bool, error) {
+	s.mu.Lock()
+	list := s.entries[userID]
+	found := false
+	for i, e := range list {
+		if e.Token == token {
+			s.entries[userID] = append(list[:i], list[i+1:]...)
+			found = true
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if !found {
+		return false, nil
+	}
+	return true, s.save()
+}
+
+// Unread 返回用户当前尚未确认已读的条目列表
+func (s *Store) Unread(userID int64) []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Entry(nil), s.entries[userID]...)
+}
+
+func (s *Store) save() error {
+	s.mu.Lock()
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("序列化已读状态失败: %v", err)
+	}
+	if err := os.WriteFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("写入已读状态文件失败: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+// Package sampling 支持大频道的低重要性条目抽样投递：重要性达到阈值的条目
+// 总是投递，未达到阈值的条目按各聊天各自配置的概率随机决定是否投递，
+// 用于控制推送频率而不是通过硬性阈值直接丢弃内容
+package sampling
+
+import (
+	"math/rand"
+
+	"github.com/amylixing/news-fetcher/internal/config"
+)
+
+// Sampler 按配置对条目做逐聊天的抽样投递判断
+type Sampler struct {
+	cfg *config.SamplingConfig
+}
+
+// New 创建一个 Sampler，cfg 为空或未开启时 Allow 总是返回 true（不抽样，照常投递）
+func New(cfg *config.SamplingConfig) *Sampler {
+	return &Sampler{cfg: cfg}
+}
+
+// Allow 判断 chatID 是否应该投递这条重要性为 importance（通常取 |SentimentScore|）
+// 的条目：达到配置的重要性阈值时总是投递；未达到阈值时按该聊天配置的抽样概率
+// 随机决定，未给该聊天配置抽样概率则视为不抽样、照常投递
+func (s *Sampler) Allow(chatID string, importance float64) bool {
+	if s.cfg == nil || !s.cfg.Enabled {
+		return true
+	}
+	if importance >= s.cfg.ImportanceThreshold {
+		return true
+	}
+	rate, ok := s.cfg.ChatRates[chatID]
+	if !ok {
+		return true
+	}
+	return rand.Float64() < rate
+}
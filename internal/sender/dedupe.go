@@ -0,0 +1,49 @@
+package sender
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// messageDedupe 是发送前的最后一道防线：在一个短暂窗口内记住每个聊天最近发出的
+// 消息内容哈希，拒绝在窗口内向同一聊天重复发送字节完全相同的消息，用于兜底
+// 上游任何逻辑缺陷（如重复抓取、重试未去重）导致的重复推送；不持久化，
+// 进程重启即清空，仅作为最后一道保险而不是主去重机制
+type messageDedupe struct {
+	mu     sync.Mutex
+	window time.Duration
+	seen   map[string]time.Time // key: chatID + ":" + sha256(message)
+}
+
+func newMessageDedupe(window time.Duration) *messageDedupe {
+	return &messageDedupe{window: window, seen: make(map[string]time.Time)}
+}
+
+// allow 返回 true 表示这条消息在窗口内首次出现，允许发送并记录；返回 false
+// 表示窗口内已经给该聊天发过字节完全相同的消息，调用方应当拒绝重复发送
+func (d *messageDedupe) allow(chatID, message string) bool {
+	key := chatID + ":" + hashMessage(message)
+	now := time.Now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for k, t := range d.seen {
+		if now.Sub(t) > d.window {
+			delete(d.seen, k)
+		}
+	}
+
+	if last, ok := d.seen[key]; ok && now.Sub(last) <= d.window {
+		return false
+	}
+	d.seen[key] = now
+	return true
+}
+
+func hashMessage(message string) string {
+	sum := sha256.Sum256([]byte(message))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,38 @@
+package sender
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageDedupeAllowsFirstOccurrence(t *testing.T) {
+	d := newMessageDedupe(time.Minute)
+	assert.True(t, d.allow("chat1", "hello"))
+}
+
+func TestMessageDedupeRejectsDuplicateWithinWindow(t *testing.T) {
+	d := newMessageDedupe(time.Minute)
+	assert.True(t, d.allow("chat1", "hello"))
+	assert.False(t, d.allow("chat1", "hello"))
+}
+
+func TestMessageDedupeDifferentChatsIndependent(t *testing.T) {
+	d := newMessageDedupe(time.Minute)
+	assert.True(t, d.allow("chat1", "hello"))
+	assert.True(t, d.allow("chat2", "hello"))
+}
+
+func TestMessageDedupeDifferentMessagesIndependent(t *testing.T) {
+	d := newMessageDedupe(time.Minute)
+	assert.True(t, d.allow("chat1", "hello"))
+	assert.True(t, d.allow("chat1", "world"))
+}
+
+func TestMessageDedupeAllowsAfterWindowExpires(t *testing.T) {
+	d := newMessageDedupe(20 * time.Millisecond)
+	assert.True(t, d.allow("chat1", "hello"))
+	time.Sleep(40 * time.Millisecond)
+	assert.True(t, d.allow("chat1", "hello"))
+}
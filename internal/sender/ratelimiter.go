@@ -0,0 +1,70 @@
+package sender
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rateLimiter 简单的令牌桶限速器，用于约束对 Telegram API 的全局发送速率
+type rateLimiter struct {
+	mu         sync.Mutex
+	tokens     float64
+	maxTokens  float64
+	refillRate float64 // 每秒补充的令牌数
+	lastRefill time.Time
+}
+
+// newRateLimiter 创建限速器，perSecond<=0 表示不限速
+func newRateLimiter(perSecond float64, burst int) *rateLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = 1
+	}
+	return &rateLimiter{
+		tokens:     float64(burst),
+		maxTokens:  float64(burst),
+		refillRate: perSecond,
+		lastRefill: time.Now(),
+	}
+}
+
+// Wait 阻塞直到获取到一个令牌，或 ctx 被取消/超时；后一种情况下返回 ctx.Err()，
+// 调用方应放弃这次发送而不是无视 ctx 继续等下去——否则一个限速很紧的令牌桶会让
+// sendToChats 的 goroutine 在已经过期的 ctx 上白白占着，拖慢 wg.Wait() 的返回
+func (r *rateLimiter) Wait(ctx context.Context) error {
+	if r == nil {
+		return nil
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		r.mu.Lock()
+		now := time.Now()
+		elapsed := now.Sub(r.lastRefill).Seconds()
+		r.tokens += elapsed * r.refillRate
+		if r.tokens > r.maxTokens {
+			r.tokens = r.maxTokens
+		}
+		r.lastRefill = now
+
+		if r.tokens >= 1 {
+			r.tokens--
+			r.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - r.tokens) / r.refillRate * float64(time.Second))
+		r.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
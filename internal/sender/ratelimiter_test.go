@@ -0,0 +1,55 @@
+package sender
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRateLimiterDisabled(t *testing.T) {
+	assert.Nil(t, newRateLimiter(0, 10))
+	assert.Nil(t, newRateLimiter(-1, 10))
+}
+
+func TestRateLimiterWaitNil(t *testing.T) {
+	var r *rateLimiter
+	assert.NoError(t, r.Wait(context.Background()))
+}
+
+func TestRateLimiterBurstAllowsImmediateTokens(t *testing.T) {
+	r := newRateLimiter(1, 3)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, r.Wait(context.Background()))
+	}
+	assert.Less(t, time.Since(start), 200*time.Millisecond, "突发容量内的令牌不应等待")
+}
+
+func TestRateLimiterThrottlesBeyondBurst(t *testing.T) {
+	r := newRateLimiter(10, 1)
+	assert.NoError(t, r.Wait(context.Background())) // 消耗掉唯一的令牌
+
+	start := time.Now()
+	assert.NoError(t, r.Wait(context.Background())) // 补充到下一个令牌大约需要 100ms（1/10 秒）
+	elapsed := time.Since(start)
+
+	assert.GreaterOrEqual(t, elapsed, 50*time.Millisecond)
+	assert.Less(t, elapsed, 500*time.Millisecond)
+}
+
+func TestRateLimiterWaitReturnsWhenContextCanceled(t *testing.T) {
+	r := newRateLimiter(1, 1)
+	assert.NoError(t, r.Wait(context.Background())) // 消耗掉唯一的令牌，之后的等待需要约 1 秒补满
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := r.Wait(ctx)
+	elapsed := time.Since(start)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, elapsed, 200*time.Millisecond, "ctx 超时应立即中断等待，而不是等到令牌补满")
+}
@@ -2,30 +2,73 @@ package sender
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/amylixing/news-fetcher/internal/cache"
+	"github.com/amylixing/news-fetcher/internal/chatmigration"
 	"github.com/amylixing/news-fetcher/internal/config"
+	"github.com/amylixing/news-fetcher/internal/dedupstats"
+	"github.com/amylixing/news-fetcher/internal/eventlog"
+	"github.com/amylixing/news-fetcher/internal/hashtag"
+	"github.com/amylixing/news-fetcher/internal/htmltotext"
+	"github.com/amylixing/news-fetcher/internal/httpclient"
 	"github.com/amylixing/news-fetcher/internal/models"
+	"github.com/amylixing/news-fetcher/internal/titlerewrite"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 // Sender 消息发送器
 type Sender struct {
-	cfg    *config.TelegramConfig
-	bot    *tgbotapi.BotAPI
-	client *http.Client
-	cache  *cache.Cache
+	cfg        *config.TelegramConfig
+	bot        *tgbotapi.BotAPI
+	client     *http.Client
+	cache      *cache.Cache
+	limiter    *rateLimiter
+	migrations *chatmigration.Store // 为空表示不持久化聊天ID迁移记录
+	dedupe     *messageDedupe       // 为空表示不开启发送前最后一道去重防线
+	dedupStats *dedupstats.Recorder // 为空表示不统计发送前去重防线的淘汰量
 }
 
-// NewSender 创建新的发送器
-func NewSender(cfg *config.TelegramConfig, cache *cache.Cache) (*Sender, error) {
+// SetDedupStats 注入去重环节的调优统计计数器，dedupe 命中重复消息时会计入
+// "content_hash" 环节，供 /dedupstats 一类的调优报告使用
+func (s *Sender) SetDedupStats(stats *dedupstats.Recorder) {
+	s.dedupStats = stats
+}
+
+// Bot 返回底层的 Telegram 机器人实例，供需要直接监听命令更新的调用方使用（如 botcmd.Dispatcher）
+func (s *Sender) Bot() *tgbotapi.BotAPI {
+	return s.bot
+}
+
+// EnableChatMigration 启用聊天ID迁移的持久化记录：群组升级为超级群组后 Telegram 会拒绝
+// 所有对旧聊天ID的发送并在错误里返回新聊天ID，启用后新聊天ID会被记录到 path，
+// 之后配置里的旧聊天ID在发送时自动替换为新聊天ID，无需手动修改配置文件
+func (s *Sender) EnableChatMigration(path string) error {
+	store, err := chatmigration.NewStore(path)
+	if err != nil {
+		return fmt.Errorf("初始化聊天ID迁移记录失败: %v", err)
+	}
+	s.migrations = store
+	return nil
+}
+
+// chatResult 单个聊天的发送结果，供调用方汇总投递情况
+type chatResult struct {
+	chatID    string
+	messageID int64
+	err       error
+}
+
+// NewSender 创建新的发送器，poolCfg 为空时使用共享的默认连接池参数
+func NewSender(cfg *config.TelegramConfig, cache *cache.Cache, poolCfg *config.HTTPClientConfig) (*Sender, error) {
 	if cfg == nil || !cfg.Enabled {
 		return &Sender{
 			cache: cache,
@@ -33,22 +76,13 @@ func NewSender(cfg *config.TelegramConfig, cache *cache.Cache) (*Sender, error)
 	}
 
 	// 配置HTTP客户端
-	httpClient := &http.Client{
-		Transport: &http.Transport{
-			MaxIdleConns:        100,
-			MaxIdleConnsPerHost: 20,
-			IdleConnTimeout:     30 * time.Second,
-		},
-		Timeout: time.Duration(cfg.Timeout) * time.Second,
-	}
-
-	// 如果配置了代理
-	if cfg.ProxyURL != "" {
-		proxyURL, err := url.Parse(cfg.ProxyURL)
-		if err != nil {
-			return nil, fmt.Errorf("解析代理URL失败: %v", err)
-		}
-		httpClient.Transport.(*http.Transport).Proxy = http.ProxyURL(proxyURL)
+	httpClient, err := httpclient.New(httpclient.Options{
+		ProxyURL: cfg.ProxyURL,
+		Timeout:  time.Duration(cfg.Timeout) * time.Second,
+		Pool:     httpclient.PoolFromConfig(poolCfg),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP客户端失败: %v", err)
 	}
 
 	// 创建Telegram机器人
@@ -57,107 +91,350 @@ func NewSender(cfg *config.TelegramConfig, cache *cache.Cache) (*Sender, error)
 		return nil, fmt.Errorf("创建Telegram机器人失败: %v", err)
 	}
 
+	var limiter *rateLimiter
+	if cfg.RateLimit != nil {
+		limiter = newRateLimiter(cfg.RateLimit.PerSecond, cfg.RateLimit.Burst)
+	}
+
+	var dedupe *messageDedupe
+	if cfg.DuplicateGuard != nil && cfg.DuplicateGuard.Enabled {
+		dedupe = newMessageDedupe(time.Duration(cfg.DuplicateGuard.Window) * time.Second)
+	}
+
 	return &Sender{
-		cfg:    cfg,
-		bot:    bot,
-		client: httpClient,
-		cache:  cache,
+		cfg:     cfg,
+		bot:     bot,
+		client:  httpClient,
+		cache:   cache,
+		limiter: limiter,
+		dedupe:  dedupe,
 	}, nil
 }
 
-// SendNews 发送新闻消息
+// SendNews 发送新闻消息到配置的全部聊天
 func (s *Sender) SendNews(ctx context.Context, news *models.News) error {
+	_, err := s.SendNewsToChats(ctx, news, nil)
+	return err
+}
+
+// SendNewsToChats 发送新闻消息到指定的聊天列表，chatIDs 为空时使用配置里的全部聊天，
+// 用于监控名单等需要单独路由到专属聊天的场景；返回值为发送成功的聊天到对应
+// Telegram 消息ID的映射，供调用方在条目内容后续更新时改为编辑原消息。markup 为
+// 可选的内联按钮（如已读确认按钮），不传或传 nil 表示消息不带按钮
+func (s *Sender) SendNewsToChats(ctx context.Context, news *models.News, chatIDs []string, markup ...*tgbotapi.InlineKeyboardMarkup) (map[string]int64, error) {
+	receipts, err := s.SendNewsToChatsWithReceipts(ctx, news, chatIDs, markup...)
+	messageIDs := make(map[string]int64, len(receipts))
+	for _, r := range receipts {
+		if r.Err == nil {
+			messageIDs[r.ChatID] = r.MessageID
+		}
+	}
+	return messageIDs, err
+}
+
+// DeliveryReceipt 记录单个聊天一次发送的详细结果，供需要持久化投递回执
+// （发送时间、尝试次数、最终状态）的调用方使用，排查"为什么这条没有出现在
+// 某个频道"时可以直接查数据而不必翻日志
+type DeliveryReceipt struct {
+	ChatID    string
+	MessageID int64 // 发送成功时对应的 Telegram 消息ID，失败时为 0
+	Attempts  int   // 实际尝试次数（含失败的重试）
+	Err       error // 最终仍失败时的错误，成功时为 nil
+}
+
+// SendNewsToChatsWithReceipts 与 SendNewsToChats 逻辑相同，额外返回每个聊天的
+// 详细投递回执（尝试次数、最终错误）
+func (s *Sender) SendNewsToChatsWithReceipts(ctx context.Context, news *models.News, chatIDs []string, markup ...*tgbotapi.InlineKeyboardMarkup) ([]DeliveryReceipt, error) {
+	var replyMarkup *tgbotapi.InlineKeyboardMarkup
+	if len(markup) > 0 {
+		replyMarkup = markup[0]
+	}
+
 	if s.bot == nil {
 		log.Printf("[%s] Telegram 未启用，跳过发送: %s (ID: %v)", news.Source, news.OriginalTitle, news.ID)
-		return nil
+		return nil, nil
 	}
 
-	message := s.formatMessage(news)
-	if message == "" {
+	if s.formatMessage(news, "") == "" {
 		log.Printf("[%s] 消息内容为空，跳过发送: %s (ID: %v)", news.Source, news.OriginalTitle, news.ID)
-		return fmt.Errorf("消息内容为空")
+		return nil, fmt.Errorf("消息内容为空")
 	}
 
-	log.Printf("[%s] 准备发送新闻: %s (ID: %v)", news.Source, news.OriginalTitle, news.ID)
+	if len(chatIDs) == 0 {
+		chatIDs = s.cfg.Bot.ChatIDs
+	}
 
-	var lastErr error
-	for i := 0; i < s.cfg.Retry.Count; i++ {
+	log.Printf("[%s] 准备发送新闻: %s (ID: %v, TraceID: %s)", news.Source, news.OriginalTitle, news.ID, news.TraceID)
+
+	// pending 记录尚未成功投递的聊天，每一轮只重试这些聊天，
+	// 避免已经成功的聊天被重复重试，也避免上一轮的失败被本轮的全部成功掩盖或反过来
+	pending := append([]string(nil), chatIDs...)
+	attempts := make(map[string]int, len(chatIDs))
+	errs := make(map[string]error)
+	messageIDs := make(map[string]int64)
+
+	for i := 0; i < s.cfg.Retry.Count && len(pending) > 0; i++ {
 		if i > 0 {
-			log.Printf("[%s] 第%d次重试发送新闻: %s (ID: %v)", news.Source, i+1, news.OriginalTitle, news.ID)
+			log.Printf("[%s] 第%d次重试发送新闻，剩余 %d 个聊天: %s (ID: %v)", news.Source, i+1, len(pending), news.OriginalTitle, news.ID)
 			time.Sleep(time.Duration(s.cfg.Retry.Interval) * time.Second)
 		}
 
-		// 为每个聊天ID发送消息
-		for _, chatID := range s.cfg.Bot.ChatIDs {
-			log.Printf("[%s] 正在发送到聊天 %s: %s (ID: %v)", news.Source, chatID, news.OriginalTitle, news.ID)
-			if err := s.sendToChat(ctx, chatID, message); err != nil {
-				lastErr = err
-				log.Printf("[%s] 发送到聊天 %s 失败: %s (ID: %v), 错误: %v", news.Source, chatID, news.OriginalTitle, news.ID, err)
+		// message 传空串，由 sendToChats 按每个聊天各自的时间格式配置分别渲染
+		results := s.sendToChats(ctx, news, "", pending, replyMarkup)
+		pending = pending[:0]
+		for _, r := range results {
+			attempts[r.chatID]++
+			if r.err != nil {
+				errs[r.chatID] = r.err
+				pending = append(pending, r.chatID)
+				log.Printf("[%s] 发送到聊天 %s 失败: %s (ID: %v), 错误: %v", news.Source, r.chatID, news.OriginalTitle, news.ID, r.err)
 				continue
 			}
-			log.Printf("[%s] 成功发送到聊天 %s: %s (ID: %v)", news.Source, chatID, news.OriginalTitle, news.ID)
+			delete(errs, r.chatID)
+			messageIDs[r.chatID] = r.messageID
+			log.Printf("[%s] 成功发送到聊天 %s: %s (ID: %v, TraceID: %s)", news.Source, r.chatID, news.OriginalTitle, news.ID, news.TraceID)
+		}
+	}
+
+	receipts := make([]DeliveryReceipt, 0, len(chatIDs))
+	for _, chatID := range chatIDs {
+		receipts = append(receipts, DeliveryReceipt{
+			ChatID:    chatID,
+			MessageID: messageIDs[chatID],
+			Attempts:  attempts[chatID],
+			Err:       errs[chatID],
+		})
+	}
+
+	if len(pending) == 0 {
+		return receipts, nil
+	}
+
+	return receipts, fmt.Errorf("发送新闻到 %d 个聊天失败: %v", len(pending), errs[pending[0]])
+}
+
+// EditNews 用新闻当前内容编辑此前已发送到指定聊天的消息，用于条目内容更新（如 AI 分析
+// 补全、上游修订正文）后原地刷新，而不是重复推送一条新消息
+func (s *Sender) EditNews(ctx context.Context, chatID string, messageID int64, news *models.News) error {
+	message := s.formatMessage(news, chatID)
+	if message == "" {
+		return fmt.Errorf("消息内容为空")
+	}
+	return s.editMessage(ctx, chatID, messageID, message)
+}
+
+// EditNewsWithNote 与 EditNews 相同，但会在正文末尾追加一行提示，用于重复抑制窗口内
+// 新增来源转载同一条新闻时，编辑原消息标注"还有其它来源报道"而不是重复推送一条新消息
+func (s *Sender) EditNewsWithNote(ctx context.Context, chatID string, messageID int64, news *models.News, note string) error {
+	message := s.formatMessage(news, chatID)
+	if message == "" {
+		return fmt.Errorf("消息内容为空")
+	}
+	if note != "" {
+		message += "\n\n" + note
+	}
+	return s.editMessage(ctx, chatID, messageID, message)
+}
+
+// editMessage 编辑指定聊天里的一条已发送消息
+func (s *Sender) editMessage(ctx context.Context, chatID string, messageID int64, message string) error {
+	if s.bot == nil {
+		return nil
+	}
+
+	parsedChatID := parseChatID(s.resolveChatID(chatID))
+	if parsedChatID == 0 {
+		return fmt.Errorf("解析聊天ID失败: %s", chatID)
+	}
+
+	edit := tgbotapi.NewEditMessageText(parsedChatID, int(messageID), message)
+	edit.ParseMode = tgbotapi.ModeHTML
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		if _, err := s.bot.Send(edit); err != nil {
+			return fmt.Errorf("编辑消息失败: %v", err)
 		}
+		return nil
+	}
+}
+
+// SendText 向指定聊天（为空时使用配置里的全部聊天）发送一条纯文本消息，
+// 用于情绪指数摘要等不依附于具体新闻条目的推送
+func (s *Sender) SendText(ctx context.Context, chatIDs []string, text string) error {
+	if s.bot == nil {
+		log.Printf("Telegram 未启用，跳过文本推送")
+		return nil
+	}
+
+	if len(chatIDs) == 0 {
+		chatIDs = s.cfg.Bot.ChatIDs
+	}
 
-		if lastErr == nil {
-			return nil
+	results := s.sendToChats(ctx, &models.News{Source: "sentiment"}, text, chatIDs, nil)
+	var lastErr error
+	for _, r := range results {
+		if r.err != nil {
+			lastErr = r.err
+			log.Printf("文本推送到聊天 %s 失败: %v", r.chatID, r.err)
 		}
 	}
+	return lastErr
+}
+
+// sendToChats 并发地把消息发送到给定的聊天列表，发送速率受全局限速器约束；
+// message 为空时按每个聊天各自的时间格式配置调用 formatMessage 分别渲染，
+// 非空时（如情绪指数摘要等纯文本推送）原样发给所有聊天。markup 为 nil 表示
+// 消息不带内联按钮
+func (s *Sender) sendToChats(ctx context.Context, news *models.News, message string, chatIDs []string, markup *tgbotapi.InlineKeyboardMarkup) []chatResult {
+	results := make([]chatResult, len(chatIDs))
+
+	var wg sync.WaitGroup
+	for i, chatID := range chatIDs {
+		wg.Add(1)
+		go func(i int, chatID string) {
+			defer wg.Done()
+			if err := s.limiter.Wait(ctx); err != nil {
+				results[i] = chatResult{chatID: chatID, err: err}
+				return
+			}
+			chatMessage := message
+			if chatMessage == "" {
+				chatMessage = s.formatMessage(news, chatID)
+			}
+			log.Printf("[%s] 正在发送到聊天 %s: %s (ID: %v)", news.Source, chatID, news.OriginalTitle, news.ID)
+			messageID, err := s.sendToChat(ctx, chatID, chatMessage, markup)
+			results[i] = chatResult{chatID: chatID, messageID: messageID, err: err}
+		}(i, chatID)
+	}
+	wg.Wait()
 
-	return fmt.Errorf("发送新闻失败: %v", lastErr)
+	return results
 }
 
-// sendToChat 发送消息到指定聊天
-func (s *Sender) sendToChat(ctx context.Context, chatID string, message string) error {
+// sendToChat 发送消息到指定聊天，返回发送成功后 Telegram 分配的消息ID。
+// markup 为 nil 表示消息不带内联按钮
+func (s *Sender) sendToChat(ctx context.Context, chatID string, message string, markup *tgbotapi.InlineKeyboardMarkup) (int64, error) {
+	start := time.Now()
 	if chatID == "" {
-		return fmt.Errorf("无效的聊天ID")
+		return 0, fmt.Errorf("无效的聊天ID")
 	}
 
-	// 解析聊天ID
-	parsedChatID := parseChatID(chatID)
+	// 解析聊天ID，若该聊天此前已升级为超级群组，透明地替换成迁移记录里的新聊天ID
+	resolvedChatID := s.resolveChatID(chatID)
+	parsedChatID := parseChatID(resolvedChatID)
 	if parsedChatID == 0 {
-		return fmt.Errorf("解析聊天ID失败: %s", chatID)
+		return 0, fmt.Errorf("解析聊天ID失败: %s", chatID)
+	}
+
+	if s.dedupe != nil && !s.dedupe.allow(chatID, message) {
+		log.Printf("[窗口内重复] 聊天 %s 已发送过字节相同的消息，跳过本次发送", chatID)
+		eventlog.Event(chatID, "send_telegram", "", "duplicate_skipped", 0)
+		if s.dedupStats != nil {
+			s.dedupStats.Record("content_hash")
+		}
+		return 0, nil
 	}
 
 	log.Printf("准备发送消息到聊天ID: %d", parsedChatID)
-	log.Printf("消息内容: %s", message)
+	// 消息全文默认不打日志，仅在开启 debug_dump 时输出（并做截断），避免正文泄露
+	eventlog.Dump(fmt.Sprintf("message:%d", parsedChatID), message)
 
 	msg := tgbotapi.NewMessage(parsedChatID, message)
 	msg.ParseMode = tgbotapi.ModeHTML
 	msg.DisableWebPagePreview = true
+	if markup != nil {
+		msg.ReplyMarkup = markup
+	}
 
 	select {
 	case <-ctx.Done():
-		return ctx.Err()
+		eventlog.Event(chatID, "send_telegram", "", "error", time.Since(start))
+		return 0, ctx.Err()
 	default:
 		log.Printf("正在调用Telegram API发送消息...")
-		_, err := s.bot.Send(msg)
+		sent, err := s.bot.Send(msg)
+		if newChatID, migrated := migratedChatID(err); migrated {
+			log.Printf("群组 %s 已升级为超级群组，新聊天ID: %s，更新记录并重试", resolvedChatID, newChatID)
+			s.handleMigration(ctx, resolvedChatID, newChatID)
+			msg.BaseChat.ChatID = parseChatID(newChatID)
+			sent, err = s.bot.Send(msg)
+		}
 		if err != nil {
 			log.Printf("发送消息到聊天 %d 失败: %v", parsedChatID, err)
-			return fmt.Errorf("发送消息失败: %v", err)
+			eventlog.Event(chatID, "send_telegram", "", "error", time.Since(start))
+			return 0, fmt.Errorf("发送消息失败: %v", err)
 		}
 		log.Printf("成功发送消息到聊天 %d", parsedChatID)
-		return nil
+		eventlog.Event(chatID, "send_telegram", "", "success", time.Since(start))
+		return int64(sent.MessageID), nil
 	}
 }
 
-// formatMessage 格式化消息
-func (s *Sender) formatMessage(news *models.News) string {
-	// 清理文本中的 HTML 标签
-	cleanText := func(text string) string {
-		text = strings.ReplaceAll(text, "<br>", "\n")
-		text = strings.ReplaceAll(text, "<p>", "\n")
-		text = strings.ReplaceAll(text, "</p>", "\n")
-		text = strings.ReplaceAll(text, "<img", "[图片]")
-		text = strings.ReplaceAll(text, "<a", "[链接]")
-		text = strings.ReplaceAll(text, "</a>", "")
-		return text
+// previewNews 是 /testformat 命令用来渲染示例条目的固定新闻数据，覆盖标题、
+// 正文、AI 分析、情绪分数、标签等字段，尽量还原真实推送时会用到的各项内容
+func previewNews() *models.News {
+	return &models.News{
+		ID:                "preview",
+		OriginalTitle:     "Example Corp announces breakthrough in quantum computing",
+		OriginalContent:   "This is a sample article body used to preview message formatting and layout.",
+		TranslatedTitle:   "示例公司宣布量子计算突破",
+		TranslatedContent: "这是一段用于预览消息排版效果的示例正文。",
+		Summary:           "示例公司发布量子计算新进展，行业反响积极。",
+		Analysis:          "类型：科技；影响范围：全球；重要性：中；潜在影响：技术；建议行动：关注。",
+		SentimentScore:    0.6,
+		Tags:              []string{"watchlist:ExampleCorp"},
+		Link:              "https://example.com/news/quantum-breakthrough",
+		CreateTime:        time.Now(),
+		Source:            "preview",
+	}
+}
+
+// SendPreview 用示例新闻按 chatID 专属的模板/路由/展示配置（时区、内容展示模式、
+// 标题改写、话题标签等）渲染并发送一条预览消息，供 /testformat 命令验证排版
+// 改动是否符合预期，不必等待下一条真实条目
+func (s *Sender) SendPreview(ctx context.Context, chatID string) error {
+	message := s.formatMessage(previewNews(), chatID)
+	_, err := s.sendToChat(ctx, chatID, message, nil)
+	return err
+}
+
+// formatMessage 格式化消息，chatID 用于挑选该聊天专属的时间显示配置（时区/格式/相对时间），
+// 传空串则使用 telegram.time_format 的全局默认配置
+func (s *Sender) formatMessage(news *models.News, chatID string) string {
+	content := s.contentOptions(chatID)
+	tags := s.hashtagLine(news, chatID)
+
+	if content.Mode == "bilingual" {
+		return s.formatBilingualMessage(news, chatID, content, tags)
 	}
 
+	title := s.displayTitle(news, chatID)
+
 	// 格式化消息
-	message := fmt.Sprintf("📰 *%s*\n\n", news.OriginalTitle)
-	message += fmt.Sprintf("�� 发布时间: %s\n", news.CreateTime.Format("2006-01-02 15:04"))
-	message += fmt.Sprintf("🔗 原文链接: %s\n\n", news.Link)
-	message += fmt.Sprintf("📝 内容摘要:\n%s\n\n", cleanText(news.OriginalContent))
+	message := fmt.Sprintf("📰 *%s*\n\n", title)
+	message += fmt.Sprintf("🕒 发布时间: %s\n", s.formatTime(news.CreateTime, chatID))
+	message += fmt.Sprintf("🔗 原文链接: %s\n", news.Link)
+
+	if content.Mode == "title_link" {
+		if tags != "" {
+			message += "\n" + tags
+		}
+		return message
+	}
+	message += "\n"
+
+	if content.Mode == "summary_only" {
+		if news.Summary != "" {
+			message += fmt.Sprintf("📝 摘要:\n%s\n\n", news.Summary)
+		}
+	} else {
+		body := htmltotext.Convert(news.OriginalContent, s.htmlOptions(chatID))
+		body = truncateAtSentence(body, content.MaxLength)
+		message += fmt.Sprintf("📝 内容摘要:\n%s\n\n", body)
+	}
 
 	// 添加 AI 分析部分
 	if news.Analysis != "" {
@@ -165,9 +442,202 @@ func (s *Sender) formatMessage(news *models.News) string {
 		message += news.Analysis
 	}
 
+	if tags != "" {
+		message += "\n\n" + tags
+	}
+
 	return message
 }
 
+// formatBilingualMessage 渲染 content.mode 为 "bilingual" 时的双语版式：标题同时
+// 展示译文标题和原标题，正文同时展示原文摘要和译文/摘要，供中英文混合受众的加密货币
+// 频道等场景使用，此模式下不再走标题改写（displayTitle）逻辑
+func (s *Sender) formatBilingualMessage(news *models.News, chatID string, content config.ContentConfig, tags string) string {
+	title := news.OriginalTitle
+	if news.TranslatedTitle != "" && news.TranslatedTitle != news.OriginalTitle {
+		title = fmt.Sprintf("%s\n%s", news.TranslatedTitle, news.OriginalTitle)
+	}
+
+	message := fmt.Sprintf("📰 *%s*\n\n", title)
+	message += fmt.Sprintf("🕒 发布时间: %s\n", s.formatTime(news.CreateTime, chatID))
+	message += fmt.Sprintf("🔗 原文链接: %s\n\n", news.Link)
+
+	original := htmltotext.Convert(news.OriginalContent, s.htmlOptions(chatID))
+	original = truncateAtSentence(original, content.MaxLength)
+	message += fmt.Sprintf("🇬🇧 原文:\n%s\n\n", original)
+
+	translated := news.TranslatedContent
+	if translated == "" {
+		translated = news.Summary
+	}
+	if translated != "" {
+		translated = truncateAtSentence(translated, content.MaxLength)
+		message += fmt.Sprintf("🇨🇳 译文:\n%s\n\n", translated)
+	}
+
+	if news.Analysis != "" {
+		message += "🤖 *AI 分析*\n\n"
+		message += news.Analysis
+	}
+
+	if tags != "" {
+		message += "\n\n" + tags
+	}
+
+	return message
+}
+
+// displayTitle 按 chatID 对应的标题改写配置，返回该聊天消息标题中实际展示的文字；
+// 未开启时原样返回原标题
+func (s *Sender) displayTitle(news *models.News, chatID string) string {
+	cfg := s.titleRewriteOptions(chatID)
+	if cfg == nil || !cfg.Enabled {
+		return news.OriginalTitle
+	}
+
+	rewritten := titlerewrite.Rewrite(news.OriginalTitle)
+	if cfg.ShowOriginal && rewritten != news.OriginalTitle {
+		return fmt.Sprintf("%s（原标题：%s）", rewritten, news.OriginalTitle)
+	}
+	return rewritten
+}
+
+// titleRewriteOptions 按 chatID 对应的标题改写配置（chat_title_rewrite 未命中则用
+// 全局 title_rewrite，都未配置则返回 nil，表示不改写）
+func (s *Sender) titleRewriteOptions(chatID string) *config.TitleRewriteConfig {
+	cfg := s.cfg.TitleRewrite
+	if perChat, ok := s.cfg.ChatTitleRewrite[chatID]; ok {
+		cfg = perChat
+	}
+	return cfg
+}
+
+// hashtagLine 按 chatID 对应的话题标签配置，从命中的关注名单实体和数据源名称
+// 生成一行 "#Tag1 #Tag2" 附在消息末尾；未开启或没有可用标签时返回空字符串
+func (s *Sender) hashtagLine(news *models.News, chatID string) string {
+	cfg := s.hashtagOptions(chatID)
+	if cfg == nil || !cfg.Enabled {
+		return ""
+	}
+	tags := hashtag.Generate(watchlistEntities(news.Tags), news.Source, cfg.MaxTags)
+	return hashtag.Format(tags)
+}
+
+// hashtagOptions 按 chatID 对应的话题标签配置（chat_hashtag 未命中则用全局
+// hashtag，都未配置则返回 nil，表示不附加）
+func (s *Sender) hashtagOptions(chatID string) *config.HashtagConfig {
+	cfg := s.cfg.Hashtag
+	if perChat, ok := s.cfg.ChatHashtag[chatID]; ok {
+		cfg = perChat
+	}
+	return cfg
+}
+
+// watchlistEntities 从新闻的标签列表里提取关注名单命中的实体名称，
+// 即去掉 "watchlist:" 前缀后的部分
+func watchlistEntities(tags []string) []string {
+	var entities []string
+	for _, t := range tags {
+		if e, ok := strings.CutPrefix(t, "watchlist:"); ok {
+			entities = append(entities, e)
+		}
+	}
+	return entities
+}
+
+// htmlOptions 按 chatID 对应的 HTML 处理配置（chat_html 未命中则用全局 html，
+// 都未配置则按默认的 inline 链接处理方式）构造正文转换选项
+func (s *Sender) htmlOptions(chatID string) htmltotext.Options {
+	cfg := s.cfg.HTML
+	if perChat, ok := s.cfg.ChatHTML[chatID]; ok {
+		cfg = perChat
+	}
+	if cfg == nil {
+		return htmltotext.Options{}
+	}
+	return htmltotext.Options{LinkMode: htmltotext.LinkMode(cfg.LinkMode)}
+}
+
+// contentOptions 按 chatID 对应的正文展示配置（chat_content 未命中则用全局 content，
+// 都未配置则不限长、展示原文摘要+AI分析）
+func (s *Sender) contentOptions(chatID string) config.ContentConfig {
+	cfg := s.cfg.Content
+	if perChat, ok := s.cfg.ChatContent[chatID]; ok {
+		cfg = perChat
+	}
+	if cfg == nil {
+		return config.ContentConfig{}
+	}
+	return *cfg
+}
+
+// truncateAtSentence 把文本截断到不超过 maxLength 个字符，尽量在最靠近上限的句子边界
+// （中英文句号、问号、叹号）处截断，找不到句子边界时直接截断并补上省略号；
+// maxLength 不大于 0 或文本本身未超长时原样返回
+func truncateAtSentence(text string, maxLength int) string {
+	runes := []rune(text)
+	if maxLength <= 0 || len(runes) <= maxLength {
+		return text
+	}
+
+	truncated := string(runes[:maxLength])
+	cut := -1
+	for _, boundary := range []string{"。", "！", "？", ". ", "! ", "? "} {
+		if idx := strings.LastIndex(truncated, boundary); idx > cut {
+			cut = idx + len(boundary)
+		}
+	}
+	if cut > 0 {
+		return strings.TrimSpace(truncated[:cut])
+	}
+	return truncated + "…"
+}
+
+// formatTime 按 chatID 对应的时间格式配置（chat_time_formats 未命中则用全局 time_format，
+// 都未配置则用系统本地时区的默认布局）把时间转换为展示字符串，relative 开启时优先显示相对时间
+func (s *Sender) formatTime(t time.Time, chatID string) string {
+	cfg := s.cfg.TimeFormat
+	if perChat, ok := s.cfg.ChatTimeFormats[chatID]; ok {
+		cfg = perChat
+	}
+	if cfg == nil {
+		return t.Format("2006-01-02 15:04")
+	}
+	if cfg.Relative {
+		return formatRelativeTime(t)
+	}
+
+	loc := time.Local
+	if cfg.Timezone != "" {
+		if l, err := time.LoadLocation(cfg.Timezone); err != nil {
+			log.Printf("加载时间格式时区失败，使用系统本地时区: %v", err)
+		} else {
+			loc = l
+		}
+	}
+
+	layout := cfg.Layout
+	if layout == "" {
+		layout = "2006-01-02 15:04"
+	}
+	return t.In(loc).Format(layout)
+}
+
+// formatRelativeTime 把时间转换为“5分钟前”一类的相对时间展示，超过一天则按天计
+func formatRelativeTime(t time.Time) string {
+	elapsed := time.Since(t)
+	switch {
+	case elapsed < time.Minute:
+		return "刚刚"
+	case elapsed < time.Hour:
+		return fmt.Sprintf("%d分钟前", int(elapsed.Minutes()))
+	case elapsed < 24*time.Hour:
+		return fmt.Sprintf("%d小时前", int(elapsed.Hours()))
+	default:
+		return fmt.Sprintf("%d天前", int(elapsed.Hours()/24))
+	}
+}
+
 // parseChatID 解析聊天ID
 func parseChatID(chatID string) int64 {
 	log.Printf("开始解析聊天ID: %s", chatID)
@@ -183,6 +653,49 @@ func parseChatID(chatID string) int64 {
 	return id
 }
 
+// resolveChatID 把配置里的聊天ID替换成迁移记录里对应的新聊天ID（如果有），
+// 未启用迁移记录持久化或该聊天从未发生过迁移时原样返回
+func (s *Sender) resolveChatID(chatID string) string {
+	if s.migrations == nil {
+		return chatID
+	}
+	if newChatID, ok := s.migrations.Get(chatID); ok {
+		return newChatID
+	}
+	return chatID
+}
+
+// migratedChatID 从 Telegram 返回的错误中提取群组升级为超级群组后的新聊天ID
+func migratedChatID(err error) (string, bool) {
+	var tgErr *tgbotapi.Error
+	if errors.As(err, &tgErr) && tgErr.MigrateToChatID != 0 {
+		return strconv.FormatInt(tgErr.MigrateToChatID, 10), true
+	}
+	return "", false
+}
+
+// handleMigration 持久化一次聊天ID迁移并通知管理员，迁移记录写入失败或未配置
+// 管理员ID时只记录日志，不影响本次发送继续用新聊天ID重试
+func (s *Sender) handleMigration(ctx context.Context, oldChatID, newChatID string) {
+	if s.migrations != nil {
+		if err := s.migrations.Update(oldChatID, newChatID); err != nil {
+			log.Printf("持久化聊天ID迁移记录失败: %v", err)
+		}
+	}
+
+	if s.cfg == nil || s.cfg.Commands == nil || len(s.cfg.Commands.AdminIDs) == 0 {
+		return
+	}
+	adminChatIDs := make([]string, 0, len(s.cfg.Commands.AdminIDs))
+	for _, id := range s.cfg.Commands.AdminIDs {
+		adminChatIDs = append(adminChatIDs, strconv.FormatInt(id, 10))
+	}
+	text := fmt.Sprintf("⚠️ 群组已升级为超级群组\n旧聊天ID: %s\n新聊天ID: %s\n配置已在运行时自动更新并持久化", oldChatID, newChatID)
+	if err := s.SendText(ctx, adminChatIDs, text); err != nil {
+		log.Printf("通知管理员聊天ID迁移失败: %v", err)
+	}
+}
+
 // sendTestMessage 发送测试消息
 func sendTestMessage(chatID int64) bool {
 	// 创建机器人
@@ -0,0 +1,52 @@
+// Package sentiment 维护一个滚动的新闻情绪指数：按类别累积 AI 给出的情绪分数，
+// 供定时任务周期性汇总并推送（如每小时的加密货币恐慌贪婪风格摘要）。
+package sentiment
+
+import "sync"
+
+// Index 按类别累积情绪分数
+type Index struct {
+	mu     sync.Mutex
+	sums   map[string]float64
+	counts map[string]int
+}
+
+// NewIndex 创建一个空的情绪指数
+func NewIndex() *Index {
+	return &Index{
+		sums:   make(map[string]float64),
+		counts: make(map[string]int),
+	}
+}
+
+// Add 记录一条新闻的情绪分数，category 为空时归入"综合"类别
+func (idx *Index) Add(category string, score float64) {
+	if category == "" {
+		category = "综合"
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.sums[category] += score
+	idx.counts[category]++
+}
+
+// Snapshot 返回自上次调用以来各类别的平均情绪分数，并清空累计状态，
+// 供定时任务按固定周期（如每小时）滚动统计
+func (idx *Index) Snapshot() map[string]float64 {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	result := make(map[string]float64, len(idx.sums))
+	for category, sum := range idx.sums {
+		count := idx.counts[category]
+		if count == 0 {
+			continue
+		}
+		result[category] = sum / float64(count)
+	}
+
+	idx.sums = make(map[string]float64)
+	idx.counts = make(map[string]int)
+	return result
+}
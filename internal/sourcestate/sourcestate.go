@@ -0,0 +1,83 @@
+// Package sourcestate 提供逐数据源的小型持久化键值存储（如分页游标、鉴权令牌、
+// ETag），供数据源实现通过 Source 接口的可选扩展点保存自己的抓取状态，
+// 不必各自发明一套文件格式
+package sourcestate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Store 按数据源（以 URL 为键）分区保存任意字符串键值对，整体持久化到一个 JSON 文件
+type Store struct {
+	mu   sync.Mutex
+	path string
+	data map[string]map[string]string
+}
+
+// NewStore 创建一个数据源状态存储，文件不存在时视为空
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path, data: make(map[string]map[string]string)}
+
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取数据源状态文件失败: %v", err)
+	}
+	if err := json.Unmarshal(raw, &s.data); err != nil {
+		return nil, fmt.Errorf("解析数据源状态文件失败: %v", err)
+	}
+	return s, nil
+}
+
+// Get 返回某个数据源的某个状态键，不存在时返回空字符串和 false
+func (s *Store) Get(source, key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.data[source][key]
+	return v, ok
+}
+
+// Set 写入某个数据源的某个状态键并立即持久化
+func (s *Store) Set(source, key, value string) error {
+	s.mu.Lock()
+	if s.data[source] == nil {
+		s.data[source] = make(map[string]string)
+	}
+	s.data[source][key] = value
+	raw, err := json.MarshalIndent(s.data, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("序列化数据源状态失败: %v", err)
+	}
+	if err := os.WriteFile(s.path, raw, 0644); err != nil {
+		return fmt.Errorf("写入数据源状态文件失败: %v", err)
+	}
+	return nil
+}
+
+// For 返回绑定到某个数据源的命名空间视图，供数据源实现在自己的 Fetch 方法里
+// 用不带 source 参数的 Get/Set 读写自己的状态，无需自己记住 source 标识
+func (s *Store) For(source string) *Namespace {
+	return &Namespace{store: s, source: source}
+}
+
+// Namespace 是绑定到单个数据源的状态视图
+type Namespace struct {
+	store  *Store
+	source string
+}
+
+// Get 返回当前数据源的某个状态键，不存在时返回空字符串和 false
+func (n *Namespace) Get(key string) (string, bool) {
+	return n.store.Get(n.source, key)
+}
+
+// Set 写入当前数据源的某个状态键并立即持久化
+func (n *Namespace) Set(key, value string) error {
+	return n.store.Set(n.source, key, value)
+}
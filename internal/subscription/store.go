@@ -0,0 +1,48 @@
+// Package subscription 持久化通过 /subscribe 命令运行期添加的 RSS 源，
+// 使其在进程重启后仍然生效，而不只是存在于 Fetcher 的内存状态中。
+package subscription
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Store 把订阅的 RSS URL 列表保存到一个 JSON 文件
+type Store struct {
+	path string
+}
+
+// NewStore 创建一个订阅存储，path 是持久化文件的路径
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Load 读取已持久化的 URL 列表，文件不存在时返回空列表
+func (s *Store) Load() ([]string, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取订阅文件失败: %v", err)
+	}
+
+	var urls []string
+	if err := json.Unmarshal(data, &urls); err != nil {
+		return nil, fmt.Errorf("解析订阅文件失败: %v", err)
+	}
+	return urls, nil
+}
+
+// Save 把 URL 列表整体覆盖写入持久化文件
+func (s *Store) Save(urls []string) error {
+	data, err := json.MarshalIndent(urls, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化订阅列表失败: %v", err)
+	}
+	if err := os.WriteFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("写入订阅文件失败: %v", err)
+	}
+	return nil
+}
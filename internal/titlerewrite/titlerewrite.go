@@ -0,0 +1,35 @@
+// Package titlerewrite 提供标题党标题的规则式中性化改写：去除夸张的标点堆叠、
+// 常见的悬念/夸大套话，让语气更接近客观陈述，不依赖 AI 调用
+package titlerewrite
+
+import (
+	"regexp"
+	"strings"
+)
+
+// clickbaitPhrases 常见的标题党套话，按原样子串删除
+var clickbaitPhrases = []string{
+	"震惊！", "震惊", "惊爆", "重磅！", "重磅", "刷屏了", "炸锅了", "沸腾了",
+	"万万没想到", "你绝对想不到", "太恐怖了", "太意外了", "疯传",
+	"必看", "速看", "紧急扩散", "转疯了",
+}
+
+// exclamationRun 匹配连续两个及以上的感叹号或问号，改写时收敛为一个句号
+var exclamationRun = regexp.MustCompile(`[！!？?]{2,}`)
+
+// Rewrite 把一条标题改写成语气更中性的版本：删除常见标题党套话、把连续的
+// 感叹号/问号收敛为一个句号、去掉首尾多余的空白和标点；改写后为空时返回原标题，
+// 避免把整条标题清空
+func Rewrite(title string) string {
+	rewritten := title
+	for _, phrase := range clickbaitPhrases {
+		rewritten = strings.ReplaceAll(rewritten, phrase, "")
+	}
+	rewritten = exclamationRun.ReplaceAllString(rewritten, "。")
+	rewritten = strings.Trim(rewritten, " \t　，,。！!？?")
+	rewritten = strings.TrimSpace(rewritten)
+	if rewritten == "" {
+		return title
+	}
+	return rewritten
+}
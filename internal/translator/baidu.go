@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/amylixing/news-fetcher/internal/config"
+	"github.com/amylixing/news-fetcher/internal/httpclient"
 )
 
 // BaiduTranslator 百度翻译实现
@@ -58,8 +59,12 @@ func (t *BaiduTranslator) Translate(ctx context.Context, text string) (string, e
 	params.Set("sign", sign)
 
 	// 创建HTTP客户端
-	client := &http.Client{
-		Timeout: t.timeout,
+	client, err := httpclient.New(httpclient.Options{
+		ProxyURL: t.cfg.ProxyURL,
+		Timeout:  t.timeout,
+	})
+	if err != nil {
+		return "", fmt.Errorf("创建HTTP客户端失败: %v", err)
 	}
 
 	// 发送请求
@@ -3,12 +3,11 @@ package translator
 import (
 	"context"
 	"fmt"
-	"net/http"
-	"net/url"
 	"time"
 
 	"cloud.google.com/go/translate"
 	"github.com/amylixing/news-fetcher/internal/config"
+	"github.com/amylixing/news-fetcher/internal/httpclient"
 	"golang.org/x/text/language"
 	"google.golang.org/api/option"
 )
@@ -36,18 +35,12 @@ func (t *GoogleTranslator) Init() error {
 	defer cancel()
 
 	// 配置HTTP客户端
-	proxyURL, err := url.Parse(t.cfg.ProxyURL)
+	httpClient, err := httpclient.New(httpclient.Options{
+		ProxyURL: t.cfg.ProxyURL,
+		Timeout:  t.timeout,
+	})
 	if err != nil {
-		return fmt.Errorf("解析代理URL失败: %v", err)
-	}
-
-	transport := &http.Transport{
-		Proxy: http.ProxyURL(proxyURL),
-	}
-
-	httpClient := &http.Client{
-		Transport: transport,
-		Timeout:   t.timeout,
+		return fmt.Errorf("创建HTTP客户端失败: %v", err)
 	}
 
 	client, err := translate.NewClient(ctx, option.WithAPIKey(t.cfg.APIKey), option.WithHTTPClient(httpClient))
@@ -13,7 +13,9 @@ import (
 	"time"
 
 	"cloud.google.com/go/translate"
+	"github.com/amylixing/news-fetcher/internal/budget"
 	"github.com/amylixing/news-fetcher/internal/config"
+	"github.com/amylixing/news-fetcher/internal/httpclient"
 	"github.com/amylixing/news-fetcher/internal/models"
 	"golang.org/x/text/language"
 	"google.golang.org/api/option"
@@ -21,6 +23,9 @@ import (
 
 var translatorConfig config.TranslatorConfig
 
+// budgetKey 是 Translator 在 budget.Tracker 中的计数键
+const budgetKey = "translator"
+
 // InitTranslator 初始化翻译器配置
 func InitTranslator(cfg config.TranslatorConfig) {
 	translatorConfig = cfg
@@ -38,6 +43,7 @@ type Translator struct {
 	targetLanguage language.Tag
 	timeout        time.Duration
 	cfg            config.TranslatorConfig
+	budget         *budget.Tracker
 }
 
 // NewTranslator 创建新的翻译器
@@ -46,24 +52,28 @@ func NewTranslator(cfg config.TranslatorConfig) *Translator {
 		targetLanguage: language.Make(cfg.TargetLanguage),
 		timeout:        time.Duration(cfg.Timeout) * time.Second,
 		cfg:            cfg,
+		budget:         budget.New(),
+	}
+}
+
+// BudgetUsage 返回今天已消耗的翻译请求次数及上限，供 /budget 一类的用量查询命令使用；
+// 未开启每日额度时 Limit 为 0
+func (t *Translator) BudgetUsage() (used int, limit int) {
+	if t.budget == nil {
+		return 0, 0
 	}
+	return t.budget.Usage()[budgetKey].Used, t.cfg.DailyBudget
 }
 
 // Init 初始化翻译器
 func (t *Translator) Init() error {
 	// 配置HTTP客户端
-	client := &http.Client{
-		Timeout: t.timeout,
-	}
-
-	if t.cfg.ProxyURL != "" {
-		proxyURL, err := url.Parse(t.cfg.ProxyURL)
-		if err != nil {
-			return fmt.Errorf("解析代理URL失败: %v", err)
-		}
-		client.Transport = &http.Transport{
-			Proxy: http.ProxyURL(proxyURL),
-		}
+	client, err := httpclient.New(httpclient.Options{
+		ProxyURL: t.cfg.ProxyURL,
+		Timeout:  t.timeout,
+	})
+	if err != nil {
+		return fmt.Errorf("创建HTTP客户端失败: %v", err)
 	}
 
 	// 创建翻译客户端
@@ -92,6 +102,11 @@ func (t *Translator) TranslateNews(ctx context.Context, newsList []models.News)
 		return newsList, nil
 	}
 
+	if !t.budget.TryConsume(budgetKey, t.cfg.DailyBudget) {
+		fmt.Printf("翻译每日请求额度已用尽（%d 次），本次跳过翻译，保留原文\n", t.cfg.DailyBudget)
+		return newsList, nil
+	}
+
 	fmt.Printf("开始翻译 %d 条新闻\n", len(newsList))
 
 	// 创建超时上下文
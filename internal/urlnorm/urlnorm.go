@@ -0,0 +1,98 @@
+// Package urlnorm 对新闻链接做规范化：去除易变的追踪参数（utm_* 等），
+// 可选跟随重定向解析出最终地址，避免同一篇文章因追踪参数不同而被反复当作新的条目
+package urlnorm
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// DefaultStripParams 是未配置时使用的默认追踪参数列表，支持以 "*" 结尾的前缀匹配
+var DefaultStripParams = []string{"utm_*", "ref", "fbclid", "gclid", "spm"}
+
+// StripTrackingParams 去除 URL 查询字符串中匹配 params 的参数，params 为空时
+// 使用 DefaultStripParams；rawURL 无法解析时原样返回
+func StripTrackingParams(rawURL string, params []string) string {
+	if rawURL == "" {
+		return rawURL
+	}
+	if len(params) == 0 {
+		params = DefaultStripParams
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	query := u.Query()
+	for key := range query {
+		if matchesAny(key, params) {
+			query.Del(key)
+		}
+	}
+
+	// url.Values.Encode 按键排序，保证同一链接每次规范化后结果一致
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+func matchesAny(key string, patterns []string) bool {
+	lowerKey := strings.ToLower(key)
+	for _, pattern := range patterns {
+		lowerPattern := strings.ToLower(pattern)
+		if strings.HasSuffix(lowerPattern, "*") {
+			if strings.HasPrefix(lowerKey, strings.TrimSuffix(lowerPattern, "*")) {
+				return true
+			}
+			continue
+		}
+		if lowerKey == lowerPattern {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveRedirect 用 HEAD 请求跟随重定向，返回最终落地的地址；client 需要允许
+// 自动跟随重定向（Go 默认行为）。maxHops > 0 时最多跟随该跳数（用于 Google News
+// 等聚合器的多级中间跳转链接，避免个别链接的重定向异常拖住抓取），超出后返回
+// 已跟随到的最后一跳地址；maxHops <= 0 时使用 Go 标准库默认上限（10 跳）。
+// 请求失败时返回原始地址和错误
+func ResolveRedirect(ctx context.Context, client *http.Client, rawURL string, maxHops int) (string, error) {
+	if maxHops > 0 {
+		capped := *client
+		capped.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxHops {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		}
+		client = &capped
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, rawURL, nil)
+	if err != nil {
+		return rawURL, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return rawURL, err
+	}
+	defer resp.Body.Close()
+
+	if resp.Request != nil && resp.Request.URL != nil {
+		return resp.Request.URL.String(), nil
+	}
+	return rawURL, nil
+}
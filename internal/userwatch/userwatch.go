@@ -0,0 +1,154 @@
+// Package userwatch 管理用户通过 /watch 命令订阅的个人关键词，命中时把条目
+// 私信给订阅者，并对每个用户每小时的私信条数做限流，避免刷屏
+package userwatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Store 持久化每个用户的关键词订阅，并对私信投递做限流
+type Store struct {
+	mu         sync.Mutex
+	path       string
+	maxPerHour int // 0 表示不限制
+	keywords   map[int64][]string
+	quota      map[int64]*hourlyQuota
+}
+
+type hourlyQuota struct {
+	windowStart time.Time
+	sent        int
+}
+
+// NewStore 创建一个用户关键词订阅存储，maxPerHour 为单用户每小时最多投递条数，0 表示不限制
+func NewStore(path string, maxPerHour int) (*Store, error) {
+	s := &Store{
+		path:       path,
+		maxPerHour: maxPerHour,
+		keywords:   make(map[int64][]string),
+		quota:      make(map[int64]*hourlyQuota),
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取用户关键词订阅文件失败: %v", err)
+	}
+	if err := json.Unmarshal(data, &s.keywords); err != nil {
+		return nil, fmt.Errorf("解析用户关键词订阅文件失败: %v", err)
+	}
+	return s, nil
+}
+
+// Add 为用户添加一个关键词订阅，重复添加视为成功
+func (s *Store) Add(userID int64, keyword string) error {
+	keyword = strings.TrimSpace(keyword)
+	if keyword == "" {
+		return fmt.Errorf("关键词不能为空")
+	}
+
+	s.mu.Lock()
+	for _, k := range s.keywords[userID] {
+		if strings.EqualFold(k, keyword) {
+			s.mu.Unlock()
+			return nil
+		}
+	}
+	s.keywords[userID] = append(s.keywords[userID], keyword)
+	s.mu.Unlock()
+
+	return s.save()
+}
+
+// Remove 移除用户的一个关键词订阅，未找到时返回 false
+func (s *Store) Remove(userID int64, keyword string) (bool, error) {
+	s.mu.Lock()
+	list := s.keywords[userID]
+	removed := false
+	for i, k := range list {
+		if strings.EqualFold(k, keyword) {
+			s.keywords[userID] = append(list[:i], list[i+1:]...)
+			removed = true
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if !removed {
+		return false, nil
+	}
+	return true, s.save()
+}
+
+// Keywords 返回用户当前订阅的关键词列表
+func (s *Store) Keywords(userID int64) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.keywords[userID]...)
+}
+
+// Match 返回标题或正文命中了自己订阅关键词、且未超出每小时投递配额的用户ID列表，
+// 命中会立即计入配额，调用方应确保只在实际投递后才依赖这一副作用
+func (s *Store) Match(title, content string) []int64 {
+	haystack := strings.ToLower(title + " " + content)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var matched []int64
+	for userID, keywords := range s.keywords {
+		hit := false
+		for _, k := range keywords {
+			if strings.Contains(haystack, strings.ToLower(k)) {
+				hit = true
+				break
+			}
+		}
+		if !hit {
+			continue
+		}
+		if s.allow(userID) {
+			matched = append(matched, userID)
+		}
+	}
+	return matched
+}
+
+// allow 检查并占用一个用户的每小时配额，调用方必须持有 s.mu
+func (s *Store) allow(userID int64) bool {
+	if s.maxPerHour <= 0 {
+		return true
+	}
+
+	now := time.Now()
+	q := s.quota[userID]
+	if q == nil || now.Sub(q.windowStart) >= time.Hour {
+		q = &hourlyQuota{windowStart: now}
+		s.quota[userID] = q
+	}
+	if q.sent >= s.maxPerHour {
+		return false
+	}
+	q.sent++
+	return true
+}
+
+func (s *Store) save() error {
+	s.mu.Lock()
+	data, err := json.MarshalIndent(s.keywords, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("序列化用户关键词订阅失败: %v", err)
+	}
+	if err := os.WriteFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("写入用户关键词订阅文件失败: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+// Package wasmplugin 从一个目录批量发现 WASM 数据源插件，把每个插件展开成一条
+// fetcher 的 wasm 类型自定义数据源配置（见 fetcher.WASMSource），由内嵌的 wazero
+// 运行时在进程内直接执行，无需操作系统上另外安装 wasmtime/wasmer 等运行时
+package wasmplugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/amylixing/news-fetcher/internal/config"
+)
+
+// Discover 扫描 dir 下的 .wasm 文件，为每个插件构造一条 wasm 类型的
+// SourceConfig：url 为插件文件路径，交由 fetcher.NewWASMSource 加载执行；
+// dir 不存在时返回空列表而不是错误，方便插件目录是可选的
+func Discover(cfg *config.WASMPluginConfig) ([]*config.SourceConfig, error) {
+	if cfg == nil || !cfg.Enabled {
+		return nil, nil
+	}
+	if cfg.Dir == "" {
+		return nil, fmt.Errorf("wasm_plugins 必须配置 dir 字段指向插件目录")
+	}
+
+	entries, err := os.ReadDir(cfg.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取 WASM 插件目录失败: %v", err)
+	}
+
+	var sources []*config.SourceConfig
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".wasm") {
+			continue
+		}
+		sources = append(sources, &config.SourceConfig{
+			Type:    "wasm",
+			URL:     filepath.Join(cfg.Dir, e.Name()),
+			Timeout: cfg.Timeout,
+		})
+	}
+	return sources, nil
+}
@@ -0,0 +1,36 @@
+// Package watchlist 按配置的实体名称（代币/项目）对新闻条目做子串匹配打标，
+// 用于把命中关注名单的条目单独打标签、路由到专属聊天，或过滤掉未命中的条目。
+package watchlist
+
+import "strings"
+
+// Matcher 持有一份小写化后的关注名单，供重复匹配使用
+type Matcher struct {
+	entities []string
+}
+
+// NewMatcher 创建匹配器，entities 为空时 Match 始终返回空结果
+func NewMatcher(entities []string) *Matcher {
+	m := &Matcher{entities: make([]string, len(entities))}
+	for i, e := range entities {
+		m.entities[i] = strings.ToLower(e)
+	}
+	return m
+}
+
+// Match 在标题和正文中查找命中的关注名单条目（大小写不敏感的子串匹配），
+// 返回命中的原始实体名称列表，未命中时返回空切片
+func (m *Matcher) Match(title, content string) []string {
+	if len(m.entities) == 0 {
+		return nil
+	}
+
+	haystack := strings.ToLower(title + " " + content)
+	var hits []string
+	for _, entity := range m.entities {
+		if entity != "" && strings.Contains(haystack, entity) {
+			hits = append(hits, entity)
+		}
+	}
+	return hits
+}
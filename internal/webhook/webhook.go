@@ -0,0 +1,143 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/amylixing/news-fetcher/internal/config"
+	"github.com/amylixing/news-fetcher/internal/models"
+)
+
+// 支持的负载格式
+const (
+	FormatIFTTT  = "ifttt"
+	FormatZapier = "zapier"
+	// FormatSchema 是携带 schema_version 的完整 News JSON 负载（见 models.NewsPayload），
+	// 面向需要拿到全部字段、自行按版本号做兼容处理的集成方（如 Kafka/自建导出管道）
+	FormatSchema = "schema"
+)
+
+// Webhook 通用 Webhook 发送器
+type Webhook struct {
+	cfg    *config.WebhookConfig
+	client *http.Client
+}
+
+// iftttPayload IFTTT Maker Webhooks 期望的扁平结构
+type iftttPayload struct {
+	Value1 string `json:"value1"`
+	Value2 string `json:"value2"`
+	Value3 string `json:"value3"`
+}
+
+// zapierPayload 通用的 Zapier 友好负载，字段名保持简单直白
+type zapierPayload struct {
+	Title     string `json:"title"`
+	Content   string `json:"content"`
+	Link      string `json:"link"`
+	Source    string `json:"source"`
+	Analysis  string `json:"analysis,omitempty"`
+	CreatedAt string `json:"created_at"`
+	TraceID   string `json:"trace_id,omitempty"`
+}
+
+// NewWebhook 创建新的 Webhook 发送器
+func NewWebhook(cfg *config.WebhookConfig) (*Webhook, error) {
+	if cfg == nil || !cfg.Enabled {
+		return &Webhook{cfg: cfg}, nil
+	}
+
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("未配置 Webhook URL")
+	}
+
+	return &Webhook{
+		cfg: cfg,
+		client: &http.Client{
+			Timeout: time.Duration(cfg.Timeout) * time.Second,
+		},
+	}, nil
+}
+
+// SendNews 将新闻以配置的格式推送到 Webhook
+func (w *Webhook) SendNews(ctx context.Context, news *models.News) error {
+	if w.cfg == nil || !w.cfg.Enabled {
+		return nil
+	}
+
+	body, err := w.buildPayload(news)
+	if err != nil {
+		return fmt.Errorf("构建 Webhook 负载失败: %v", err)
+	}
+
+	var lastErr error
+	retryCount, retryInterval := 0, 0
+	if w.cfg.Retry != nil {
+		retryCount, retryInterval = w.cfg.Retry.Count, w.cfg.Retry.Interval
+	}
+
+	for i := 0; i <= retryCount; i++ {
+		if i > 0 {
+			log.Printf("[webhook] 第%d次重试发送: %s (ID: %v, TraceID: %s)", i, news.OriginalTitle, news.ID, news.TraceID)
+			time.Sleep(time.Duration(retryInterval) * time.Second)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", w.cfg.URL, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("创建 Webhook 请求失败: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := w.client.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("发送 Webhook 请求失败: %v", err)
+			continue
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			log.Printf("[webhook] 成功推送: %s (ID: %v, TraceID: %s)", news.OriginalTitle, news.ID, news.TraceID)
+			return nil
+		}
+		lastErr = fmt.Errorf("Webhook 响应状态码异常: %d", resp.StatusCode)
+	}
+
+	return lastErr
+}
+
+// buildPayload 根据配置的格式构建请求体
+func (w *Webhook) buildPayload(news *models.News) ([]byte, error) {
+	switch w.cfg.Format {
+	case FormatIFTTT:
+		return json.Marshal(iftttPayload{
+			Value1: news.OriginalTitle,
+			Value2: news.OriginalContent,
+			Value3: news.Link,
+		})
+	case FormatZapier, "":
+		return json.Marshal(zapierPayload{
+			Title:     news.OriginalTitle,
+			Content:   news.OriginalContent,
+			Link:      news.Link,
+			Source:    news.Source,
+			Analysis:  news.Analysis,
+			CreatedAt: news.CreateTime.Format(time.RFC3339),
+			TraceID:   news.TraceID,
+		})
+	case FormatSchema:
+		payload := news.ToPayload()
+		// SchemaVersion<=0（未配置）或等于当前版本时发送最新字段集；显式配置为
+		// 旧版本号时降级为对应的兼容负载，供尚未升级的老消费方继续按原字段集解析
+		if w.cfg.SchemaVersion > 0 && w.cfg.SchemaVersion < models.CurrentSchemaVersion {
+			return json.Marshal(payload.ToV1())
+		}
+		return json.Marshal(payload)
+	default:
+		return nil, fmt.Errorf("不支持的 Webhook 负载格式: %s", w.cfg.Format)
+	}
+}
@@ -0,0 +1,138 @@
+// Package websub 实现 WebSub（原 PubSubHubbub）订阅所需的最小客户端和服务端
+// 能力：从 feed 原始内容里发现 hub 地址、向 hub 发起订阅请求、以及提供一个
+// 接收 hub 推送的回调 HTTP Handler。目标 feed 一旦支持 WebSub，就可以由 hub
+// 主动推送更新到本地回调地址，减少持续轮询的需求。
+package websub
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// linkElement 对应 RSS/Atom 里 <link rel="..." href="..."/> 声明的一条链接
+type linkElement struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+}
+
+// feedLinks 是一个足够宽松的 XML 结构，只用于把 feed 中带 rel/href 属性的
+// <link> 元素找出来，不解析其余字段，因此同时兼容 RSS 2.0（<link> 在
+// <channel> 下）和 Atom（<link> 直接是 <feed> 的子元素）两种格式
+type feedLinks struct {
+	XMLName   xml.Name
+	ChanLinks []linkElement `xml:"channel>link"`
+	RootLinks []linkElement `xml:"link"`
+}
+
+// DiscoverHub 从 feed 原始内容中查找 rel="hub" 声明的 WebSub hub 地址，以及
+// rel="self" 声明的 feed 规范地址（订阅时作为 hub.topic 上报给 hub）。
+// 未声明 hub 时 hub 返回空字符串，调用方应视为该 feed 不支持 WebSub，继续轮询
+func DiscoverHub(body []byte) (hub string, self string) {
+	var f feedLinks
+	if err := xml.Unmarshal(body, &f); err != nil {
+		return "", ""
+	}
+	for _, l := range append(append([]linkElement(nil), f.ChanLinks...), f.RootLinks...) {
+		switch strings.ToLower(l.Rel) {
+		case "hub":
+			if hub == "" {
+				hub = l.Href
+			}
+		case "self":
+			if self == "" {
+				self = l.Href
+			}
+		}
+	}
+	return hub, self
+}
+
+// Subscribe 向 hub 发起 WebSub 订阅请求（hub.mode=subscribe）。hub 收到合法
+// 请求后会向 callback 发起一次 GET 挑战验证，验证通过订阅才真正生效；本函数
+// 只负责发起请求本身并检查 hub 是否接受了该请求，不等待挑战完成
+func Subscribe(httpClient *http.Client, hub, topic, callback string, leaseSeconds int) error {
+	form := url.Values{}
+	form.Set("hub.mode", "subscribe")
+	form.Set("hub.topic", topic)
+	form.Set("hub.callback", callback)
+	if leaseSeconds > 0 {
+		form.Set("hub.lease_seconds", strconv.Itoa(leaseSeconds))
+	}
+
+	resp, err := httpClient.PostForm(hub, form)
+	if err != nil {
+		return fmt.Errorf("发送订阅请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// hub 接受订阅请求时按规范返回 202，个别 hub 实现会用 200/204，一并接受
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("hub 拒绝订阅请求，状态码: %d，响应: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// Handler 是 WebSub 回调端点的 http.Handler：GET 请求对应 hub 的订阅/退订挑战
+// 验证，原样回显 hub.challenge；POST 请求是 hub 推送的内容更新，读取请求体后
+// 交给按路径注册的回调处理，本身不解析 feed 格式。一个 Handler 可以按路径
+// 同时服务多个数据源的回调地址
+type Handler struct {
+	mu       sync.RWMutex
+	onNotify map[string]func([]byte)
+}
+
+// New 创建一个空的 WebSub 回调 Handler，通过 Register 按路径逐个绑定推送回调
+func New() *Handler {
+	return &Handler{onNotify: make(map[string]func([]byte))}
+}
+
+// Register 把某个回调路径和收到推送内容后的处理函数关联起来，path 应当与
+// 分配给对应数据源的订阅回调地址（hub.callback 的路径部分）一致
+func (h *Handler) Register(path string, onNotify func([]byte)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onNotify[path] = onNotify
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.RLock()
+	onNotify, ok := h.onNotify[r.URL.Path]
+	h.mu.RUnlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		challenge := r.URL.Query().Get("hub.challenge")
+		if challenge == "" {
+			http.Error(w, "缺少 hub.challenge", http.StatusBadRequest)
+			return
+		}
+		log.Printf("[websub] 收到挑战验证请求: %s mode=%s topic=%s", r.URL.Path, r.URL.Query().Get("hub.mode"), r.URL.Query().Get("hub.topic"))
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(challenge))
+	case http.MethodPost:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "读取推送内容失败", http.StatusBadRequest)
+			return
+		}
+		log.Printf("[websub] 收到推送: %s，%d 字节", r.URL.Path, len(body))
+		onNotify(bytes.TrimSpace(body))
+		w.WriteHeader(http.StatusOK)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
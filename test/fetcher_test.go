@@ -16,7 +16,7 @@ func TestFetcher(t *testing.T) {
 	assert.NoError(t, err)
 
 	// 创建抓取器
-	f, err := fetcher.NewFetcher(cfg.Sources)
+	f, err := fetcher.NewFetcher(cfg.Sources, cfg.HTTPClient)
 	assert.NoError(t, err)
 
 	// 创建带超时的上下文
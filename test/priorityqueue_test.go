@@ -0,0 +1,99 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/amylixing/news-fetcher/internal/models"
+	"github.com/amylixing/news-fetcher/internal/priorityqueue"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriorityQueuePopOrdersByImportance(t *testing.T) {
+	q := priorityqueue.New(0, 0.1)
+	q.Push([]*models.News{
+		{Source: "a", ID: "1", SentimentScore: 0.2},
+		{Source: "a", ID: "2", SentimentScore: 0.9},
+		{Source: "a", ID: "3", SentimentScore: 0.5},
+	})
+
+	popped := q.Pop()
+	assert.Len(t, popped, 3)
+	assert.Equal(t, "2", popped[0].ID)
+	assert.Equal(t, "3", popped[1].ID)
+	assert.Equal(t, "1", popped[2].ID)
+	assert.Zero(t, q.Pending())
+}
+
+func TestPriorityQueueDefersBeyondBudget(t *testing.T) {
+	q := priorityqueue.New(1, 0.1)
+	q.Push([]*models.News{
+		{Source: "a", ID: "high", SentimentScore: 0.9},
+		{Source: "a", ID: "low", SentimentScore: 0.1},
+	})
+
+	popped := q.Pop()
+	assert.Len(t, popped, 1)
+	assert.Equal(t, "high", popped[0].ID)
+	assert.Equal(t, 1, q.Pending())
+}
+
+func TestPriorityQueueAgingPreventsStarvation(t *testing.T) {
+	q := priorityqueue.New(1, 1.0)
+	q.Push([]*models.News{
+		{Source: "a", ID: "high", SentimentScore: 0.9},
+		{Source: "a", ID: "low", SentimentScore: 0.1},
+	})
+
+	// 第一轮：high 优先弹出，low 留在队列并老化一轮
+	popped := q.Pop()
+	assert.Equal(t, []string{"high"}, ids(popped))
+
+	// 第二轮：没有新条目涌入时，low 应该因为老化被弹出
+	popped = q.Pop()
+	assert.Equal(t, []string{"low"}, ids(popped))
+	assert.Zero(t, q.Pending())
+}
+
+func TestPriorityQueuePushDoesNotResetWaitCycles(t *testing.T) {
+	q := priorityqueue.New(1, 1.0)
+	q.Push([]*models.News{
+		{Source: "a", ID: "low", SentimentScore: 0.1},
+		{Source: "a", ID: "high", SentimentScore: 0.9},
+	})
+	popped := q.Pop() // high 优先弹出，low 留队并老化一轮（有效优先级变为 0.1+1.0=1.1）
+	assert.Equal(t, []string{"high"}, ids(popped))
+
+	// 同一条目（相同 source+ID）再次被抓取到时不应重置等待轮数：如果被重置为 0，
+	// 有效优先级会退回 0.1，本该输给下面新入队的 medium（0.5）
+	q.Push([]*models.News{
+		{Source: "a", ID: "low", SentimentScore: 0.1},
+		{Source: "a", ID: "medium", SentimentScore: 0.5},
+	})
+	popped = q.Pop()
+	assert.Equal(t, []string{"low"}, ids(popped))
+}
+
+func TestPriorityQueueContainsReflectsQueuedItems(t *testing.T) {
+	q := priorityqueue.New(1, 0.1)
+	q.Push([]*models.News{
+		{Source: "a", ID: "high", SentimentScore: 0.9},
+		{Source: "a", ID: "low", SentimentScore: 0.1},
+	})
+	assert.True(t, q.Contains("a", "high"))
+	assert.True(t, q.Contains("a", "low"))
+	assert.False(t, q.Contains("a", "unknown"))
+
+	popped := q.Pop()
+	assert.Equal(t, []string{"high"}, ids(popped))
+	// high 已经弹出交给发送阶段，不再算排队中；low 预算不够，仍在排队
+	assert.False(t, q.Contains("a", "high"))
+	assert.True(t, q.Contains("a", "low"))
+}
+
+func ids(newsList []*models.News) []string {
+	out := make([]string, len(newsList))
+	for i, n := range newsList {
+		out[i] = n.ID
+	}
+	return out
+}
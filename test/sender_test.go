@@ -22,7 +22,7 @@ func TestSender(t *testing.T) {
 	assert.NoError(t, err)
 
 	// 创建发送器
-	s, err := sender.NewSender(cfg.Telegram, newsCache)
+	s, err := sender.NewSender(cfg.Telegram, newsCache, cfg.HTTPClient)
 	assert.NoError(t, err)
 
 	// 创建测试新闻